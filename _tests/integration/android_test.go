@@ -145,6 +145,40 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   android: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  android:
+    score: 90
+    evidence:
+    - description: build.gradle found
+      weight: 40
+      paths:
+      - src/build.gradle
+    - description: project at depth 1
+      weight: 20
+      paths:
+      - src/build.gradle
+    - description: Gradle wrapper found
+      weight: 15
+      paths:
+      - src/gradlew
+    - description: AndroidManifest.xml found
+      weight: 15
+      paths:
+      - src/app/src/main/AndroidManifest.xml
+ranking:
+- android
 `, sampleAppsAndroidSDK22SubdirVersions...)
 
 var sampleAppsSDK22NoGradlewResultYML = `warnings:
@@ -155,6 +189,30 @@ var sampleAppsSDK22NoGradlewResultYML = `warnings:
 errors:
   general:
   - No known platform detected
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+diagnostics:
+- code: android-gradlew-not-found
+  severity: error
+  scanner: android
+  message: |-
+    No Gradle Wrapper (gradlew) found.
+    Using a Gradle Wrapper (gradlew) is required, as the wrapper is what makes sure
+    that the right Gradle version is installed and used for the build.
+  doc_url: https://docs.gradle.org/current/userguide/gradle_wrapper.html
+- code: no-platform-detected
+  severity: error
+  message: No known platform detected
 `
 
 var sampleAppsAndroid22Versions = []interface{}{
@@ -214,6 +272,40 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   android: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  android:
+    score: 100
+    evidence:
+    - description: build.gradle found
+      weight: 40
+      paths:
+      - build.gradle
+    - description: root level project
+      weight: 30
+      paths:
+      - build.gradle
+    - description: Gradle wrapper found
+      weight: 15
+      paths:
+      - gradlew
+    - description: AndroidManifest.xml found
+      weight: 15
+      paths:
+      - app/src/main/AndroidManifest.xml
+ranking:
+- android
 `, sampleAppsAndroid22Versions...)
 
 var androidNonExecutableGradlewVersions = []interface{}{
@@ -273,4 +365,38 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   android: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  android:
+    score: 100
+    evidence:
+    - description: build.gradle found
+      weight: 40
+      paths:
+      - build.gradle
+    - description: root level project
+      weight: 30
+      paths:
+      - build.gradle
+    - description: Gradle wrapper found
+      weight: 15
+      paths:
+      - gradlew
+    - description: AndroidManifest.xml found
+      weight: 15
+      paths:
+      - app/src/main/AndroidManifest.xml
+ranking:
+- android
 `, androidNonExecutableGradlewVersions...)
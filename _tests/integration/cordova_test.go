@@ -119,6 +119,36 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   cordova: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  cordova:
+    score: 85
+    evidence:
+    - description: Cordova config.xml found
+      weight: 40
+      paths:
+      - config.xml
+    - description: root level project
+      weight: 30
+      paths:
+      - config.xml
+    - description: package.json found
+      weight: 15
+      paths:
+      - package.json
+ranking:
+- cordova
 `, sampleAppsCordovaWithJasmineVersions...)
 
 var sampleAppsCordovaWithKarmaJasmineVersions = []interface{}{
@@ -186,4 +216,34 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   cordova: []
-  `, sampleAppsCordovaWithKarmaJasmineVersions...)
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  cordova:
+    score: 85
+    evidence:
+    - description: Cordova config.xml found
+      weight: 40
+      paths:
+      - config.xml
+    - description: root level project
+      weight: 30
+      paths:
+      - config.xml
+    - description: package.json found
+      weight: 15
+      paths:
+      - package.json
+ranking:
+- cordova
+`, sampleAppsCordovaWithKarmaJasmineVersions...)
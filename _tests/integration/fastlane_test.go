@@ -158,4 +158,50 @@ configs:
 warnings:
   fastlane: []
   ios: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  fastlane:
+    score: 75
+    evidence:
+    - description: Fastfile found
+      weight: 40
+      paths:
+      - BitriseFastlaneSample/fastlane/Fastfile
+    - description: project at depth 1
+      weight: 20
+      paths:
+      - BitriseFastlaneSample/fastlane/Fastfile
+    - description: Appfile found
+      weight: 15
+      paths:
+      - BitriseFastlaneSample/fastlane/Appfile
+  ios:
+    score: 75
+    evidence:
+    - description: Xcode project found
+      weight: 40
+      paths:
+      - BitriseFastlaneSample/BitriseFastlaneSample.xcodeproj
+    - description: project at depth 1
+      weight: 20
+      paths:
+      - BitriseFastlaneSample/BitriseFastlaneSample.xcodeproj
+    - description: shared schemes found
+      weight: 15
+      paths:
+      - BitriseFastlaneSample/BitriseFastlaneSample.xcodeproj/xcshareddata/xcschemes/BitriseFastlaneSample.xcscheme
+ranking:
+- fastlane
+- ios
 `, fastlaneVersions...)
@@ -175,6 +175,43 @@ warnings:
     No shared schemes found for project: BitriseXcode7Sample.xcodeproj.
     Automatically generated schemes may differ from the ones in your project.
     Make sure to <a href="http://devcenter.bitrise.io/ios/frequent-ios-issues/#xcode-scheme-not-found">share your schemes</a> for the expected behaviour.
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+diagnostics:
+- code: xcode-no-shared-schemes
+  severity: warning
+  scanner: ios
+  paths:
+  - BitriseXcode7Sample.xcodeproj
+  message: |-
+    No shared schemes found for project: BitriseXcode7Sample.xcodeproj.
+    Automatically generated schemes may differ from the ones in your project.
+    Make sure to share your schemes for the expected behaviour.
+  doc_url: http://devcenter.bitrise.io/ios/frequent-ios-issues/#xcode-scheme-not-found
+confidences:
+  ios:
+    score: 70
+    evidence:
+    - description: Xcode project found
+      weight: 40
+      paths:
+      - BitriseXcode7Sample.xcodeproj
+    - description: root level project
+      weight: 30
+      paths:
+      - BitriseXcode7Sample.xcodeproj
+ranking:
+- ios
 `, iosNoSharedSchemesVersions...)
 
 var iosCocoapodsAtRootVersions = []interface{}{
@@ -254,6 +291,40 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   ios: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  ios:
+    score: 100
+    evidence:
+    - description: Xcode project found
+      weight: 40
+      paths:
+      - iOSMinimalCocoaPodsSample.xcodeproj
+    - description: root level project
+      weight: 30
+      paths:
+      - iOSMinimalCocoaPodsSample.xcodeproj
+    - description: shared schemes found
+      weight: 15
+      paths:
+      - iOSMinimalCocoaPodsSample.xcodeproj/xcshareddata/xcschemes/iOSMinimalCocoaPodsSample.xcscheme
+    - description: CocoaPods lock file found
+      weight: 15
+      paths:
+      - Podfile.lock
+ranking:
+- ios
 `, iosCocoapodsAtRootVersions...)
 
 var sampleAppsIosWatchkitVersions = []interface{}{
@@ -383,6 +454,40 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   ios: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  ios:
+    score: 85
+    evidence:
+    - description: Xcode project found
+      weight: 40
+      paths:
+      - watch-test.xcodeproj
+    - description: root level project
+      weight: 30
+      paths:
+      - watch-test.xcodeproj
+    - description: shared schemes found
+      weight: 15
+      paths:
+      - watch-test.xcodeproj/xcshareddata/xcschemes/Complication - watch-test WatchKit App.xcscheme
+      - watch-test.xcodeproj/xcshareddata/xcschemes/Glance - watch-test WatchKit App.xcscheme
+      - watch-test.xcodeproj/xcshareddata/xcschemes/Notification - watch-test WatchKit App.xcscheme
+      - watch-test.xcodeproj/xcshareddata/xcschemes/watch-test WatchKit App.xcscheme
+      - watch-test.xcodeproj/xcshareddata/xcschemes/watch-test.xcscheme
+ranking:
+- ios
 `, sampleAppsIosWatchkitVersions...)
 
 var sampleAppsCarthageVersions = []interface{}{
@@ -466,4 +571,38 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   ios: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  ios:
+    score: 100
+    evidence:
+    - description: Xcode project found
+      weight: 40
+      paths:
+      - sample-apps-carthage.xcodeproj
+    - description: root level project
+      weight: 30
+      paths:
+      - sample-apps-carthage.xcodeproj
+    - description: shared schemes found
+      weight: 15
+      paths:
+      - sample-apps-carthage.xcodeproj/xcshareddata/xcschemes/sample-apps-carthage.xcscheme
+    - description: Carthage lock file found
+      weight: 15
+      paths:
+      - Cartfile.resolved
+ranking:
+- ios
 `, sampleAppsCarthageVersions...)
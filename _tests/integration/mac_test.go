@@ -110,4 +110,34 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   macos: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  macos:
+    score: 85
+    evidence:
+    - description: Xcode project found
+      weight: 40
+      paths:
+      - sample-apps-osx-10-11.xcodeproj
+    - description: root level project
+      weight: 30
+      paths:
+      - sample-apps-osx-10-11.xcodeproj
+    - description: shared schemes found
+      weight: 15
+      paths:
+      - sample-apps-osx-10-11.xcodeproj/xcshareddata/xcschemes/sample-apps-osx-10-11.xcscheme
+ranking:
+- macos
 `, sampleAppsOSX1011Versions...)
@@ -145,6 +145,44 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   xamarin: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  xamarin:
+    score: 100
+    evidence:
+    - description: solution file found
+      weight: 40
+      paths:
+      - XamarinSampleApp.sln
+    - description: root level project
+      weight: 30
+      paths:
+      - XamarinSampleApp.sln
+    - description: NuGet packages found
+      weight: 15
+      paths:
+      - XamarinSampleApp/packages.config
+      - XamarinSampleApp.Droid/packages.config
+      - XamarinSampleApp.iOS/packages.config
+    - description: C# project files found
+      weight: 15
+      paths:
+      - XamarinSampleApp.Droid/XamarinSampleApp.Droid.csproj
+      - XamarinSampleApp/XamarinSampleApp.csproj
+      - XamarinSampleApp.iOS/XamarinSampleApp.iOS.csproj
+ranking:
+- xamarin
 `, xamarinSampleAppVersions...)
 
 var sampleAppsXamarinIosVersions = []interface{}{
@@ -216,6 +254,42 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   xamarin: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  xamarin:
+    score: 100
+    evidence:
+    - description: solution file found
+      weight: 40
+      paths:
+      - CreditCardValidator.iOS.sln
+    - description: root level project
+      weight: 30
+      paths:
+      - CreditCardValidator.iOS.sln
+    - description: NuGet packages found
+      weight: 15
+      paths:
+      - CreditCardValidator.iOS/packages.config
+      - CreditCardValidator.iOS.UITests/packages.config
+    - description: C# project files found
+      weight: 15
+      paths:
+      - CreditCardValidator.iOS.UITests/CreditCardValidator.iOS.UITests.csproj
+      - CreditCardValidator.iOS/CreditCardValidator.iOS.csproj
+ranking:
+- xamarin
 `, sampleAppsXamarinIosVersions...)
 
 var sampleAppsXamarinAndroidVersions = []interface{}{
@@ -279,4 +353,40 @@ configs:
           - deploy-to-bitrise-io@%s: {}
 warnings:
   xamarin: []
+scanners:
+- ionic
+- cordova
+- react-native
+- flutter
+- unity
+- ios
+- macos
+- android
+- xamarin
+- fastlane
+- spm
+confidences:
+  xamarin:
+    score: 100
+    evidence:
+    - description: solution file found
+      weight: 40
+      paths:
+      - CreditCardValidator.Droid.sln
+    - description: root level project
+      weight: 30
+      paths:
+      - CreditCardValidator.Droid.sln
+    - description: NuGet packages found
+      weight: 15
+      paths:
+      - CreditCardValidator.Droid/packages.config
+      - CreditCardValidator.Droid.UITests/packages.config
+    - description: C# project files found
+      weight: 15
+      paths:
+      - CreditCardValidator.Droid.UITests/CreditCardValidator.Droid.UITests.csproj
+      - CreditCardValidator.Droid/CreditCardValidator.Droid.csproj
+ranking:
+- xamarin
 `, sampleAppsXamarinAndroidVersions...)
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/bitrise-core/bitrise-init/scanners/plugin"
 	"github.com/bitrise-core/bitrise-init/version"
 	"github.com/urfave/cli"
 )
@@ -36,6 +38,16 @@ func Run() {
 			Usage:  "If true it indicates that we're used by another tool so don't require any user input!",
 			EnvVar: "CI",
 		},
+		cli.StringSliceFlag{
+			Name:   "plugin",
+			Usage:  "Path to an external scanner plugin executable, can be specified multiple times.",
+			EnvVar: "BITRISE_INIT_PLUGINS",
+		},
+		cli.StringFlag{
+			Name:   "plugin-path",
+			Usage:  "List of directories to discover scanner plugin executables (named: " + plugin.ExecutablePrefix + "*) in, separated by the OS path list separator.",
+			EnvVar: "BITRISE_INIT_PLUGIN_PATH",
+		},
 	}
 
 	app.Before = func(c *cli.Context) error {
@@ -64,9 +76,23 @@ func Run() {
 		versionCommand,
 		configCommand,
 		manualConfigCommand,
+		resolveCommand,
+		configurationsCommand,
 	}
 
 	if err := app.Run(os.Args); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// pluginOptions returns the plugin executables and plugin dirs, defined by the global flags.
+func pluginOptions(c *cli.Context) ([]string, []string) {
+	executablePths := c.GlobalStringSlice("plugin")
+
+	pluginDirs := []string{}
+	if pluginPath := c.GlobalString("plugin-path"); pluginPath != "" {
+		pluginDirs = filepath.SplitList(pluginPath)
+	}
+
+	return executablePths, pluginDirs
+}
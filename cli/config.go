@@ -1,14 +1,20 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
+	"github.com/bitrise-core/bitrise-init/events"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/output"
 	"github.com/bitrise-core/bitrise-init/scanner"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
 	"github.com/bitrise-io/go-utils/colorstring"
 	"github.com/bitrise-io/go-utils/command"
 	"github.com/bitrise-io/go-utils/log"
@@ -20,6 +26,9 @@ const (
 	defaultScanResultDir = "_scan_result"
 )
 
+// noPlatformDetectedCode is the code of the general error diagnostic, added if no scanner detected the project.
+const noPlatformDetectedCode = "no-platform-detected"
+
 var configCommand = cli.Command{
 	Name:  "config",
 	Usage: "Generates a bitrise config files based on your project.",
@@ -33,7 +42,7 @@ var configCommand = cli.Command{
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:  "dir",
-			Usage: "Directory to scan.",
+			Usage: "Directory, archive (zip, tar, tar.gz) or git bundle to scan, archives are scanned without extracting them.",
 			Value: "./",
 		},
 		cli.StringFlag{
@@ -46,9 +55,91 @@ var configCommand = cli.Command{
 			Usage: "Output format, options [json, yaml].",
 			Value: "yaml",
 		},
+		cli.StringSliceFlag{
+			Name:   "scanners",
+			Usage:  "Comma separated list of the scanners to run, by default every scanner runs.",
+			EnvVar: "BITRISE_INIT_SCANNERS",
+		},
+		cli.StringSliceFlag{
+			Name:   "skip-scanners",
+			Usage:  "Comma separated list of the scanners to skip.",
+			EnvVar: "BITRISE_INIT_SKIP_SCANNERS",
+		},
+		cli.BoolFlag{
+			Name:  "monorepo",
+			Usage: "Select multiple projects (possibly of different platforms) and merge them into one multi-workflow bitrise.yml.",
+		},
+		cli.StringFlag{
+			Name:  "answers",
+			Usage: "Answers file (YAML or JSON) selecting the platform and the option values, the bitrise.yml is generated without asking for inputs.",
+		},
+		cli.StringFlag{
+			Name:  "events",
+			Usage: "Print the scan progress as an event stream, options [json]. The newline-delimited JSON events are printed to the standard output, the log to the standard error.",
+		},
+		cli.BoolFlag{
+			Name:  "explain",
+			Usage: "Explain why each scanner did or did not detect the project, the explanations are saved in the scan result too.",
+		},
+		cli.DurationFlag{
+			Name:   "timeout",
+			Usage:  "Time budget of the whole scan, like: 15m, by default the scan is not limited.",
+			EnvVar: "BITRISE_INIT_TIMEOUT",
+		},
+		cli.DurationFlag{
+			Name:   "scanner-timeout",
+			Usage:  "Time budget of a single scanner, 0 disables the limit.",
+			Value:  scanner.DefaultScannerTimeout,
+			EnvVar: "BITRISE_INIT_SCANNER_TIMEOUT",
+		},
+		cli.DurationFlag{
+			Name:   "subprocess-timeout",
+			Usage:  "Time budget of a single external tool call (like a Podfile evaluation), 0 disables the limit.",
+			Value:  utility.DefaultSubprocessTimeout,
+			EnvVar: "BITRISE_INIT_SUBPROCESS_TIMEOUT",
+		},
 	},
 }
 
+// scannerNamesFlag returns the scanner names of the given string slice flag,
+// every flag value may contain a comma separated list of names.
+func scannerNamesFlag(c *cli.Context, name string) []string {
+	scannerNames := []string{}
+	for _, value := range c.StringSlice(name) {
+		for _, scannerName := range strings.Split(value, ",") {
+			if scannerName = strings.TrimSpace(scannerName); scannerName != "" {
+				scannerNames = append(scannerNames, scannerName)
+			}
+		}
+	}
+	return scannerNames
+}
+
+// outputDirAndFormat returns the absolute output dir and the output format of the scan result commands,
+// the output dir defaults to the scan result dir in the current dir and the format to yaml.
+func outputDirAndFormat(currentDir, outputDir, formatStr string) (string, output.Format, error) {
+	if outputDir == "" {
+		outputDir = filepath.Join(currentDir, defaultScanResultDir)
+	}
+	outputDir, err := pathutil.AbsPath(outputDir)
+	if err != nil {
+		return "", output.Format(0), fmt.Errorf("Failed to expand path (%s), error: %s", outputDir, err)
+	}
+
+	if formatStr == "" {
+		formatStr = output.YAMLFormat.String()
+	}
+	format, err := output.ParseFormat(formatStr)
+	if err != nil {
+		return "", output.Format(0), fmt.Errorf("Failed to parse format (%s), error: %s", formatStr, err)
+	}
+	if format != output.JSONFormat && format != output.YAMLFormat {
+		return "", output.Format(0), fmt.Errorf("Not allowed output format (%s), options: [%s, %s]", format.String(), output.YAMLFormat.String(), output.JSONFormat.String())
+	}
+
+	return outputDir, format, nil
+}
+
 func writeScanResult(scanResult models.ScanResultModel, outputDir string, format output.Format) (string, error) {
 	pth := path.Join(outputDir, "result")
 	return output.WriteToFile(scanResult, format, pth)
@@ -60,6 +151,29 @@ func initConfig(c *cli.Context) error {
 	searchDir := c.String("dir")
 	outputDir := c.String("output-dir")
 	formatStr := c.String("format")
+	scannerNames := scannerNamesFlag(c, "scanners")
+	skippedScannerNames := scannerNamesFlag(c, "skip-scanners")
+	timeout := c.Duration("timeout")
+	scannerTimeout := c.Duration("scanner-timeout")
+	subprocessTimeout := c.Duration("subprocess-timeout")
+	isMonorepo := c.Bool("monorepo")
+	isExplain := c.Bool("explain")
+	eventsFormat := c.String("events")
+	answersPth := c.String("answers")
+
+	logWriter := io.Writer(os.Stdout)
+	var jsonEmitter *events.JSONEmitter
+	var emitter events.Emitter
+	if eventsFormat != "" {
+		if eventsFormat != "json" {
+			return fmt.Errorf("Not allowed event stream format (%s), options: [json]", eventsFormat)
+		}
+		// the event stream takes over the standard output
+		logWriter = os.Stderr
+		log.SetOutWriter(logWriter)
+		jsonEmitter = events.NewJSONEmitter(os.Stdout)
+		emitter = jsonEmitter
+	}
 
 	if isCI {
 		log.Infoft(colorstring.Yellow("CI mode"))
@@ -67,7 +181,21 @@ func initConfig(c *cli.Context) error {
 	log.Infoft(colorstring.Yellowf("scan dir: %s", searchDir))
 	log.Infoft(colorstring.Yellowf("output dir: %s", outputDir))
 	log.Infoft(colorstring.Yellowf("output format: %s", formatStr))
-	fmt.Println()
+	if len(scannerNames) > 0 {
+		log.Infoft(colorstring.Yellowf("scanners: %s", strings.Join(scannerNames, ", ")))
+	}
+	if len(skippedScannerNames) > 0 {
+		log.Infoft(colorstring.Yellowf("skipped scanners: %s", strings.Join(skippedScannerNames, ", ")))
+	}
+	if answersPth != "" {
+		log.Infoft(colorstring.Yellowf("answers: %s", answersPth))
+	}
+	if timeout > 0 {
+		log.Infoft(colorstring.Yellowf("timeout: %s", timeout))
+	}
+	log.Infoft(colorstring.Yellowf("scanner timeout: %s", scannerTimeout))
+	log.Infoft(colorstring.Yellowf("subprocess timeout: %s", subprocessTimeout))
+	fmt.Fprintln(logWriter)
 
 	currentDir, err := pathutil.AbsPath("./")
 	if err != nil {
@@ -82,12 +210,9 @@ func initConfig(c *cli.Context) error {
 		return fmt.Errorf("Failed to expand path (%s), error: %s", outputDir, err)
 	}
 
-	if outputDir == "" {
-		outputDir = filepath.Join(currentDir, defaultScanResultDir)
-	}
-	outputDir, err = pathutil.AbsPath(outputDir)
+	outputDir, format, err := outputDirAndFormat(currentDir, outputDir, formatStr)
 	if err != nil {
-		return fmt.Errorf("Failed to expand path (%s), error: %s", outputDir, err)
+		return err
 	}
 	if exist, err := pathutil.IsDirExists(outputDir); err != nil {
 		return err
@@ -97,41 +222,75 @@ func initConfig(c *cli.Context) error {
 		}
 	}
 
-	if formatStr == "" {
-		formatStr = output.YAMLFormat.String()
-	}
-	format, err := output.ParseFormat(formatStr)
-	if err != nil {
-		return fmt.Errorf("Failed to parse format (%s), error: %s", formatStr, err)
+	// read the answers before the scan, to fail fast on an invalid file
+	var answers scanner.Answers
+	if answersPth != "" {
+		answers, err = scanner.ReadAnswers(answersPth)
+		if err != nil {
+			return err
+		}
 	}
-	if format != output.JSONFormat && format != output.YAMLFormat {
-		return fmt.Errorf("Not allowed output format (%s), options: [%s, %s]", format.String(), output.YAMLFormat.String(), output.JSONFormat.String())
+
+	pluginExecutablePths, pluginDirs := pluginOptions(c)
+	scanOptions := scanner.ScanOptions{
+		ScannerNames:         scannerNames,
+		SkippedScannerNames:  skippedScannerNames,
+		PluginExecutablePths: pluginExecutablePths,
+		PluginDirs:           pluginDirs,
+		ScannerTimeout:       scannerTimeout,
+		LogWriter:            logWriter,
+		Explain:              isExplain,
+		Events:               emitter,
 	}
 	// ---
 
-	scanResult := scanner.Config(searchDir)
+	ctx := utility.WithSubprocessTimeout(context.Background(), subprocessTimeout)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	platforms := []string{}
-	for platform := range scanResult.PlatformOptionMap {
-		platforms = append(platforms, platform)
+	scanResult, err := scanner.Scan(ctx, searchDir, scanOptions)
+	if err != nil {
+		return err
+	}
+	if jsonEmitter != nil && jsonEmitter.Err() != nil {
+		log.Warnft("Failed to write the scan events, error: %s", jsonEmitter.Err())
+	}
+	if len(scanResult.TimedOutScannerNames) > 0 {
+		log.Warnft("Scanners timed out: %s", strings.Join(scanResult.TimedOutScannerNames, ", "))
+	}
+	if len(scanResult.Diagnostics) > 0 {
+		log.Infoft("Diagnostics:")
+		fmt.Fprint(logWriter, output.RenderTerminal(scanResult.Diagnostics))
+		fmt.Fprintln(logWriter)
+	}
+	if isExplain {
+		log.Infoft("Explanations:")
+		fmt.Fprint(logWriter, output.RenderExplanationsTerminal(scanResult.Explanations))
+		fmt.Fprintln(logWriter)
 	}
 
-	if len(platforms) == 0 {
-		cmd := command.New("which", "tree")
-		out, err := cmd.RunAndReturnTrimmedCombinedOutput()
-		if err != nil || out == "" {
-			log.Errorft("tree not installed, can not list files")
-		} else {
-			fmt.Println()
-			cmd := command.NewWithStandardOuts("tree", ".", "-L", "3")
-			log.Printft("$ %s", cmd.PrintableCommandArgs())
-			if err := cmd.Run(); err != nil {
-				log.Errorft("Failed to list files in current directory, error: %s", err)
+	if len(scanResult.RankedPlatforms()) == 0 {
+		// the explanations tell more about the missing detection, than the file tree
+		if !isExplain {
+			cmd := command.New("which", "tree")
+			out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+			if err != nil || out == "" {
+				log.Errorft("tree not installed, can not list files")
+			} else {
+				fmt.Fprintln(logWriter)
+				cmd := command.New("tree", ".", "-L", "3").SetStdout(logWriter).SetStderr(os.Stderr)
+				log.Printft("$ %s", cmd.PrintableCommandArgs())
+				if err := cmd.Run(); err != nil {
+					log.Errorft("Failed to list files in current directory, error: %s", err)
+				}
 			}
 		}
 
 		log.Infoft("Saving outputs:")
-		scanResult.AddError("general", "No known platform detected")
+		scanResult.AddDiagnostic(models.NewError(noPlatformDetectedCode, "No known platform detected"))
 
 		outputPth, err := writeScanResult(scanResult, outputDir, format)
 		if err != nil {
@@ -152,14 +311,24 @@ func initConfig(c *cli.Context) error {
 		}
 
 		log.Printft("  scan result: %s", outputPth)
-		return nil
+		if answersPth == "" {
+			return nil
+		}
 	}
 	// ---
 
 	// Select option
-	log.Infoft("Collecting inputs:")
-
-	config, err := scanner.AskForConfig(scanResult)
+	var config bitriseModels.BitriseDataModel
+	if answersPth != "" {
+		log.Infoft("Applying answers:")
+		config, err = scanner.AnswerConfig(scanResult, answers)
+	} else if isMonorepo {
+		log.Infoft("Collecting inputs:")
+		config, err = scanner.AskForMonorepoConfig(scanResult)
+	} else {
+		log.Infoft("Collecting inputs:")
+		config, err = scanner.AskForConfig(scanResult)
+	}
 	if err != nil {
 		return err
 	}
@@ -178,7 +347,7 @@ func initConfig(c *cli.Context) error {
 		return fmt.Errorf("Failed to print result, error: %s", err)
 	}
 	log.Infoft("  bitrise.yml template: %s", outputPth)
-	fmt.Println()
+	fmt.Fprintln(logWriter)
 	// ---
 
 	return nil
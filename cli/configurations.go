@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/bitrise-core/bitrise-init/output"
+	"github.com/bitrise-core/bitrise-init/scanner"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/urfave/cli"
+)
+
+var configurationsCommand = cli.Command{
+	Name:  "configurations",
+	Usage: "Lists the concrete configurations of a saved scan result, by expanding the option trees of the platforms.",
+	Action: func(c *cli.Context) error {
+		if err := listConfigurations(c); err != nil {
+			log.Errorft(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "scan-result",
+			Usage: "Scan result (result.yml or result.json) saved by the config command.",
+		},
+		cli.BoolFlag{
+			Name:  "bitrise-yml",
+			Usage: "Include the bitrise config of every configuration.",
+		},
+		cli.StringFlag{
+			Name:  "output-dir",
+			Usage: "Directory to save the configurations.",
+			Value: "./_scan_result",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format, options [json, yaml].",
+			Value: "yaml",
+		},
+	},
+}
+
+func listConfigurations(c *cli.Context) error {
+	// Config
+	scanResultPth := c.String("scan-result")
+	materialize := c.Bool("bitrise-yml")
+	outputDir := c.String("output-dir")
+	formatStr := c.String("format")
+
+	if scanResultPth == "" {
+		return errors.New("Scan result not specified")
+	}
+
+	log.Infoft(colorstring.Yellowf("scan result: %s", scanResultPth))
+	log.Infoft(colorstring.Yellowf("output dir: %s", outputDir))
+	log.Infoft(colorstring.Yellowf("output format: %s", formatStr))
+	fmt.Println()
+
+	currentDir, err := pathutil.AbsPath("./")
+	if err != nil {
+		return fmt.Errorf("Failed to get current directory, error: %s", err)
+	}
+
+	outputDir, format, err := outputDirAndFormat(currentDir, outputDir, formatStr)
+	if err != nil {
+		return err
+	}
+	// ---
+
+	scanResult, err := scanner.ReadScanResult(scanResultPth)
+	if err != nil {
+		return err
+	}
+
+	configurations, err := scanner.Configurations(scanResult, materialize)
+	if err != nil {
+		return err
+	}
+
+	log.Infoft("%d configurations found:", len(configurations))
+	for _, configuration := range configurations {
+		log.Printft("- %s", configuration.Title)
+	}
+	fmt.Println()
+
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return fmt.Errorf("Failed to create (%s), error: %s", outputDir, err)
+	}
+
+	pth := path.Join(outputDir, "configurations")
+	outputPth, err := output.WriteToFile(configurations, format, pth)
+	if err != nil {
+		return fmt.Errorf("Failed to print result, error: %s", err)
+	}
+	log.Infoft("  configurations: %s", outputPth)
+	fmt.Println()
+	// ---
+
+	return nil
+}
@@ -85,9 +85,14 @@ func initManualConfig(c *cli.Context) error {
 	if format != output.JSONFormat && format != output.YAMLFormat {
 		return fmt.Errorf("Not allowed output format (%v), options: [%s, %s]", format, output.YAMLFormat.String(), output.JSONFormat.String())
 	}
+
+	pluginExecutablePths, pluginDirs := pluginOptions(c)
 	// ---
 
-	scanResult, err := scanner.ManualConfig()
+	scanResult, err := scanner.ManualConfig(scanner.ScanOptions{
+		PluginExecutablePths: pluginExecutablePths,
+		PluginDirs:           pluginDirs,
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/bitrise-core/bitrise-init/output"
+	"github.com/bitrise-core/bitrise-init/scanner"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/urfave/cli"
+)
+
+var resolveCommand = cli.Command{
+	Name:  "resolve",
+	Usage: "Generates the bitrise config of a saved scan result, based on the selected platform and option values.",
+	Action: func(c *cli.Context) error {
+		if err := resolveConfig(c); err != nil {
+			log.Errorft(err.Error())
+			os.Exit(1)
+		}
+		return nil
+	},
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "scan-result",
+			Usage: "Scan result (result.yml or result.json) saved by the config command.",
+		},
+		cli.StringFlag{
+			Name:  "platform",
+			Usage: "Platform to select, it can be omitted if the scan result has a single platform.",
+		},
+		cli.StringSliceFlag{
+			Name:  "value",
+			Usage: "Selected value of the next option level, specify it for every level, from the platform's first option down to the config.",
+		},
+		cli.StringFlag{
+			Name:  "answers",
+			Usage: "Answers file (YAML or JSON) selecting the platform and the option values, instead of the platform and value flags.",
+		},
+		cli.StringFlag{
+			Name:  "output-dir",
+			Usage: "Directory to save the bitrise config.",
+			Value: "./_scan_result",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format, options [json, yaml].",
+			Value: "yaml",
+		},
+	},
+}
+
+func resolveConfig(c *cli.Context) error {
+	// Config
+	scanResultPth := c.String("scan-result")
+	platform := c.String("platform")
+	values := c.StringSlice("value")
+	answersPth := c.String("answers")
+	outputDir := c.String("output-dir")
+	formatStr := c.String("format")
+
+	if scanResultPth == "" {
+		return errors.New("Scan result not specified")
+	}
+	if answersPth != "" && (platform != "" || len(values) > 0) {
+		return errors.New("Select the options either by answers file or by platform and values, not both")
+	}
+
+	log.Infoft(colorstring.Yellowf("scan result: %s", scanResultPth))
+	if answersPth != "" {
+		log.Infoft(colorstring.Yellowf("answers: %s", answersPth))
+	} else {
+		log.Infoft(colorstring.Yellowf("platform: %s", platform))
+		log.Infoft(colorstring.Yellowf("values: %v", values))
+	}
+	log.Infoft(colorstring.Yellowf("output dir: %s", outputDir))
+	log.Infoft(colorstring.Yellowf("output format: %s", formatStr))
+	fmt.Println()
+
+	currentDir, err := pathutil.AbsPath("./")
+	if err != nil {
+		return fmt.Errorf("Failed to get current directory, error: %s", err)
+	}
+
+	outputDir, format, err := outputDirAndFormat(currentDir, outputDir, formatStr)
+	if err != nil {
+		return err
+	}
+	// ---
+
+	scanResult, err := scanner.ReadScanResult(scanResultPth)
+	if err != nil {
+		return err
+	}
+
+	// Select option
+	log.Infoft("Resolving config:")
+
+	var config bitriseModels.BitriseDataModel
+	if answersPth != "" {
+		answers, err := scanner.ReadAnswers(answersPth)
+		if err != nil {
+			return err
+		}
+		config, err = scanner.AnswerConfig(scanResult, answers)
+		if err != nil {
+			return err
+		}
+	} else {
+		config, err = scanner.Resolve(scanResult, platform, values)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return fmt.Errorf("Failed to create (%s), error: %s", outputDir, err)
+	}
+
+	pth := path.Join(outputDir, "bitrise.yml")
+	outputPth, err := output.WriteToFile(config, format, pth)
+	if err != nil {
+		return fmt.Errorf("Failed to print result, error: %s", err)
+	}
+	log.Infoft("  bitrise.yml template: %s", outputPth)
+	fmt.Println()
+	// ---
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/models"
+)
+
+// Type is the type of a scan event.
+type Type string
+
+// Event types, in the order they occur during a scan.
+const (
+	ScanStarted      Type = "scan_started"
+	FilesIndexed     Type = "files_indexed"
+	ScannerStarted   Type = "scanner_started"
+	FilesFound       Type = "files_found"
+	CommandRun       Type = "command_run"
+	OptionAdded      Type = "option_added"
+	DiagnosticRaised Type = "diagnostic_raised"
+	ScannerFinished  Type = "scanner_finished"
+	ScannerSkipped   Type = "scanner_skipped"
+	ScanFinished     Type = "scan_finished"
+)
+
+// Option describes an option (a question of the option tree), added by a scanner.
+type Option struct {
+	Title  string   `json:"title"`
+	EnvKey string   `json:"env_key,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// Event is a single progress event of a scan, the fields not related to the event type are left empty.
+type Event struct {
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	// Scanner is the name of the scanner, which emitted the event, it is empty for the scan level events.
+	Scanner string `json:"scanner,omitempty"`
+	// DurationMs is the duration of the finished scan, scanner or command in milliseconds.
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// Detected is set by the scanner_finished event of the scanners, which detected the project.
+	Detected bool `json:"detected,omitempty"`
+	// Count is the number of the indexed files.
+	Count int `json:"count,omitempty"`
+	// Paths are the files found by a scanner.
+	Paths []string `json:"paths,omitempty"`
+	// Command is the external command run, like: bundle install.
+	Command    string             `json:"command,omitempty"`
+	Option     *Option            `json:"option,omitempty"`
+	Diagnostic *models.Diagnostic `json:"diagnostic,omitempty"`
+	// Message describes the reason of a skipped or stopped scanner.
+	Message string `json:"message,omitempty"`
+}
+
+// Emitter receives the events of a scan, the scanners run concurrently, so it has to be safe for concurrent use.
+type Emitter interface {
+	Emit(event Event)
+}
+
+// JSONEmitter writes the events as newline-delimited JSON, every event is a single line.
+// It is safe for concurrent use.
+type JSONEmitter struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	err     error
+}
+
+// NewJSONEmitter ...
+func NewJSONEmitter(writer io.Writer) *JSONEmitter {
+	return &JSONEmitter{encoder: json.NewEncoder(writer)}
+}
+
+// Emit writes the event as a JSON line, the events after a failed write are dropped (see: Err).
+func (emitter *JSONEmitter) Emit(event Event) {
+	emitter.mutex.Lock()
+	defer emitter.mutex.Unlock()
+
+	if emitter.err != nil {
+		return
+	}
+	emitter.err = emitter.encoder.Encode(event)
+}
+
+// Err returns the error of the first failed write.
+// A progress event is not worth failing the scan for, so the scan goes on without the events.
+func (emitter *JSONEmitter) Err() error {
+	emitter.mutex.Lock()
+	defer emitter.mutex.Unlock()
+
+	return emitter.err
+}
+
+// Recorder collects the events in memory, like for a scan running in-process.
+// It is safe for concurrent use.
+type Recorder struct {
+	mutex  sync.Mutex
+	events []Event
+}
+
+// NewRecorder ...
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Emit ...
+func (recorder *Recorder) Emit(event Event) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.events = append(recorder.events, event)
+}
+
+// Events returns the collected events, in the order they were emitted.
+func (recorder *Recorder) Events() []Event {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return append([]Event{}, recorder.events...)
+}
+
+// Milliseconds returns the duration in milliseconds, as reported by the events.
+func Milliseconds(duration time.Duration) int64 {
+	return int64(duration / time.Millisecond)
+}
+
+type emitterKey struct{}
+type scannerKey struct{}
+
+// WithEmitter returns a copy of the ctx, which carries the emitter of the scan events.
+func WithEmitter(ctx context.Context, emitter Emitter) context.Context {
+	return context.WithValue(ctx, emitterKey{}, emitter)
+}
+
+// WithScanner returns a copy of the ctx, which carries the name of the running scanner,
+// the events emitted with the ctx are attributed to the scanner.
+func WithScanner(ctx context.Context, scannerName string) context.Context {
+	return context.WithValue(ctx, scannerKey{}, scannerName)
+}
+
+// Emit sends the event to the emitter carried by the ctx, if any.
+// The event time and scanner default to the current time and the scanner carried by the ctx.
+func Emit(ctx context.Context, event Event) {
+	emitter, ok := ctx.Value(emitterKey{}).(Emitter)
+	if !ok {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Scanner == "" {
+		if scannerName, ok := ctx.Value(scannerKey{}).(string); ok {
+			event.Scanner = scannerName
+		}
+	}
+	emitter.Emit(event)
+}
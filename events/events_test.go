@@ -0,0 +1,51 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmit(t *testing.T) {
+	t.Log("no emitter")
+	{
+		Emit(context.Background(), Event{Type: ScanStarted})
+	}
+
+	t.Log("scanner of the context")
+	{
+		recorder := NewRecorder()
+		ctx := WithScanner(WithEmitter(context.Background(), recorder), "ios")
+
+		Emit(ctx, Event{Type: ScannerStarted})
+		Emit(ctx, Event{Type: ScanFinished, Scanner: "android"})
+
+		recorded := recorder.Events()
+		require.Equal(t, 2, len(recorded))
+		require.Equal(t, "ios", recorded[0].Scanner)
+		require.False(t, recorded[0].Time.IsZero())
+		require.Equal(t, "android", recorded[1].Scanner)
+	}
+}
+
+func TestJSONEmitter(t *testing.T) {
+	var buffer bytes.Buffer
+	emitter := NewJSONEmitter(&buffer)
+
+	eventTime := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	diagnostic := models.NewWarning("android-gradlew-not-found", "no gradlew")
+	emitter.Emit(Event{Type: ScannerFinished, Time: eventTime, Scanner: "android", DurationMs: 12, Detected: true})
+	emitter.Emit(Event{Type: DiagnosticRaised, Time: eventTime, Scanner: "android", Diagnostic: &diagnostic})
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	require.Equal(t, []string{
+		`{"type":"scanner_finished","time":"2018-01-02T03:04:05Z","scanner":"android","duration_ms":12,"detected":true}`,
+		`{"type":"diagnostic_raised","time":"2018-01-02T03:04:05Z","scanner":"android","diagnostic":{"code":"android-gradlew-not-found","severity":"warning","message":"no gradlew"}}`,
+	}, lines)
+	require.NoError(t, emitter.Err())
+}
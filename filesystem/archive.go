@@ -0,0 +1,104 @@
+package filesystem
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// The archives are read into the memory, so their content is limited, the entries over the limits are skipped.
+var (
+	// maxArchiveEntrySize is the maximum content size of an entry.
+	maxArchiveEntrySize int64 = 64 << 20
+	// maxArchiveSize is the maximum total content size of the entries.
+	maxArchiveSize int64 = 512 << 20
+	// maxArchiveEntryCount is the maximum number of entries, the rest of the archive is not read.
+	maxArchiveEntryCount = 100000
+)
+
+// Archive is the MemoryFileSystem of an archive's content.
+type Archive struct {
+	*MemoryFileSystem
+
+	// SkippedEntries are the names of the entries which are not added to the file system:
+	// the entries pointing outside of the archive root (like ../file or /etc/passwd),
+	// the entries under a symlink, the conflicting entries, the special files (devices, pipes)
+	// and the entries over the size limits. Once the maximum number of entries is reached,
+	// the first entry over the limit is recorded and the rest of the archive is not read.
+	SkippedEntries []string
+
+	size       int64
+	entryCount int
+}
+
+// NewArchive returns an empty Archive.
+func NewArchive() (*Archive, error) {
+	fs, err := NewMemoryFileSystem(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{MemoryFileSystem: fs}, nil
+}
+
+// Skip records the named entry as skipped.
+func (archive *Archive) Skip(name string) {
+	archive.SkippedEntries = append(archive.SkippedEntries, name)
+}
+
+// AddEntry adds the archive entry using the given add function (like AddFile),
+// the entry is skipped if it can not be added: the add functions reject the names pointing outside of the root.
+func (archive *Archive) AddEntry(name string, add func(name string) error) {
+	if err := add(name); err != nil {
+		archive.Skip(name)
+	}
+}
+
+// addHardlink adds the named entry as a copy of the already added target file.
+func (archive *Archive) addHardlink(name, target string) error {
+	node, err := archive.lookup("link", target, false)
+	if err != nil {
+		return err
+	}
+	if node.isDir() || node.isSymlink() {
+		return fmt.Errorf("invalid hard link target: %s", filepath.ToSlash(target))
+	}
+	return archive.AddFile(name, node.content)
+}
+
+// countEntry counts the named entry, it returns false (and records the entry as skipped)
+// if the archive reached the maximum number of entries.
+func (archive *Archive) countEntry(name string) bool {
+	archive.entryCount++
+	if archive.entryCount > maxArchiveEntryCount {
+		archive.Skip(name)
+		return false
+	}
+	return true
+}
+
+// readEntry reads the content of the named entry with the given (declared) size,
+// it returns false (and records the entry as skipped) if the content is over the size limits.
+// The declared size is not trusted, at most the limit is read from the reader.
+func (archive *Archive) readEntry(name string, reader io.Reader, size int64) ([]byte, bool, error) {
+	limit := maxArchiveEntrySize
+	if remaining := maxArchiveSize - archive.size; remaining < limit {
+		limit = remaining
+	}
+	if size > limit {
+		archive.Skip(name)
+		return nil, false, nil
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(content)) > limit {
+		archive.Skip(name)
+		return nil, false, nil
+	}
+
+	archive.size += int64(len(content))
+	return content, true, nil
+}
@@ -0,0 +1,172 @@
+package filesystem
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileSystem is a read-only file tree, the scanners read the project files through it,
+// so the same scan can run against a directory, an in-memory fixture or an archive.
+// The names are relative to the root of the tree, "." names the root itself.
+// Names pointing outside of the root do not exist.
+type FileSystem interface {
+	// Open opens the named file for reading.
+	Open(name string) (io.ReadCloser, error)
+	// Stat returns the file info of the named file, symlinks are followed.
+	Stat(name string) (os.FileInfo, error)
+	// Lstat returns the file info of the named file, symlinks are not followed.
+	Lstat(name string) (os.FileInfo, error)
+	// ReadDir returns the (Lstat) file infos of the named directory's entries, sorted by name.
+	ReadDir(name string) ([]os.FileInfo, error)
+}
+
+// Rooted is implemented by the file systems, which are backed by a directory on the disk.
+type Rooted interface {
+	RootDir() string
+}
+
+// RootDir returns the disk directory of the file system, if it is backed by one.
+func RootDir(fs FileSystem) (string, bool) {
+	rooted, ok := fs.(Rooted)
+	if !ok {
+		return "", false
+	}
+	return rooted.RootDir(), true
+}
+
+// CleanName returns the cleaned, root relative form of the name.
+// It returns false if the name is absolute or points outside of the root.
+func CleanName(name string) (string, bool) {
+	if filepath.IsAbs(name) {
+		return "", false
+	}
+
+	name = filepath.Clean(name)
+	if name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return name, true
+}
+
+func notExistError(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+}
+
+// ReadFile reads the content of the named file.
+func ReadFile(fs FileSystem, name string) ([]byte, error) {
+	file, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if _, err := io.Copy(&buffer, file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// ReadStringFromFile reads the content of the named file as string.
+func ReadStringFromFile(fs FileSystem, name string) (string, error) {
+	content, err := ReadFile(fs, name)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// IsPathExists reports whether the named file or directory exists.
+func IsPathExists(fs FileSystem, name string) (bool, error) {
+	if _, err := fs.Stat(name); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// IsDir reports whether the named path exists and it is a directory.
+func IsDir(fs FileSystem, name string) (bool, error) {
+	info, err := fs.Stat(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return info.IsDir(), nil
+}
+
+// Walk walks the tree rooted at the named directory, in lexical order, like filepath.Walk does.
+// The walkFn gets the root relative names, symlinks are not followed.
+func Walk(fs FileSystem, root string, walkFn filepath.WalkFunc) error {
+	info, err := fs.Lstat(root)
+	if err != nil {
+		err = walkFn(root, nil, err)
+	} else {
+		err = walk(fs, root, info, walkFn)
+	}
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+func walk(fs FileSystem, name string, info os.FileInfo, walkFn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return walkFn(name, info, nil)
+	}
+
+	infos, err := fs.ReadDir(name)
+	if err := walkFn(name, info, err); err != nil || infos == nil {
+		return err
+	}
+
+	for _, childInfo := range infos {
+		childName := filepath.Join(name, childInfo.Name())
+		if err := walk(fs, childName, childInfo, walkFn); err != nil {
+			if !childInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CopyDir copies the named directory of the file system into the dstDir on the disk,
+// for the tools, which need to read the files directly. Symlinks are not copied.
+func CopyDir(fs FileSystem, name, dstDir string) error {
+	return Walk(fs, name, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPth, err := filepath.Rel(name, pth)
+		if err != nil {
+			return err
+		}
+		dstPth := filepath.Join(dstDir, relPth)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(dstPth, 0755)
+		case info.Mode()&os.ModeSymlink != 0:
+			return nil
+		}
+
+		content, err := ReadFile(fs, pth)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(dstPth, content, info.Mode().Perm()|0600)
+	})
+}
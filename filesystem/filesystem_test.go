@@ -0,0 +1,499 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+var testFiles = map[string]string{
+	"build.gradle":     "root",
+	"app/build.gradle": "app",
+	"app/src/Main.kt":  "main",
+	"ios/Podfile":      "pod",
+}
+
+func walkedNames(t *testing.T, fs FileSystem) []string {
+	names := []string{}
+	require.NoError(t, Walk(fs, ".", func(name string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		if info.IsDir() && name == filepath.Join("app", "src") {
+			return filepath.SkipDir
+		}
+		names = append(names, name)
+		return nil
+	}))
+	return names
+}
+
+func requireFileSystem(t *testing.T, fs FileSystem) {
+	t.Log("read file")
+	{
+		content, err := ReadStringFromFile(fs, "app/build.gradle")
+		require.NoError(t, err)
+		require.Equal(t, "app", content)
+
+		_, err = ReadStringFromFile(fs, "app")
+		require.Error(t, err)
+	}
+
+	t.Log("stat")
+	{
+		exist, err := IsPathExists(fs, "ios/Podfile")
+		require.NoError(t, err)
+		require.True(t, exist)
+
+		exist, err = IsPathExists(fs, "ios/Podfile.lock")
+		require.NoError(t, err)
+		require.False(t, exist)
+
+		isDir, err := IsDir(fs, "ios")
+		require.NoError(t, err)
+		require.True(t, isDir)
+
+		isDir, err = IsDir(fs, "ios/Podfile")
+		require.NoError(t, err)
+		require.False(t, isDir)
+	}
+
+	t.Log("outside of the root")
+	{
+		_, err := fs.Stat("../build.gradle")
+		require.True(t, os.IsNotExist(err))
+
+		_, err = fs.Open("/build.gradle")
+		require.True(t, os.IsNotExist(err))
+	}
+
+	t.Log("read dir")
+	{
+		infos, err := fs.ReadDir("app")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(infos))
+		require.Equal(t, "build.gradle", infos[0].Name())
+		require.False(t, infos[0].IsDir())
+		require.Equal(t, "src", infos[1].Name())
+		require.True(t, infos[1].IsDir())
+	}
+
+	t.Log("walk")
+	{
+		require.Equal(t, []string{".", "app", filepath.Join("app", "build.gradle"), "build.gradle", "ios", filepath.Join("ios", "Podfile")}, walkedNames(t, fs))
+	}
+}
+
+func TestMemoryFileSystem(t *testing.T) {
+	fs, err := NewMemoryFileSystem(testFiles)
+	require.NoError(t, err)
+
+	requireFileSystem(t, fs)
+
+	t.Log("add")
+	{
+		require.NoError(t, fs.AddDir("empty/dir"))
+		isDir, err := IsDir(fs, "empty/dir")
+		require.NoError(t, err)
+		require.True(t, isDir)
+
+		require.EqualError(t, fs.AddFile("empty", []byte{}), "empty is a directory")
+		require.EqualError(t, fs.AddFile("ios/Podfile/Podfile.lock", []byte{}), "ios/Podfile is not a directory")
+		require.EqualError(t, fs.AddFile("../outside", []byte{}), "invalid file name: ../outside")
+	}
+
+	t.Log("symlink")
+	{
+		require.NoError(t, fs.AddSymlink("ios/Podfile.link", "Podfile"))
+		require.NoError(t, fs.AddSymlink("android", "app"))
+		require.NoError(t, fs.AddSymlink("app/src/root", "../.."))
+		require.NoError(t, fs.AddSymlink("escape", "../outside"))
+		require.NoError(t, fs.AddSymlink("absolute", "/etc/passwd"))
+		require.NoError(t, fs.AddSymlink("loop", "loop"))
+
+		content, err := ReadStringFromFile(fs, "ios/Podfile.link")
+		require.NoError(t, err)
+		require.Equal(t, "pod", content)
+
+		content, err = ReadStringFromFile(fs, "android/build.gradle")
+		require.NoError(t, err)
+		require.Equal(t, "app", content)
+
+		content, err = ReadStringFromFile(fs, "app/src/root/build.gradle")
+		require.NoError(t, err)
+		require.Equal(t, "root", content)
+
+		info, err := fs.Stat("android")
+		require.NoError(t, err)
+		require.True(t, info.IsDir())
+		require.Equal(t, "android", info.Name())
+
+		info, err = fs.Lstat("android")
+		require.NoError(t, err)
+		require.False(t, info.IsDir())
+		require.True(t, info.Mode()&os.ModeSymlink != 0)
+
+		for _, name := range []string{"escape", "absolute", "loop"} {
+			_, err := fs.Open(name)
+			require.True(t, os.IsNotExist(err), name)
+
+			_, err = fs.Lstat(name)
+			require.NoError(t, err, name)
+		}
+
+		require.EqualError(t, fs.AddFile("android/Main.kt", []byte{}), "android is not a directory")
+	}
+
+	t.Log("copy dir")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__filesystem_copy_test__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		require.NoError(t, CopyDir(fs, "app", tmpDir))
+
+		content, err := fileutil.ReadStringFromFile(filepath.Join(tmpDir, "src", "Main.kt"))
+		require.NoError(t, err)
+		require.Equal(t, "main", content)
+
+		exist, err := pathutil.IsPathExists(filepath.Join(tmpDir, "src", "root"))
+		require.NoError(t, err)
+		require.False(t, exist)
+	}
+
+	_, ok := RootDir(fs)
+	require.False(t, ok)
+}
+
+func TestOSFileSystem(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__filesystem_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	for name, content := range testFiles {
+		pth := filepath.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0700))
+		require.NoError(t, fileutil.WriteStringToFile(pth, content))
+	}
+
+	fs, err := NewOSFileSystem(tmpDir)
+	require.NoError(t, err)
+
+	requireFileSystem(t, fs)
+
+	rootDir, ok := RootDir(fs)
+	require.True(t, ok)
+	require.Equal(t, tmpDir, rootDir)
+}
+
+func testTar(t *testing.T, names ...string) []byte {
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	for _, name := range names {
+		content, ok := testFiles[name]
+		if !ok {
+			require.NoError(t, writer.WriteHeader(&tar.Header{Name: name + "/", Typeflag: tar.TypeDir, Mode: 0755}))
+			continue
+		}
+		require.NoError(t, writer.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+		_, err := writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+// setArchiveLimits sets the archive limits, the returned function restores the defaults.
+func setArchiveLimits(entrySize, size int64, entryCount int) func() {
+	defaultEntrySize, defaultSize, defaultEntryCount := maxArchiveEntrySize, maxArchiveSize, maxArchiveEntryCount
+	maxArchiveEntrySize, maxArchiveSize, maxArchiveEntryCount = entrySize, size, entryCount
+	return func() {
+		maxArchiveEntrySize, maxArchiveSize, maxArchiveEntryCount = defaultEntrySize, defaultSize, defaultEntryCount
+	}
+}
+
+func TestNewTarFileSystem(t *testing.T) {
+	archive := testTar(t, "app", "build.gradle", "app/build.gradle", "app/src/Main.kt", "ios/Podfile")
+
+	t.Log("tar")
+	{
+		fs, err := NewTarFileSystem(bytes.NewReader(archive))
+		require.NoError(t, err)
+
+		requireFileSystem(t, fs)
+	}
+
+	t.Log("tar.gz")
+	{
+		var buffer bytes.Buffer
+		writer := gzip.NewWriter(&buffer)
+		_, err := writer.Write(archive)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		fs, err := NewTarFileSystem(&buffer)
+		require.NoError(t, err)
+
+		requireFileSystem(t, fs)
+	}
+
+	t.Log("unsafe and linked entries")
+	{
+		var buffer bytes.Buffer
+		writer := tar.NewWriter(&buffer)
+		for _, header := range []*tar.Header{
+			{Name: "ios/Podfile", Typeflag: tar.TypeReg, Mode: 0644, Size: 3},
+			{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "/etc/evil", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "a/../../evil", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+			{Name: "link/evil", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "fifo", Typeflag: tar.TypeFifo, Mode: 0644},
+			{Name: "Podfile.link", Typeflag: tar.TypeSymlink, Linkname: "ios/Podfile"},
+			{Name: "Podfile.hard", Typeflag: tar.TypeLink, Linkname: "ios/Podfile"},
+			{Name: "missing.hard", Typeflag: tar.TypeLink, Linkname: "missing"},
+		} {
+			require.NoError(t, writer.WriteHeader(header))
+			if header.Size > 0 {
+				_, err := writer.Write([]byte("pod"))
+				require.NoError(t, err)
+			}
+		}
+		require.NoError(t, writer.Close())
+
+		fs, err := NewTarFileSystem(&buffer)
+		require.NoError(t, err)
+		require.Equal(t, []string{"../evil", "/etc/evil", "a/../../evil", "link/evil", "fifo", "missing.hard"}, fs.SkippedEntries)
+
+		content, err := ReadStringFromFile(fs, "Podfile.link")
+		require.NoError(t, err)
+		require.Equal(t, "pod", content)
+
+		content, err = ReadStringFromFile(fs, "Podfile.hard")
+		require.NoError(t, err)
+		require.Equal(t, "pod", content)
+
+		_, err = fs.Stat("link")
+		require.True(t, os.IsNotExist(err))
+	}
+
+	t.Log("entries over the limits")
+	{
+		defer setArchiveLimits(5, 8, 4)()
+
+		var buffer bytes.Buffer
+		writer := tar.NewWriter(&buffer)
+		for _, file := range []struct {
+			name    string
+			content string
+		}{
+			{"a", "pod"},
+			{"big", "gradle"},
+			{"b", "pod"},
+			{"c", "pod"},
+			{"d", ""},
+			{"e", ""},
+		} {
+			require.NoError(t, writer.WriteHeader(&tar.Header{Name: file.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(file.content))}))
+			_, err := writer.Write([]byte(file.content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		fs, err := NewTarFileSystem(&buffer)
+		require.NoError(t, err)
+		require.Equal(t, []string{"big", "c", "d"}, fs.SkippedEntries)
+
+		for _, name := range []string{"a", "b"} {
+			exist, err := IsPathExists(fs, name)
+			require.NoError(t, err)
+			require.True(t, exist, name)
+		}
+		for _, name := range []string{"big", "c", "d", "e"} {
+			exist, err := IsPathExists(fs, name)
+			require.NoError(t, err)
+			require.False(t, exist, name)
+		}
+	}
+}
+
+func TestNewZipFileSystem(t *testing.T) {
+	t.Log("zip")
+	{
+		var buffer bytes.Buffer
+		writer := zip.NewWriter(&buffer)
+		for _, name := range []string{"app/", "build.gradle", "app/build.gradle", "app/src/Main.kt", "ios/Podfile", "../evil"} {
+			entry, err := writer.Create(name)
+			require.NoError(t, err)
+			_, err = entry.Write([]byte(testFiles[name]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		fs, err := NewZipFileSystem(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		require.NoError(t, err)
+		require.Equal(t, []string{"../evil"}, fs.SkippedEntries)
+
+		requireFileSystem(t, fs)
+	}
+
+	t.Log("symlink")
+	{
+		var buffer bytes.Buffer
+		writer := zip.NewWriter(&buffer)
+		entry, err := writer.Create("ios/Podfile")
+		require.NoError(t, err)
+		_, err = entry.Write([]byte("pod"))
+		require.NoError(t, err)
+
+		header := &zip.FileHeader{Name: "Podfile.link"}
+		header.SetMode(os.ModeSymlink | 0777)
+		entry, err = writer.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = entry.Write([]byte("ios/Podfile"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		fs, err := NewZipFileSystem(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(fs.SkippedEntries))
+
+		content, err := ReadStringFromFile(fs, "Podfile.link")
+		require.NoError(t, err)
+		require.Equal(t, "pod", content)
+
+		info, err := fs.Lstat("Podfile.link")
+		require.NoError(t, err)
+		require.True(t, info.Mode()&os.ModeSymlink != 0)
+	}
+
+	t.Log("entries over the limits")
+	{
+		defer setArchiveLimits(5, 100, 2)()
+
+		var buffer bytes.Buffer
+		writer := zip.NewWriter(&buffer)
+		for _, file := range []struct {
+			name    string
+			content string
+		}{
+			{"small", "pod"},
+			{"big", "gradle"},
+			{"third", ""},
+			{"fourth", ""},
+		} {
+			entry, err := writer.Create(file.name)
+			require.NoError(t, err)
+			_, err = entry.Write([]byte(file.content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		fs, err := NewZipFileSystem(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		require.NoError(t, err)
+		require.Equal(t, []string{"big", "third"}, fs.SkippedEntries)
+
+		content, err := ReadStringFromFile(fs, "small")
+		require.NoError(t, err)
+		require.Equal(t, "pod", content)
+	}
+}
+
+func TestReadGitObjects(t *testing.T) {
+	type gitEntry struct {
+		mode    string
+		name    string
+		content string
+	}
+	// testGitArchive reads the entries into an archive, as the ls-tree and cat-file --batch outputs of a git bundle
+	testGitArchive := func(entries []gitEntry) (*Archive, error) {
+		var tree bytes.Buffer
+		contents := map[string]string{}
+		for i, entry := range entries {
+			object := fmt.Sprintf("%040d", i)
+			contents[object] = entry.content
+			fmt.Fprintf(&tree, "%s blob %s\t%s\x00", entry.mode, object, entry.name)
+		}
+
+		archive, err := NewArchive()
+		require.NoError(t, err)
+
+		treeEntries, err := archive.ReadGitTree(tree.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		var objects bytes.Buffer
+		for _, entry := range treeEntries {
+			fmt.Fprintf(&objects, "%s blob %d\n%s\n", entry.Object, len(contents[entry.Object]), contents[entry.Object])
+		}
+		return archive, archive.ReadGitObjects(&objects, treeEntries)
+	}
+
+	t.Log("git tree")
+	{
+		archive, err := testGitArchive([]gitEntry{
+			{"100644", "app/build.gradle", "app"},
+			{"120000", "build.gradle", "app/build.gradle"},
+			{"160000", "submodule", ""},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"submodule"}, archive.SkippedEntries)
+
+		content, err := ReadStringFromFile(archive, "build.gradle")
+		require.NoError(t, err)
+		require.Equal(t, "app", content)
+	}
+
+	t.Log("entries over the limits")
+	{
+		defer setArchiveLimits(5, 8, 6)()
+
+		archive, err := testGitArchive([]gitEntry{
+			{"100644", "a", "pod"},
+			{"100644", "big", "gradle"},
+			{"120000", "link", "a"},
+			{"160000", "submodule", ""},
+			{"100644", "b", "pods!"},
+			{"100644", "c", "p"},
+			{"100644", "d", ""},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"submodule", "d", "big", "b"}, archive.SkippedEntries)
+
+		for _, name := range []string{"a", "link", "c"} {
+			exist, err := IsPathExists(archive, name)
+			require.NoError(t, err)
+			require.True(t, exist, name)
+		}
+		for _, name := range []string{"big", "b", "d"} {
+			exist, err := IsPathExists(archive, name)
+			require.NoError(t, err)
+			require.False(t, exist, name)
+		}
+
+		content, err := ReadStringFromFile(archive, "c")
+		require.NoError(t, err)
+		require.Equal(t, "p", content)
+	}
+
+	t.Log("truncated cat-file output")
+	{
+		archive, err := NewArchive()
+		require.NoError(t, err)
+
+		entries := []GitTreeEntry{{Mode: "100644", Object: "0", Name: "a"}}
+		require.Error(t, archive.ReadGitObjects(bytes.NewBufferString("0 blob 10\npod"), entries))
+	}
+}
@@ -0,0 +1,112 @@
+package filesystem
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+const (
+	gitSymlinkMode   = "120000"
+	gitSubmoduleMode = "160000"
+)
+
+// GitTreeEntry is an entry of a git tree, which content is read into the archive.
+type GitTreeEntry struct {
+	Mode   string
+	Object string
+	Name   string
+}
+
+// ReadGitTree parses the git ls-tree -r -z output and returns the entries to read into the archive,
+// the submodules are skipped, once the maximum number of entries is reached, the rest of the tree is not read.
+func (archive *Archive) ReadGitTree(tree []byte) ([]GitTreeEntry, error) {
+	entries := []GitTreeEntry{}
+	for _, line := range strings.Split(string(tree), "\x00") {
+		if line == "" {
+			continue
+		}
+		// <mode> SP <type> SP <object> TAB <file>
+		split := strings.SplitN(line, "\t", 2)
+		fields := strings.Fields(split[0])
+		if len(split) != 2 || len(fields) != 3 {
+			return nil, fmt.Errorf("invalid git tree entry: %s", line)
+		}
+
+		name := split[1]
+		if !archive.countEntry(name) {
+			break
+		}
+		if fields[0] == gitSubmoduleMode {
+			archive.Skip(name)
+			continue
+		}
+
+		entries = append(entries, GitTreeEntry{Mode: fields[0], Object: fields[2], Name: name})
+	}
+	return entries, nil
+}
+
+// ReadGitObjects reads the git cat-file --batch output of the given entries into the archive.
+// The output is read as a stream, the content of the objects over the size limits is discarded.
+func (archive *Archive) ReadGitObjects(reader io.Reader, entries []GitTreeEntry) error {
+	bufferedReader := bufio.NewReader(reader)
+	for _, entry := range entries {
+		content, ok, err := archive.readGitObject(bufferedReader, entry.Name)
+		if err != nil {
+			return fmt.Errorf("failed to read git object of %s, error: %s", entry.Name, err)
+		} else if !ok {
+			continue
+		}
+
+		if entry.Mode == gitSymlinkMode {
+			archive.AddEntry(entry.Name, func(name string) error {
+				return archive.AddSymlink(name, string(content))
+			})
+		} else {
+			archive.AddEntry(entry.Name, func(name string) error {
+				return archive.AddFile(name, content)
+			})
+		}
+	}
+	return nil
+}
+
+// readGitObject reads the next object of the git cat-file --batch output,
+// it returns false (and records the entry as skipped) if the object is over the size limits.
+func (archive *Archive) readGitObject(reader *bufio.Reader, name string) ([]byte, bool, error) {
+	// <object> SP <type> SP <size> LF <contents> LF
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, false, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, false, fmt.Errorf("invalid git object header: %s", strings.TrimSpace(header))
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || size < 0 {
+		return nil, false, fmt.Errorf("invalid git object size: %s", fields[2])
+	}
+
+	object := io.LimitReader(reader, size)
+	content, ok, err := archive.readEntry(name, object, size)
+	if err != nil {
+		return nil, false, err
+	}
+	// the skipped content is discarded, to continue with the next object
+	if _, err := io.Copy(ioutil.Discard, object); err != nil {
+		return nil, false, err
+	}
+
+	if lf, err := reader.ReadByte(); err != nil {
+		return nil, false, err
+	} else if lf != '\n' {
+		return nil, false, fmt.Errorf("invalid git object content of size: %d", size)
+	}
+
+	return content, ok, nil
+}
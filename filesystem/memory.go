@@ -0,0 +1,273 @@
+package filesystem
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type memoryNode struct {
+	name     string
+	mode     os.FileMode
+	modTime  time.Time
+	content  []byte
+	children map[string]*memoryNode
+}
+
+func (node *memoryNode) isDir() bool {
+	return node.mode.IsDir()
+}
+
+func (node *memoryNode) isSymlink() bool {
+	return node.mode&os.ModeSymlink != 0
+}
+
+// memoryFileInfo implements os.FileInfo, name overrides the node's name for the followed symlinks.
+type memoryFileInfo struct {
+	node *memoryNode
+	name string
+}
+
+func (info memoryFileInfo) Name() string {
+	if info.name != "" {
+		return info.name
+	}
+	return info.node.name
+}
+
+func (info memoryFileInfo) Size() int64        { return int64(len(info.node.content)) }
+func (info memoryFileInfo) Mode() os.FileMode  { return info.node.mode }
+func (info memoryFileInfo) ModTime() time.Time { return info.node.modTime }
+func (info memoryFileInfo) IsDir() bool        { return info.node.isDir() }
+func (info memoryFileInfo) Sys() interface{}   { return nil }
+
+// maxSymlinkDepth is the number of symlinks followed while resolving a name, a deeper chain is handled as a loop.
+const maxSymlinkDepth = 40
+
+// MemoryFileSystem is an in-memory FileSystem, the parent directories of the added files are created implicitly.
+// Symlinks are followed by Open and Stat, but only within the file system:
+// a symlink pointing outside of the root (or to itself) is reported as not existing.
+// The file system should not be modified while it is read.
+type MemoryFileSystem struct {
+	root *memoryNode
+}
+
+// NewMemoryFileSystem returns a MemoryFileSystem with the given files, the map keys are the root relative file names.
+func NewMemoryFileSystem(files map[string]string) (*MemoryFileSystem, error) {
+	fs := &MemoryFileSystem{
+		root: newMemoryDirNode("."),
+	}
+
+	for name, content := range files {
+		if err := fs.AddFile(name, []byte(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	return fs, nil
+}
+
+func newMemoryDirNode(name string) *memoryNode {
+	return &memoryNode{
+		name:     name,
+		mode:     os.ModeDir | 0755,
+		children: map[string]*memoryNode{},
+	}
+}
+
+func splitName(name string) ([]string, bool) {
+	cleanName, ok := CleanName(name)
+	if !ok {
+		return nil, false
+	}
+	if cleanName == "." {
+		return []string{}, true
+	}
+	return strings.Split(filepath.ToSlash(cleanName), "/"), true
+}
+
+// mkdirAll returns the named directory node, the missing directories are created.
+func (fs *MemoryFileSystem) mkdirAll(components []string) (*memoryNode, error) {
+	dir := fs.root
+	for i, component := range components {
+		child, ok := dir.children[component]
+		if !ok {
+			child = newMemoryDirNode(component)
+			dir.children[component] = child
+		} else if !child.isDir() {
+			return nil, fmt.Errorf("%s is not a directory", filepath.Join(components[:i+1]...))
+		}
+		dir = child
+	}
+	return dir, nil
+}
+
+// AddDir adds the named directory, along with its missing parents.
+func (fs *MemoryFileSystem) AddDir(name string) error {
+	components, ok := splitName(name)
+	if !ok {
+		return fmt.Errorf("invalid directory name: %s", name)
+	}
+	_, err := fs.mkdirAll(components)
+	return err
+}
+
+// AddFile adds the named file with the given content, an existing file is overwritten.
+func (fs *MemoryFileSystem) AddFile(name string, content []byte) error {
+	components, ok := splitName(name)
+	if !ok || len(components) == 0 {
+		return fmt.Errorf("invalid file name: %s", name)
+	}
+
+	dir, err := fs.mkdirAll(components[:len(components)-1])
+	if err != nil {
+		return err
+	}
+
+	base := components[len(components)-1]
+	if existing, ok := dir.children[base]; ok && existing.isDir() {
+		return fmt.Errorf("%s is a directory", name)
+	}
+
+	dir.children[base] = &memoryNode{
+		name:    base,
+		mode:    0644,
+		content: content,
+	}
+	return nil
+}
+
+// AddSymlink adds the named symlink pointing to the given target, an existing file or symlink is overwritten.
+// The target is stored as it is, relative targets are resolved from the symlink's directory.
+func (fs *MemoryFileSystem) AddSymlink(name, target string) error {
+	components, ok := splitName(name)
+	if !ok || len(components) == 0 {
+		return fmt.Errorf("invalid symlink name: %s", name)
+	}
+
+	dir, err := fs.mkdirAll(components[:len(components)-1])
+	if err != nil {
+		return err
+	}
+
+	base := components[len(components)-1]
+	if existing, ok := dir.children[base]; ok && existing.isDir() {
+		return fmt.Errorf("%s is a directory", name)
+	}
+
+	dir.children[base] = &memoryNode{
+		name:    base,
+		mode:    os.ModeSymlink | 0777,
+		content: []byte(target),
+	}
+	return nil
+}
+
+// lookup returns the named node, the symlinks in the parent directories are always followed,
+// the last component is followed only if followLast is set.
+func (fs *MemoryFileSystem) lookup(op, name string, followLast bool) (*memoryNode, error) {
+	components, ok := splitName(name)
+	if !ok {
+		return nil, notExistError(op, name)
+	}
+
+	node, ok := fs.resolve(components, followLast, 0)
+	if !ok {
+		return nil, notExistError(op, name)
+	}
+	return node, nil
+}
+
+func (fs *MemoryFileSystem) resolve(components []string, followLast bool, depth int) (*memoryNode, bool) {
+	node := fs.root
+	for i, component := range components {
+		if !node.isDir() {
+			return nil, false
+		}
+		child, ok := node.children[component]
+		if !ok {
+			return nil, false
+		}
+
+		last := i == len(components)-1
+		if child.isSymlink() && (!last || followLast) {
+			if depth >= maxSymlinkDepth {
+				return nil, false
+			}
+
+			target := string(child.content)
+			if filepath.IsAbs(target) {
+				return nil, false
+			}
+			linkDir := filepath.Join(components[:i]...)
+			targetComponents, ok := splitName(filepath.Join(linkDir, target))
+			if !ok {
+				return nil, false
+			}
+
+			return fs.resolve(append(targetComponents, components[i+1:]...), followLast, depth+1)
+		}
+
+		node = child
+	}
+	return node, true
+}
+
+// Open ...
+func (fs *MemoryFileSystem) Open(name string) (io.ReadCloser, error) {
+	node, err := fs.lookup("open", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if node.isDir() {
+		return nil, &os.PathError{Op: "open", Path: name, Err: fmt.Errorf("is a directory")}
+	}
+	return ioutil.NopCloser(bytes.NewReader(node.content)), nil
+}
+
+// Stat ...
+func (fs *MemoryFileSystem) Stat(name string) (os.FileInfo, error) {
+	node, err := fs.lookup("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return memoryFileInfo{node: node, name: filepath.Base(name)}, nil
+}
+
+// Lstat ...
+func (fs *MemoryFileSystem) Lstat(name string) (os.FileInfo, error) {
+	node, err := fs.lookup("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return memoryFileInfo{node: node}, nil
+}
+
+// ReadDir ...
+func (fs *MemoryFileSystem) ReadDir(name string) ([]os.FileInfo, error) {
+	node, err := fs.lookup("readdir", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if !node.isDir() {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: fmt.Errorf("not a directory")}
+	}
+
+	names := make([]string, 0, len(node.children))
+	for childName := range node.children {
+		names = append(names, childName)
+	}
+	sort.Strings(names)
+
+	infos := make([]os.FileInfo, len(names))
+	for i, childName := range names {
+		infos[i] = memoryFileInfo{node: node.children[childName]}
+	}
+	return infos, nil
+}
@@ -0,0 +1,71 @@
+package filesystem
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// OSFileSystem is the FileSystem of a directory on the disk.
+type OSFileSystem struct {
+	rootDir string
+}
+
+// NewOSFileSystem returns the FileSystem of the rootDir.
+func NewOSFileSystem(rootDir string) (*OSFileSystem, error) {
+	absRootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return &OSFileSystem{rootDir: absRootDir}, nil
+}
+
+// RootDir returns the absolute path of the root directory.
+func (fs *OSFileSystem) RootDir() string {
+	return fs.rootDir
+}
+
+func (fs *OSFileSystem) path(op, name string) (string, error) {
+	cleanName, ok := CleanName(name)
+	if !ok {
+		return "", notExistError(op, name)
+	}
+	return filepath.Join(fs.rootDir, cleanName), nil
+}
+
+// Open ...
+func (fs *OSFileSystem) Open(name string) (io.ReadCloser, error) {
+	pth, err := fs.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(pth)
+}
+
+// Stat ...
+func (fs *OSFileSystem) Stat(name string) (os.FileInfo, error) {
+	pth, err := fs.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(pth)
+}
+
+// Lstat ...
+func (fs *OSFileSystem) Lstat(name string) (os.FileInfo, error) {
+	pth, err := fs.path("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Lstat(pth)
+}
+
+// ReadDir ...
+func (fs *OSFileSystem) ReadDir(name string) ([]os.FileInfo, error) {
+	pth, err := fs.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadDir(pth)
+}
@@ -0,0 +1,90 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// NewTarFileSystem reads the tar archive (optionally gzip compressed) into an Archive,
+// the archive is not extracted to the disk.
+func NewTarFileSystem(reader io.Reader) (*Archive, error) {
+	bufferedReader := bufio.NewReader(reader)
+	if magic, err := bufferedReader.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gzipReader, err := gzip.NewReader(bufferedReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip stream, error: %s", err)
+		}
+		defer gzipReader.Close()
+
+		return readTar(gzipReader)
+	}
+
+	return readTar(bufferedReader)
+}
+
+// OpenTarball reads the tar or tar.gz archive at the given path into an Archive.
+func OpenTarball(pth string) (*Archive, error) {
+	file, err := os.Open(pth)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewTarFileSystem(file)
+}
+
+func readTar(reader io.Reader) (*Archive, error) {
+	archive, err := NewArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry, error: %s", err)
+		}
+
+		if !archive.countEntry(header.Name) {
+			break
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			archive.AddEntry(header.Name, archive.AddDir)
+		case tar.TypeReg, tar.TypeRegA:
+			content, ok, err := archive.readEntry(header.Name, tarReader, header.Size)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read tar entry (%s), error: %s", header.Name, err)
+			} else if !ok {
+				continue
+			}
+			archive.AddEntry(header.Name, func(name string) error {
+				return archive.AddFile(name, content)
+			})
+		case tar.TypeSymlink:
+			archive.AddEntry(header.Name, func(name string) error {
+				return archive.AddSymlink(name, header.Linkname)
+			})
+		case tar.TypeLink:
+			archive.AddEntry(header.Name, func(name string) error {
+				return archive.addHardlink(name, header.Linkname)
+			})
+		case tar.TypeXGlobalHeader:
+		default:
+			archive.Skip(header.Name)
+		}
+	}
+
+	return archive, nil
+}
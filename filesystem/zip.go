@@ -0,0 +1,89 @@
+package filesystem
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// NewZipFileSystem reads the zip archive into an Archive, the archive is not extracted to the disk.
+func NewZipFileSystem(reader io.ReaderAt, size int64) (*Archive, error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip archive, error: %s", err)
+	}
+
+	archive, err := NewArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range zipReader.File {
+		if !archive.countEntry(file.Name) {
+			break
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir() || strings.HasSuffix(file.Name, "/"):
+			archive.AddEntry(file.Name, archive.AddDir)
+		case mode.IsRegular():
+			content, ok, err := readZipEntry(archive, file)
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+			archive.AddEntry(file.Name, func(name string) error {
+				return archive.AddFile(name, content)
+			})
+		case mode&os.ModeSymlink != 0:
+			// the symlink's target is stored as the entry's content
+			target, ok, err := readZipEntry(archive, file)
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+			archive.AddEntry(file.Name, func(name string) error {
+				return archive.AddSymlink(name, string(target))
+			})
+		default:
+			archive.Skip(file.Name)
+		}
+	}
+
+	return archive, nil
+}
+
+// OpenZip reads the zip archive at the given path into an Archive.
+func OpenZip(pth string) (*Archive, error) {
+	file, err := os.Open(pth)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewZipFileSystem(file, info.Size())
+}
+
+func readZipEntry(archive *Archive, file *zip.File) ([]byte, bool, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open zip entry (%s), error: %s", file.Name, err)
+	}
+	defer reader.Close()
+
+	content, ok, err := archive.readEntry(file.Name, reader, int64(file.UncompressedSize64))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read zip entry (%s), error: %s", file.Name, err)
+	}
+	return content, ok, nil
+}
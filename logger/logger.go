@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/go-utils/colorstring"
+	"github.com/bitrise-io/go-utils/log"
+)
+
+const timestampLayout = "15:04:05"
+
+// Logger ...
+type Logger interface {
+	Printft(format string, v ...interface{})
+	Infoft(format string, v ...interface{})
+	Doneft(format string, v ...interface{})
+	Warnft(format string, v ...interface{})
+	Errorft(format string, v ...interface{})
+}
+
+//
+// Default logger
+
+type defaultLogger struct{}
+
+// NewDefaultLogger returns a Logger, which prints directly to the standard output.
+func NewDefaultLogger() Logger {
+	return defaultLogger{}
+}
+
+// Printft ...
+func (defaultLogger) Printft(format string, v ...interface{}) {
+	log.Printft(format, v...)
+}
+
+// Infoft ...
+func (defaultLogger) Infoft(format string, v ...interface{}) {
+	log.Infoft(format, v...)
+}
+
+// Doneft ...
+func (defaultLogger) Doneft(format string, v ...interface{}) {
+	log.Doneft(format, v...)
+}
+
+// Warnft ...
+func (defaultLogger) Warnft(format string, v ...interface{}) {
+	log.Warnft(format, v...)
+}
+
+// Errorft ...
+func (defaultLogger) Errorft(format string, v ...interface{}) {
+	log.Errorft(format, v...)
+}
+
+//
+// Writer logger
+
+type writerLogger struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// NewWriterLogger returns a Logger, which prints to the given writer.
+// It is safe for concurrent use.
+func NewWriterLogger(writer io.Writer) Logger {
+	return &writerLogger{writer: writer}
+}
+
+func (logger *writerLogger) printfWithColorAndTime(color colorstring.ColorfFunc, format string, v ...interface{}) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	strWithColor := color(format, v...)
+	fmt.Fprintf(logger.writer, "[%s] %s\n", time.Now().Format(timestampLayout), strWithColor)
+}
+
+// Printft ...
+func (logger *writerLogger) Printft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.NoColorf, format, v...)
+}
+
+// Infoft ...
+func (logger *writerLogger) Infoft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.Bluef, format, v...)
+}
+
+// Doneft ...
+func (logger *writerLogger) Doneft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.Greenf, format, v...)
+}
+
+// Warnft ...
+func (logger *writerLogger) Warnft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.Yellowf, format, v...)
+}
+
+// Errorft ...
+func (logger *writerLogger) Errorft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.Redf, format, v...)
+}
+
+//
+// Buffered logger
+
+// BufferedLogger collects the log lines in memory,
+// the collected lines can be written out in one batch by calling Flush.
+// It is safe for concurrent use.
+type BufferedLogger struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+// NewBufferedLogger ...
+func NewBufferedLogger() *BufferedLogger {
+	return &BufferedLogger{}
+}
+
+func (logger *BufferedLogger) printfWithColorAndTime(color colorstring.ColorfFunc, format string, v ...interface{}) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	strWithColor := color(format, v...)
+	fmt.Fprintf(&logger.buffer, "[%s] %s\n", time.Now().Format(timestampLayout), strWithColor)
+}
+
+// Printft ...
+func (logger *BufferedLogger) Printft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.NoColorf, format, v...)
+}
+
+// Infoft ...
+func (logger *BufferedLogger) Infoft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.Bluef, format, v...)
+}
+
+// Doneft ...
+func (logger *BufferedLogger) Doneft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.Greenf, format, v...)
+}
+
+// Warnft ...
+func (logger *BufferedLogger) Warnft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.Yellowf, format, v...)
+}
+
+// Errorft ...
+func (logger *BufferedLogger) Errorft(format string, v ...interface{}) {
+	logger.printfWithColorAndTime(colorstring.Redf, format, v...)
+}
+
+// String returns the collected log lines.
+func (logger *BufferedLogger) String() string {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	return logger.buffer.String()
+}
+
+// Flush writes the collected log lines to the writer and resets the buffer.
+func (logger *BufferedLogger) Flush(writer io.Writer) error {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	if _, err := logger.buffer.WriteTo(writer); err != nil {
+		return err
+	}
+	logger.buffer.Reset()
+	return nil
+}
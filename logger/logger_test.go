@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferedLogger(t *testing.T) {
+	logger := NewBufferedLogger()
+	logger.Printft("first %s", "line")
+	logger.Infoft("second line")
+
+	lines := strings.Split(strings.TrimSpace(logger.String()), "\n")
+	require.Equal(t, 2, len(lines))
+	require.True(t, strings.HasSuffix(lines[0], "] first line"), lines[0])
+	require.True(t, strings.Contains(lines[1], "second line"), lines[1])
+
+	var buff bytes.Buffer
+	require.NoError(t, logger.Flush(&buff))
+	require.Equal(t, strings.Join(lines, "\n")+"\n", buff.String())
+	require.Equal(t, "", logger.String())
+}
+
+func TestWriterLogger(t *testing.T) {
+	var buff bytes.Buffer
+	logger := NewWriterLogger(&buff)
+	logger.Printft("first %s", "line")
+	logger.Warnft("second line")
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	require.Equal(t, 2, len(lines))
+	require.True(t, strings.HasSuffix(lines[0], "] first line"), lines[0])
+	require.True(t, strings.Contains(lines[1], "second line"), lines[1])
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Weights of the common detection evidence, the confidence score is the sum of the evidence weights.
+const (
+	// MaxConfidenceScore is the score of a certain detection.
+	MaxConfidenceScore = 100
+	// ProjectFileWeight is the weight of the project files, the platform is detected by (like a build.gradle or an .xcodeproj).
+	ProjectFileWeight = 40
+	// SupportingFileWeight is the weight of a file, which complements the project files (like a lock file or a shared scheme).
+	SupportingFileWeight = 15
+	// RootLevelWeight is the weight of a root level project, the weight decreases by DepthWeightStep with every directory level.
+	RootLevelWeight = 30
+	// DepthWeightStep ...
+	DepthWeightStep = 10
+)
+
+// Evidence is a finding, which supports (or with negative weight, weakens) a platform detection.
+type Evidence struct {
+	Description string `json:"description" yaml:"description"`
+	Weight      int    `json:"weight" yaml:"weight"`
+	// Paths lists the files behind the finding, relative to the search dir.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
+
+// Confidence describes how likely it is, that the scanned project belongs to the scanner's platform.
+// The Score is the sum of the evidence weights, limited to 0-MaxConfidenceScore.
+type Confidence struct {
+	Score    int        `json:"score" yaml:"score"`
+	Evidence []Evidence `json:"evidence,omitempty" yaml:"evidence,omitempty"`
+}
+
+// NewEvidence ...
+func NewEvidence(weight int, description string, paths ...string) Evidence {
+	return Evidence{
+		Description: description,
+		Weight:      weight,
+		Paths:       paths,
+	}
+}
+
+// NewConfidence returns the confidence, backed by the given evidence, the evidence without weight is dropped.
+func NewConfidence(evidence ...Evidence) Confidence {
+	confidence := Confidence{}
+	for _, e := range evidence {
+		if e.Weight == 0 {
+			continue
+		}
+		confidence.Score += e.Weight
+		confidence.Evidence = append(confidence.Evidence, e)
+	}
+	confidence.Score = normalizedScore(confidence.Score)
+	return confidence
+}
+
+// Normalized returns the confidence with the score recalculated from the evidence,
+// or with the score limited to 0-MaxConfidenceScore, if it has no evidence.
+func (confidence Confidence) Normalized() Confidence {
+	if len(confidence.Evidence) == 0 {
+		return Confidence{Score: normalizedScore(confidence.Score)}
+	}
+	return NewConfidence(confidence.Evidence...)
+}
+
+func normalizedScore(score int) int {
+	if score < 0 {
+		return 0
+	}
+	if score > MaxConfidenceScore {
+		return MaxConfidenceScore
+	}
+	return score
+}
+
+// PathDepth returns the number of directories the path is nested in, a root level file's depth is 0.
+func PathDepth(pth string) int {
+	dir := filepath.ToSlash(filepath.Dir(filepath.Clean(pth)))
+	if dir == "." || dir == "/" {
+		return 0
+	}
+	return strings.Count(strings.Trim(dir, "/"), "/") + 1
+}
+
+// DepthEvidence returns the evidence of the shallowest of the given project files,
+// a root level project weighs RootLevelWeight, the deeper projects weigh less.
+func DepthEvidence(paths ...string) Evidence {
+	if len(paths) == 0 {
+		return Evidence{}
+	}
+
+	shallowest := paths[0]
+	for _, pth := range paths[1:] {
+		if PathDepth(pth) < PathDepth(shallowest) {
+			shallowest = pth
+		}
+	}
+
+	depth := PathDepth(shallowest)
+	description := "root level project"
+	if depth > 0 {
+		description = fmt.Sprintf("project at depth %d", depth)
+	}
+
+	weight := RootLevelWeight - depth*DepthWeightStep
+	if weight < 0 {
+		weight = 0
+	}
+	return NewEvidence(weight, description, shallowest)
+}
+
+// RankedPlatforms returns the detected platforms (the platforms with options) ordered by their confidence score,
+// the best match comes first, the platforms with the same score are ordered by name.
+func (result ScanResultModel) RankedPlatforms() []string {
+	platforms := []string{}
+	for platform := range result.PlatformOptionMap {
+		platforms = append(platforms, platform)
+	}
+
+	sort.SliceStable(platforms, func(i, j int) bool {
+		scoreI, scoreJ := result.PlatformConfidenceMap[platforms[i]].Score, result.PlatformConfidenceMap[platforms[j]].Score
+		if scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		return platforms[i] < platforms[j]
+	})
+	return platforms
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfidence(t *testing.T) {
+	t.Log("sums the weights")
+	{
+		confidence := NewConfidence(NewEvidence(ProjectFileWeight, "project"), NewEvidence(0, "nothing"), NewEvidence(SupportingFileWeight, "lock file"))
+		require.Equal(t, 55, confidence.Score)
+		require.Equal(t, []Evidence{NewEvidence(ProjectFileWeight, "project"), NewEvidence(SupportingFileWeight, "lock file")}, confidence.Evidence)
+	}
+
+	t.Log("limits the score")
+	{
+		require.Equal(t, MaxConfidenceScore, NewConfidence(NewEvidence(80, "a"), NewEvidence(80, "b")).Score)
+		require.Equal(t, 0, NewConfidence(NewEvidence(-10, "a")).Score)
+		require.Equal(t, MaxConfidenceScore, Confidence{Score: 200}.Normalized().Score)
+		require.Equal(t, 40, Confidence{Score: 200, Evidence: []Evidence{NewEvidence(40, "a")}}.Normalized().Score)
+	}
+}
+
+func TestDepthEvidence(t *testing.T) {
+	require.Equal(t, 0, PathDepth("build.gradle"))
+	require.Equal(t, 1, PathDepth("app/build.gradle"))
+	require.Equal(t, 2, PathDepth("ios/Sample/Sample.xcodeproj"))
+
+	require.Equal(t, NewEvidence(RootLevelWeight, "root level project", "build.gradle"), DepthEvidence("app/build.gradle", "build.gradle"))
+	require.Equal(t, NewEvidence(RootLevelWeight-2*DepthWeightStep, "project at depth 2", "a/b/build.gradle"), DepthEvidence("a/b/build.gradle"))
+	require.Equal(t, 0, DepthEvidence("a/b/c/d/build.gradle").Weight)
+	require.Equal(t, Evidence{}, DepthEvidence())
+}
+
+func TestRankedPlatforms(t *testing.T) {
+	result := ScanResultModel{
+		PlatformOptionMap: map[string]OptionModel{
+			"ios":      {},
+			"fastlane": {},
+			"xamarin":  {},
+			"android":  {},
+		},
+		PlatformConfidenceMap: map[string]Confidence{
+			"ios":      {Score: 55},
+			"fastlane": {Score: 70},
+			"xamarin":  {Score: 85},
+		},
+	}
+
+	require.Equal(t, []string{"xamarin", "fastlane", "ios", "android"}, result.RankedPlatforms())
+}
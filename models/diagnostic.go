@@ -0,0 +1,137 @@
+package models
+
+import "fmt"
+
+// GeneralPlatform is the key of the diagnostics, which are not related to a specific scanner.
+const GeneralPlatform = "general"
+
+// GeneralErrorCode is the code of the general errors, added by ScanResultModel.AddError.
+const GeneralErrorCode = "general-error"
+
+// Severity ...
+type Severity string
+
+const (
+	// SeverityInfo ...
+	SeverityInfo Severity = "info"
+	// SeverityWarning ...
+	SeverityWarning Severity = "warning"
+	// SeverityError ...
+	SeverityError Severity = "error"
+)
+
+// Diagnostic is a structured warning or error, reported by a scanner.
+// The Code is stable, it can be used to filter or to localize the diagnostics.
+// Diagnostic implements the error interface, so scanners can return it as an error.
+type Diagnostic struct {
+	Code     string   `json:"code" yaml:"code"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	// Scanner is the name of the reporting scanner, it is filled by the scan engine if not set.
+	Scanner string `json:"scanner,omitempty" yaml:"scanner,omitempty"`
+	// Paths lists the affected files, relative to the search dir.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	// Message is the plain text description, without any markup.
+	Message string `json:"message" yaml:"message"`
+	DocURL  string `json:"doc_url,omitempty" yaml:"doc_url,omitempty"`
+
+	// legacyMessage is the former free-form text of the diagnostic, see: LegacyString.
+	legacyMessage string
+}
+
+// Diagnostics ...
+type Diagnostics []Diagnostic
+
+// NewDiagnostic ...
+func NewDiagnostic(code string, severity Severity, message string, paths ...string) Diagnostic {
+	return Diagnostic{
+		Code:     code,
+		Severity: severity,
+		Message:  message,
+		Paths:    paths,
+	}
+}
+
+// NewWarning ...
+func NewWarning(code, message string, paths ...string) Diagnostic {
+	return NewDiagnostic(code, SeverityWarning, message, paths...)
+}
+
+// NewError ...
+func NewError(code, message string, paths ...string) Diagnostic {
+	return NewDiagnostic(code, SeverityError, message, paths...)
+}
+
+// NewWarningf ...
+func NewWarningf(code, format string, args ...interface{}) Diagnostic {
+	return NewWarning(code, fmt.Sprintf(format, args...))
+}
+
+// NewErrorf ...
+func NewErrorf(code, format string, args ...interface{}) Diagnostic {
+	return NewError(code, fmt.Sprintf(format, args...))
+}
+
+// WithPaths returns a copy of the diagnostic with the given affected paths.
+func (diagnostic Diagnostic) WithPaths(paths ...string) Diagnostic {
+	diagnostic.Paths = paths
+	return diagnostic
+}
+
+// WithDocURL returns a copy of the diagnostic with the given documentation link.
+func (diagnostic Diagnostic) WithDocURL(docURL string) Diagnostic {
+	diagnostic.DocURL = docURL
+	return diagnostic
+}
+
+// WithLegacyMessage returns a copy of the diagnostic with its former free-form text,
+// which is kept in the PlatformWarningsMap and PlatformErrorsMap for the existing consumers.
+func (diagnostic Diagnostic) WithLegacyMessage(message string) Diagnostic {
+	diagnostic.legacyMessage = message
+	return diagnostic
+}
+
+// Error ...
+func (diagnostic Diagnostic) Error() string {
+	return diagnostic.Message
+}
+
+// LegacyString returns the diagnostic in the format of the free-form warning and error strings,
+// stored in the ScanResultModel's PlatformWarningsMap and PlatformErrorsMap.
+// The former text of the diagnostic is returned unchanged, if it has one (see: WithLegacyMessage).
+func (diagnostic Diagnostic) LegacyString() string {
+	if diagnostic.legacyMessage != "" {
+		return diagnostic.legacyMessage
+	}
+	if diagnostic.DocURL == "" {
+		return diagnostic.Message
+	}
+	return fmt.Sprintf(`%s
+More info: <a href="%s">%s</a>`, diagnostic.Message, diagnostic.DocURL, diagnostic.DocURL)
+}
+
+// LegacyStrings returns the diagnostics in the format of the free-form warning and error strings.
+func (diagnostics Diagnostics) LegacyStrings() []string {
+	legacyStrings := []string{}
+	for _, diagnostic := range diagnostics {
+		legacyStrings = append(legacyStrings, diagnostic.LegacyString())
+	}
+	return legacyStrings
+}
+
+// DiagnosticFromError returns the diagnostic, if the err is a Diagnostic,
+// otherwise it wraps the error message into a diagnostic with the given code and severity.
+func DiagnosticFromError(err error, code string, severity Severity) Diagnostic {
+	if diagnostic, ok := err.(Diagnostic); ok {
+		return diagnostic
+	}
+	return NewDiagnostic(code, severity, err.Error())
+}
+
+// DiagnosticsFromWarnings wraps the free-form warning strings into diagnostics with the given code.
+func DiagnosticsFromWarnings(code string, warnings Warnings) Diagnostics {
+	diagnostics := Diagnostics{}
+	for _, warning := range warnings {
+		diagnostics = append(diagnostics, NewWarning(code, warning))
+	}
+	return diagnostics
+}
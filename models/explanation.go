@@ -0,0 +1,43 @@
+package models
+
+// Rejection is a candidate file, which was dropped during the detection.
+type Rejection struct {
+	Path string `json:"path" yaml:"path"`
+	// Filter is the name of the rejecting path filter (like ForbidEmbeddedWorkspaceRegexpFilter), if a filter rejected the path.
+	Filter string `json:"filter,omitempty" yaml:"filter,omitempty"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// Explanation describes, what a scanner looked for and why it did or did not detect the project.
+type Explanation struct {
+	Scanner  string `json:"scanner" yaml:"scanner"`
+	Detected bool   `json:"detected" yaml:"detected"`
+	// LookedFor describes the files, the scanner searched for.
+	LookedFor string `json:"looked_for,omitempty" yaml:"looked_for,omitempty"`
+	// Candidates lists the files found, which could identify the project.
+	Candidates []string `json:"candidates,omitempty" yaml:"candidates,omitempty"`
+	// Rejections lists the candidates, which were dropped, with the reason.
+	Rejections []Rejection `json:"rejections,omitempty" yaml:"rejections,omitempty"`
+	// ClaimedPaths lists the paths, which were hidden from the scanner, as they belong to the projects of an overriding scanner.
+	ClaimedPaths []string `json:"claimed_paths,omitempty" yaml:"claimed_paths,omitempty"`
+	// Reason is the decisive reason of the verdict.
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// NewExplanation returns the explanation of a scanner looking for the given files.
+func NewExplanation(lookedFor string, candidates ...string) Explanation {
+	return Explanation{
+		LookedFor:  lookedFor,
+		Candidates: candidates,
+	}
+}
+
+// Reject records the rejected candidate.
+func (explanation *Explanation) Reject(pth, reason string) {
+	explanation.Rejections = append(explanation.Rejections, Rejection{Path: pth, Reason: reason})
+}
+
+// Rejected records the rejections, like the ones of a filter pipeline (see: utility.FilterPipeline.Explain).
+func (explanation *Explanation) Rejected(rejections ...Rejection) {
+	explanation.Rejections = append(explanation.Rejections, rejections...)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/bitrise-core/bitrise-init/steps"
 	bitriseModels "github.com/bitrise-io/bitrise/models"
@@ -56,33 +57,36 @@ func (option *OptionModel) String() string {
 
 // AddOption ...
 func (option *OptionModel) AddOption(forValue string, newOption *OptionModel) {
+	option.addValue(forValue)
 	option.ChildOptionMap[forValue] = newOption
-
-	if newOption != nil {
-		newOption.Components = append(option.Components, forValue)
-
-		if option.Head == nil {
-			// first option's head is nil
-			newOption.Head = option
-		} else {
-			newOption.Head = option.Head
-		}
-	}
+	option.link(forValue, newOption)
 }
 
 // AddConfig ...
 func (option *OptionModel) AddConfig(forValue string, newConfigOption *OptionModel) {
+	option.addValue(forValue)
 	option.ChildOptionMap[forValue] = newConfigOption
+	option.link(forValue, newConfigOption)
+}
 
-	if newConfigOption != nil {
-		newConfigOption.Components = append(option.Components, forValue)
+// link sets the components and the head of the child option, added for the given value,
+// the child's own options are linked as well, so a prebuilt option tree (like a copy) can be added too.
+func (option *OptionModel) link(forValue string, childOption *OptionModel) {
+	if childOption == nil {
+		return
+	}
 
-		if option.Head == nil {
-			// first option's head is nil
-			newConfigOption.Head = option
-		} else {
-			newConfigOption.Head = option.Head
-		}
+	childOption.Components = append(append([]string{}, option.Components...), forValue)
+
+	if option.Head == nil {
+		// first option's head is nil
+		childOption.Head = option
+	} else {
+		childOption.Head = option.Head
+	}
+
+	for value, grandChildOption := range childOption.ChildOptionMap {
+		childOption.link(value, grandChildOption)
 	}
 }
 
@@ -126,7 +130,8 @@ func (option *OptionModel) LastChilds() []*OptionModel {
 			return
 		}
 
-		for _, childOption := range option.ChildOptionMap {
+		for _, value := range option.childValues() {
+			childOption := option.ChildOptionMap[value]
 			if childOption == nil {
 				// values are set to this option, but has value without child
 				lastOptions = append(lastOptions, option)
@@ -157,17 +162,44 @@ func (option *OptionModel) Copy() *OptionModel {
 	return &optionCopy
 }
 
-// GetValues ...
+// GetValues returns the config of a config option, or the option values in the order they were added.
 func (option *OptionModel) GetValues() []string {
 	if option.Config != "" {
 		return []string{option.Config}
 	}
 
+	return option.childValues()
+}
+
+func (option *OptionModel) addValue(value string) {
+	if _, ok := option.ChildOptionMap[value]; ok {
+		return
+	}
+	option.valueOrder = append(option.valueOrder, value)
+}
+
+// childValues returns the values of the ChildOptionMap in the order they were added,
+// followed by the values set directly in the map (like the ones of an option literal) in alphabetical order.
+func (option *OptionModel) childValues() []string {
 	values := []string{}
-	for value := range option.ChildOptionMap {
+	ordered := map[string]bool{}
+	for _, value := range option.valueOrder {
+		if _, ok := option.ChildOptionMap[value]; !ok || ordered[value] {
+			continue
+		}
+		ordered[value] = true
 		values = append(values, value)
 	}
-	return values
+
+	unordered := []string{}
+	for value := range option.ChildOptionMap {
+		if !ordered[value] {
+			unordered = append(unordered, value)
+		}
+	}
+	sort.Strings(unordered)
+
+	return append(values, unordered...)
 }
 
 // ---
@@ -368,13 +400,41 @@ func (builder *ConfigBuilderModel) Generate(projectType string, appEnvs ...envma
 
 // ---
 
-// AddError ...
+// AddError adds a general error diagnostic for the given platform.
 func (result *ScanResultModel) AddError(platform string, errorMessage string) {
-	if result.PlatformErrorsMap == nil {
-		result.PlatformErrorsMap = map[string]Errors{}
+	diagnostic := NewError(GeneralErrorCode, errorMessage)
+	if platform != GeneralPlatform {
+		diagnostic.Scanner = platform
+	}
+	result.AddDiagnostic(diagnostic)
+}
+
+// AddDiagnostic adds the diagnostic to the result,
+// and to the legacy warnings (info and warning severity) or errors (error severity) of the diagnostic's scanner.
+func (result *ScanResultModel) AddDiagnostic(diagnostic Diagnostic) {
+	platform := diagnostic.Scanner
+	if platform == "" {
+		platform = GeneralPlatform
+	}
+
+	result.Diagnostics = append(result.Diagnostics, diagnostic)
+
+	if diagnostic.Severity == SeverityError {
+		if result.PlatformErrorsMap == nil {
+			result.PlatformErrorsMap = map[string]Errors{}
+		}
+		if result.PlatformErrorsMap[platform] == nil {
+			result.PlatformErrorsMap[platform] = []string{}
+		}
+		result.PlatformErrorsMap[platform] = append(result.PlatformErrorsMap[platform], diagnostic.LegacyString())
+		return
+	}
+
+	if result.PlatformWarningsMap == nil {
+		result.PlatformWarningsMap = map[string]Warnings{}
 	}
-	if result.PlatformErrorsMap[platform] == nil {
-		result.PlatformErrorsMap[platform] = []string{}
+	if result.PlatformWarningsMap[platform] == nil {
+		result.PlatformWarningsMap[platform] = []string{}
 	}
-	result.PlatformErrorsMap[platform] = append(result.PlatformErrorsMap[platform], errorMessage)
+	result.PlatformWarningsMap[platform] = append(result.PlatformWarningsMap[platform], diagnostic.LegacyString())
 }
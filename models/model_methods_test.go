@@ -40,6 +40,27 @@ func TestGetValues(t *testing.T) {
 	}
 
 	require.Equal(t, 0, len(expectedMap))
+
+	t.Log("values keep the order they were added in")
+	{
+		option := NewOption("Gradle task", "GRADLE_TASK")
+		option.AddOption("assembleRelease", nil)
+		option.AddOption("assembleDebug", nil)
+		option.AddOption("assembleRelease", nil)
+		option.AddConfig("assembleAndroidTest", NewConfigOption("android-config"))
+
+		require.Equal(t, []string{"assembleRelease", "assembleDebug", "assembleAndroidTest"}, option.GetValues())
+	}
+
+	t.Log("values set directly in the map follow in alphabetical order")
+	{
+		option := NewOption("Gradle task", "GRADLE_TASK")
+		option.AddOption("assembleRelease", nil)
+		option.ChildOptionMap["assembleDebug"] = nil
+		option.ChildOptionMap["assembleAndroidTest"] = nil
+
+		require.Equal(t, []string{"assembleRelease", "assembleAndroidTest", "assembleDebug"}, option.GetValues())
+	}
 }
 
 func TestLastOptions(t *testing.T) {
@@ -79,6 +100,12 @@ func TestLastOptions(t *testing.T) {
 	require.Equal(t, true, optionsMap["OPT01"])
 	require.Equal(t, true, optionsMap["OPT0211"])
 	require.Equal(t, true, optionsMap["OPT02121"])
+
+	titles := []string{}
+	for _, opt := range lastOptions {
+		titles = append(titles, opt.Title)
+	}
+	require.Equal(t, []string{"OPT01", "OPT0211", "OPT02121"}, titles)
 }
 
 func TestCopy(t *testing.T) {
@@ -159,6 +186,16 @@ func TestComponents(t *testing.T) {
 	require.Equal(t, []string{"value2", "value1", "value1"}, opt0211.Components)
 	require.Equal(t, []string{"value2", "value1", "value2"}, opt0212.Components)
 	require.Equal(t, []string{"value2", "value1", "value2", "value1"}, opt02121.Components)
+
+	// prebuilt subtree
+	subOpt := NewOption("SUB", "SUB_KEY")
+	subOpt1 := NewOption("SUB1", "SUB1_KEY")
+	subOpt.AddOption("value1", subOpt1)
+
+	opt02121.AddOption("value1", subOpt)
+	require.Equal(t, []string{"value2", "value1", "value2", "value1", "value1"}, subOpt.Components)
+	require.Equal(t, []string{"value2", "value1", "value2", "value1", "value1", "value1"}, subOpt1.Components)
+	require.Equal(t, opt0, subOpt1.Head)
 }
 
 func TestHead(t *testing.T) {
@@ -225,3 +262,34 @@ func TestParent(t *testing.T) {
 		require.Equal(t, true, ok)
 	}
 }
+
+func TestAddDiagnostic(t *testing.T) {
+	result := ScanResultModel{}
+
+	warning := NewWarning("android-local-properties-committed", "local.properties committed", "local.properties").WithDocURL("https://devcenter.bitrise.io")
+	warning.Scanner = "android"
+	result.AddDiagnostic(warning)
+	result.AddError(GeneralPlatform, "No known platform detected")
+
+	require.Equal(t, Diagnostics{warning, NewError(GeneralErrorCode, "No known platform detected")}, result.Diagnostics)
+	require.Equal(t, map[string]Warnings{
+		"android": {"local.properties committed\nMore info: <a href=\"https://devcenter.bitrise.io\">https://devcenter.bitrise.io</a>"},
+	}, result.PlatformWarningsMap)
+	require.Equal(t, map[string]Errors{
+		"general": {"No known platform detected"},
+	}, result.PlatformErrorsMap)
+}
+
+func TestLegacyString(t *testing.T) {
+	t.Log("the former text is kept")
+	{
+		diagnostic := NewWarning("xcode-no-shared-schemes", "Make sure to share your schemes.").WithDocURL("https://devcenter.bitrise.io").WithLegacyMessage(`Make sure to <a href="https://devcenter.bitrise.io">share your schemes</a>.`)
+		require.Equal(t, `Make sure to <a href="https://devcenter.bitrise.io">share your schemes</a>.`, diagnostic.LegacyString())
+		require.Equal(t, "Make sure to share your schemes.", diagnostic.Message)
+	}
+
+	t.Log("without former text")
+	{
+		require.Equal(t, "No known platform detected", NewError(GeneralErrorCode, "No known platform detected").LegacyString())
+	}
+}
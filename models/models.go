@@ -12,6 +12,26 @@ type OptionModel struct {
 
 	Components []string     `json:"-" yaml:"-"`
 	Head       *OptionModel `json:"-" yaml:"-"`
+
+	// valueOrder lists the values of the ChildOptionMap in the order they were added (see: GetValues).
+	valueOrder []string
+}
+
+// ScannerRelationships declares how a scanner relates to the other scanners,
+// the relationships are resolved into a scan plan, independently of the scanners order.
+type ScannerRelationships struct {
+	// Precedes lists the scanners, which should be resolved after the scanner.
+	// If conflicting scanners detect the same project, only the preceding scanner's output is kept.
+	Precedes []string `json:"precedes,omitempty" yaml:"precedes,omitempty"`
+	// ConflictsWith lists the scanners, whose output can not be used together with the scanner's output,
+	// like the ios scanner detecting the generated Xcode project of a cordova project.
+	// Every conflict has to be resolved by precedence. The preceding scanner's claimed paths (see: scanners.PathClaimer)
+	// are hidden from the conflicting scanner, without claimed paths the conflicting scanner's whole output is dropped.
+	ConflictsWith []string `json:"conflicts_with,omitempty" yaml:"conflicts_with,omitempty"`
+	// Augments lists the scanners, which are complemented by the scanner (see: scanners.ConfigAugmenter),
+	// like the spm scanner adds the Swift package dependency resolution to the ios configs.
+	// The augmenting scanner is resolved after the augmented scanners and can not conflict with them.
+	Augments []string `json:"augments,omitempty" yaml:"augments,omitempty"`
 }
 
 // BitriseConfigMap ...
@@ -29,6 +49,36 @@ type ScanResultModel struct {
 	PlatformConfigMapMap map[string]BitriseConfigMap `json:"configs,omitempty" yaml:"configs,omitempty"`
 	PlatformWarningsMap  map[string]Warnings         `json:"warnings,omitempty" yaml:"warnings,omitempty"`
 	PlatformErrorsMap    map[string]Errors           `json:"errors,omitempty" yaml:"errors,omitempty"`
+
+	// ScannerNames lists the scanners, whose outputs are included in the result.
+	ScannerNames []string `json:"scanners,omitempty" yaml:"scanners,omitempty"`
+	// TimedOutScannerNames lists the scanners, which did not finish in time, their outputs are missing from the result.
+	TimedOutScannerNames []string `json:"timed_out_scanners,omitempty" yaml:"timed_out_scanners,omitempty"`
+
+	// Diagnostics holds the structured warnings and errors,
+	// the PlatformWarningsMap and PlatformErrorsMap contain their legacy string format.
+	Diagnostics Diagnostics `json:"diagnostics,omitempty" yaml:"diagnostics,omitempty"`
+
+	// PlatformConfidenceMap holds the detection confidence of the platforms with options.
+	PlatformConfidenceMap map[string]Confidence `json:"confidences,omitempty" yaml:"confidences,omitempty"`
+	// Ranking lists the platforms with options, ordered by their confidence, the best match comes first (see: RankedPlatforms).
+	Ranking []string `json:"ranking,omitempty" yaml:"ranking,omitempty"`
+
+	// Explanations describe why each scanner did or did not detect the project, in the order the scanners ran.
+	// They are collected only if requested (see: scanner.ScanOptions.Explain).
+	Explanations []Explanation `json:"explanations,omitempty" yaml:"explanations,omitempty"`
+
+	// Timings summarize the run time of the scanners, in the order the scanners ran.
+	// They are collected only if the scan events are requested (see: scanner.ScanOptions.Events).
+	Timings []ScannerTiming `json:"timings,omitempty" yaml:"timings,omitempty"`
+}
+
+// ScannerTiming is the run time of a scanner.
+type ScannerTiming struct {
+	Scanner    string `json:"scanner" yaml:"scanner"`
+	DurationMs int64  `json:"duration_ms" yaml:"duration_ms"`
+	// Stopped is set, if the scanner timed out or got canceled, its duration is the time it was waited for.
+	Stopped bool `json:"stopped,omitempty" yaml:"stopped,omitempty"`
 }
 
 type workflowBuilderModel struct {
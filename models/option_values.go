@@ -0,0 +1,192 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// optionValue is a value of an option, with the option (or config option) belonging to it.
+type optionValue struct {
+	Value  string
+	Option *OptionModel
+}
+
+// orderedOptionMap is the ChildOptionMap of an option in value order,
+// it is serialized as a map, but the map keys keep the value order, instead of the alphabetical order.
+type orderedOptionMap []optionValue
+
+// MarshalJSON ...
+func (optionMap orderedOptionMap) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	for i, item := range optionMap {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+
+		key, err := json.Marshal(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(item.Option)
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON ...
+func (optionMap *orderedOptionMap) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		*optionMap = nil
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("value map should be an object, got: %v", token)
+	}
+
+	items := orderedOptionMap{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		value, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("value map key should be a string, got: %v", token)
+		}
+
+		var option *OptionModel
+		if err := decoder.Decode(&option); err != nil {
+			return err
+		}
+		items = append(items, optionValue{Value: value, Option: option})
+	}
+
+	*optionMap = items
+	return nil
+}
+
+// MarshalYAML ...
+func (optionMap orderedOptionMap) MarshalYAML() (interface{}, error) {
+	items := yaml.MapSlice{}
+	for _, item := range optionMap {
+		if item.Option == nil {
+			// the yaml encoder would call the value receiver MarshalYAML of the nil option
+			items = append(items, yaml.MapItem{Key: item.Value, Value: nil})
+			continue
+		}
+		items = append(items, yaml.MapItem{Key: item.Value, Value: item.Option})
+	}
+	return items, nil
+}
+
+// UnmarshalYAML ...
+func (optionMap *orderedOptionMap) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// the map slice keeps the value order, the map decodes the options
+	var keys yaml.MapSlice
+	if err := unmarshal(&keys); err != nil {
+		return err
+	}
+	var options map[string]*OptionModel
+	if err := unmarshal(&options); err != nil {
+		return err
+	}
+
+	items := orderedOptionMap{}
+	for _, key := range keys {
+		value := fmt.Sprint(key.Key)
+		option, ok := options[value]
+		if !ok {
+			continue
+		}
+		items = append(items, optionValue{Value: value, Option: option})
+	}
+
+	*optionMap = items
+	return nil
+}
+
+// serializedOption is the serialized form of an OptionModel, with the option values in order.
+type serializedOption struct {
+	Title  string `json:"title,omitempty" yaml:"title,omitempty"`
+	EnvKey string `json:"env_key,omitempty" yaml:"env_key,omitempty"`
+
+	ValueMap orderedOptionMap `json:"value_map,omitempty" yaml:"value_map,omitempty"`
+	Config   string           `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+func (option OptionModel) serialized() serializedOption {
+	serialized := serializedOption{
+		Title:  option.Title,
+		EnvKey: option.EnvKey,
+		Config: option.Config,
+	}
+	for _, value := range option.childValues() {
+		serialized.ValueMap = append(serialized.ValueMap, optionValue{Value: value, Option: option.ChildOptionMap[value]})
+	}
+	return serialized
+}
+
+func (option *OptionModel) setSerialized(serialized serializedOption) {
+	*option = OptionModel{
+		Title:  serialized.Title,
+		EnvKey: serialized.EnvKey,
+		Config: serialized.Config,
+	}
+
+	if serialized.ValueMap == nil {
+		return
+	}
+
+	option.ChildOptionMap = map[string]*OptionModel{}
+	for _, item := range serialized.ValueMap {
+		option.addValue(item.Value)
+		option.ChildOptionMap[item.Value] = item.Option
+	}
+}
+
+// MarshalJSON encodes the option with its values in order (see: GetValues).
+func (option OptionModel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(option.serialized())
+}
+
+// UnmarshalJSON decodes the option, the values keep the order of the value map.
+func (option *OptionModel) UnmarshalJSON(data []byte) error {
+	var serialized serializedOption
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return err
+	}
+	option.setSerialized(serialized)
+	return nil
+}
+
+// MarshalYAML encodes the option with its values in order (see: GetValues).
+func (option OptionModel) MarshalYAML() (interface{}, error) {
+	return option.serialized(), nil
+}
+
+// UnmarshalYAML decodes the option, the values keep the order of the value map.
+func (option *OptionModel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var serialized serializedOption
+	if err := unmarshal(&serialized); err != nil {
+		return err
+	}
+	option.setSerialized(serialized)
+	return nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func testOrderedOption() *OptionModel {
+	option := NewOption("Platform", "PLATFORM")
+	option.AddConfig("ios", NewConfigOption("ios-config"))
+	option.AddConfig("android", NewConfigOption("android-config"))
+
+	schemeOption := NewOption("Scheme", "SCHEME")
+	schemeOption.AddOption("Zeta", nil)
+	schemeOption.AddOption("Alpha", nil)
+	option.AddOption("ios,android", schemeOption)
+
+	return option
+}
+
+func TestOptionJSON(t *testing.T) {
+	option := testOrderedOption()
+
+	t.Log("values are encoded in order")
+	{
+		content, err := json.Marshal(option)
+		require.NoError(t, err)
+		require.Equal(t, `{"title":"Platform","env_key":"PLATFORM","value_map":{"ios":{"config":"ios-config"},"android":{"config":"android-config"},"ios,android":{"title":"Scheme","env_key":"SCHEME","value_map":{"Zeta":null,"Alpha":null}}}}`, string(content))
+	}
+
+	t.Log("values keep the order after decoding")
+	{
+		content, err := json.Marshal(option)
+		require.NoError(t, err)
+
+		var decoded OptionModel
+		require.NoError(t, json.Unmarshal(content, &decoded))
+		require.Equal(t, []string{"ios", "android", "ios,android"}, decoded.GetValues())
+		require.Equal(t, []string{"Zeta", "Alpha"}, decoded.ChildOptionMap["ios,android"].GetValues())
+		require.Equal(t, "android-config", decoded.ChildOptionMap["android"].Config)
+	}
+
+	t.Log("invalid value map")
+	{
+		var decoded OptionModel
+		require.Error(t, json.Unmarshal([]byte(`{"value_map":["ios"]}`), &decoded))
+	}
+}
+
+func TestOptionYAML(t *testing.T) {
+	option := testOrderedOption()
+
+	content, err := yaml.Marshal(option)
+	require.NoError(t, err)
+	require.Equal(t, `title: Platform
+env_key: PLATFORM
+value_map:
+  ios:
+    config: ios-config
+  android:
+    config: android-config
+  ios,android:
+    title: Scheme
+    env_key: SCHEME
+    value_map:
+      Zeta: null
+      Alpha: null
+`, string(content))
+
+	var decoded OptionModel
+	require.NoError(t, yaml.Unmarshal(content, &decoded))
+	require.Equal(t, []string{"ios", "android", "ios,android"}, decoded.GetValues())
+	require.Equal(t, []string{"Zeta", "Alpha"}, decoded.ChildOptionMap["ios,android"].GetValues())
+	require.Nil(t, decoded.ChildOptionMap["ios,android"].ChildOptionMap["Zeta"])
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-io/go-utils/colorstring"
+)
+
+func diagnosticHeader(diagnostic models.Diagnostic) string {
+	scanner := diagnostic.Scanner
+	if scanner == "" {
+		scanner = models.GeneralPlatform
+	}
+	return fmt.Sprintf("%s [%s] %s", diagnostic.Severity, scanner, diagnostic.Code)
+}
+
+// RenderTerminal renders the diagnostics as colored plain text, for printing to the terminal.
+func RenderTerminal(diagnostics models.Diagnostics) string {
+	var buffer bytes.Buffer
+	for _, diagnostic := range diagnostics {
+		header := diagnosticHeader(diagnostic)
+		switch diagnostic.Severity {
+		case models.SeverityError:
+			header = colorstring.Red(header)
+		case models.SeverityWarning:
+			header = colorstring.Yellow(header)
+		default:
+			header = colorstring.Blue(header)
+		}
+
+		buffer.WriteString(header + "\n")
+		for _, line := range strings.Split(diagnostic.Message, "\n") {
+			buffer.WriteString("  " + line + "\n")
+		}
+		for _, pth := range diagnostic.Paths {
+			buffer.WriteString("  - " + pth + "\n")
+		}
+		if diagnostic.DocURL != "" {
+			buffer.WriteString("  More info: " + diagnostic.DocURL + "\n")
+		}
+	}
+	return buffer.String()
+}
+
+// RenderMarkdown renders the diagnostics as a Markdown list.
+func RenderMarkdown(diagnostics models.Diagnostics) string {
+	var buffer bytes.Buffer
+	for _, diagnostic := range diagnostics {
+		buffer.WriteString(fmt.Sprintf("- **%s**\n", diagnosticHeader(diagnostic)))
+		for _, line := range strings.Split(diagnostic.Message, "\n") {
+			buffer.WriteString("  " + line + "\n")
+		}
+		for _, pth := range diagnostic.Paths {
+			buffer.WriteString("  - `" + pth + "`\n")
+		}
+		if diagnostic.DocURL != "" {
+			buffer.WriteString(fmt.Sprintf("  [More info](%s)\n", diagnostic.DocURL))
+		}
+	}
+	return buffer.String()
+}
+
+// RenderHTML renders the diagnostics as an HTML list, the diagnostic fields are escaped.
+func RenderHTML(diagnostics models.Diagnostics) string {
+	if len(diagnostics) == 0 {
+		return ""
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("<ul>\n")
+	for _, diagnostic := range diagnostics {
+		buffer.WriteString(fmt.Sprintf(`<li class="%s" data-code="%s">`, html.EscapeString(string(diagnostic.Severity)), html.EscapeString(diagnostic.Code)))
+		buffer.WriteString("<b>" + html.EscapeString(diagnosticHeader(diagnostic)) + "</b><br>")
+		buffer.WriteString(strings.Replace(html.EscapeString(diagnostic.Message), "\n", "<br>", -1))
+		if len(diagnostic.Paths) > 0 {
+			buffer.WriteString("<ul>")
+			for _, pth := range diagnostic.Paths {
+				buffer.WriteString("<li><code>" + html.EscapeString(pth) + "</code></li>")
+			}
+			buffer.WriteString("</ul>")
+		}
+		if diagnostic.DocURL != "" {
+			buffer.WriteString(fmt.Sprintf(`<br><a href="%s">More info</a>`, html.EscapeString(diagnostic.DocURL)))
+		}
+		buffer.WriteString("</li>\n")
+	}
+	buffer.WriteString("</ul>\n")
+	return buffer.String()
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDiagnostics(t *testing.T) {
+	warning := models.NewWarning("android-gradlew-not-found", "No <gradlew> found.\nUse a wrapper.", "app/build.gradle").WithDocURL("https://docs.gradle.org")
+	warning.Scanner = "android"
+	general := models.NewError(models.GeneralErrorCode, "No known platform detected")
+	diagnostics := models.Diagnostics{warning, general}
+
+	t.Log("markdown")
+	{
+		require.Equal(t, "- **warning [android] android-gradlew-not-found**\n"+
+			"  No <gradlew> found.\n"+
+			"  Use a wrapper.\n"+
+			"  - `app/build.gradle`\n"+
+			"  [More info](https://docs.gradle.org)\n"+
+			"- **error [general] general-error**\n"+
+			"  No known platform detected\n", RenderMarkdown(diagnostics))
+	}
+
+	t.Log("html")
+	{
+		require.Equal(t, "<ul>\n"+
+			`<li class="warning" data-code="android-gradlew-not-found"><b>warning [android] android-gradlew-not-found</b><br>No &lt;gradlew&gt; found.<br>Use a wrapper.<ul><li><code>app/build.gradle</code></li></ul><br><a href="https://docs.gradle.org">More info</a></li>`+"\n"+
+			`<li class="error" data-code="general-error"><b>error [general] general-error</b><br>No known platform detected</li>`+"\n"+
+			"</ul>\n", RenderHTML(diagnostics))
+		require.Equal(t, "", RenderHTML(models.Diagnostics{}))
+	}
+
+	t.Log("terminal")
+	{
+		rendered := RenderTerminal(diagnostics)
+		require.Contains(t, rendered, "warning [android] android-gradlew-not-found")
+		require.Contains(t, rendered, "  No <gradlew> found.\n  Use a wrapper.\n  - app/build.gradle\n  More info: https://docs.gradle.org\n")
+		require.Contains(t, rendered, "  No known platform detected\n")
+	}
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-io/go-utils/colorstring"
+)
+
+func explanationVerdict(explanation models.Explanation) string {
+	if explanation.Detected {
+		return "detected"
+	}
+	return "not detected"
+}
+
+func rejectionReason(rejection models.Rejection) string {
+	if rejection.Filter == "" {
+		return rejection.Reason
+	}
+	return fmt.Sprintf("%s (%s)", rejection.Reason, rejection.Filter)
+}
+
+// RenderExplanationsTerminal renders the explanations as colored plain text, for printing to the terminal.
+func RenderExplanationsTerminal(explanations []models.Explanation) string {
+	var buffer bytes.Buffer
+	for _, explanation := range explanations {
+		header := fmt.Sprintf("%s: %s", explanation.Scanner, explanationVerdict(explanation))
+		if explanation.Detected {
+			header = colorstring.Green(header)
+		} else {
+			header = colorstring.Yellow(header)
+		}
+
+		buffer.WriteString(header + "\n")
+		if explanation.LookedFor != "" {
+			buffer.WriteString("  looked for: " + explanation.LookedFor + "\n")
+		}
+		if len(explanation.Candidates) > 0 {
+			buffer.WriteString("  candidates:\n")
+			for _, pth := range explanation.Candidates {
+				buffer.WriteString("  - " + pth + "\n")
+			}
+		}
+		if len(explanation.Rejections) > 0 {
+			buffer.WriteString("  rejected:\n")
+			for _, rejection := range explanation.Rejections {
+				buffer.WriteString(fmt.Sprintf("  - %s: %s\n", rejection.Path, rejectionReason(rejection)))
+			}
+		}
+		buffer.WriteString("  reason: " + explanation.Reason + "\n")
+	}
+	return buffer.String()
+}
+
+// RenderExplanationsMarkdown renders the explanations as a Markdown list.
+func RenderExplanationsMarkdown(explanations []models.Explanation) string {
+	var buffer bytes.Buffer
+	for _, explanation := range explanations {
+		buffer.WriteString(fmt.Sprintf("- **%s**: %s, %s\n", explanation.Scanner, explanationVerdict(explanation), explanation.Reason))
+		if explanation.LookedFor != "" {
+			buffer.WriteString("  looked for: " + explanation.LookedFor + "\n")
+		}
+		for _, pth := range explanation.Candidates {
+			buffer.WriteString("  - `" + pth + "`\n")
+		}
+		for _, rejection := range explanation.Rejections {
+			buffer.WriteString(fmt.Sprintf("  - ~~`%s`~~ %s\n", rejection.Path, rejectionReason(rejection)))
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderExplanations(t *testing.T) {
+	explanations := []models.Explanation{
+		{
+			Scanner:    "ios",
+			LookedFor:  "Xcode projects with iphoneos SDK",
+			Candidates: []string{"Pods/Pods.xcodeproj", "macos.xcodeproj"},
+			Rejections: []models.Rejection{
+				{Path: "Pods/Pods.xcodeproj", Filter: "ForbidPodsDirComponentFilter", Reason: "inside the Pods directory"},
+				{Path: "macos.xcodeproj", Reason: "no iphoneos SDK in the build configurations"},
+			},
+			Reason: "no relevant Xcode ios project found",
+		},
+		{Scanner: "android", Detected: true, Reason: "platform detected"},
+	}
+
+	t.Log("markdown")
+	{
+		require.Equal(t, "- **ios**: not detected, no relevant Xcode ios project found\n"+
+			"  looked for: Xcode projects with iphoneos SDK\n"+
+			"  - `Pods/Pods.xcodeproj`\n"+
+			"  - `macos.xcodeproj`\n"+
+			"  - ~~`Pods/Pods.xcodeproj`~~ inside the Pods directory (ForbidPodsDirComponentFilter)\n"+
+			"  - ~~`macos.xcodeproj`~~ no iphoneos SDK in the build configurations\n"+
+			"- **android**: detected, platform detected\n", RenderExplanationsMarkdown(explanations))
+	}
+
+	t.Log("terminal")
+	{
+		rendered := RenderExplanationsTerminal(explanations)
+		require.Contains(t, rendered, "ios: not detected")
+		require.Contains(t, rendered, "  looked for: Xcode projects with iphoneos SDK\n  candidates:\n  - Pods/Pods.xcodeproj\n  - macos.xcodeproj\n")
+		require.Contains(t, rendered, "  rejected:\n  - Pods/Pods.xcodeproj: inside the Pods directory (ForbidPodsDirComponentFilter)\n")
+		require.Contains(t, rendered, "  reason: no relevant Xcode ios project found\n")
+		require.Contains(t, rendered, "android: detected")
+	}
+}
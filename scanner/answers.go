@@ -0,0 +1,219 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+)
+
+// ProjectAnswers are the answers to the options of a detected platform, given either by option env key or as a path through the option tree.
+type ProjectAnswers struct {
+	// Platform is the selected platform, it can be omitted if a single platform is detected.
+	Platform string `json:"platform,omitempty" yaml:"platform,omitempty"`
+	// Values are the selected option values by option env key (like BITRISE_PROJECT_PATH: ios/Sample.xcodeproj),
+	// the options with a single value do not need an answer.
+	Values map[string]string `json:"values,omitempty" yaml:"values,omitempty"`
+	// Path lists the selected value of every option, from the platform's first option down to the config.
+	Path []string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// Answers are the answers of the option questions, used to generate the bitrise.yml without a prompt.
+// A single project is answered inline, multiple projects (see: MergeConfigs) are listed under the projects key.
+type Answers struct {
+	ProjectAnswers `yaml:",inline"`
+	Projects       []ProjectAnswers `json:"projects,omitempty" yaml:"projects,omitempty"`
+}
+
+// ReadAnswers reads the answers file (YAML or JSON).
+func ReadAnswers(pth string) (Answers, error) {
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return Answers{}, fmt.Errorf("failed to read answers file (%s), error: %s", pth, err)
+	}
+
+	var answers Answers
+	if err := yaml.Unmarshal(content, &answers); err != nil {
+		return Answers{}, fmt.Errorf("failed to parse answers file (%s), error: %s", pth, err)
+	}
+
+	if answers.hasProjectAnswers() && len(answers.Projects) > 0 {
+		return Answers{}, errors.New("answers file should either answer a single project or list the projects, not both")
+	}
+	return answers, nil
+}
+
+func (answers Answers) hasProjectAnswers() bool {
+	return answers.Platform != "" || len(answers.Values) > 0 || len(answers.Path) > 0
+}
+
+// projects returns the answers of every answered project.
+func (answers Answers) projects() []ProjectAnswers {
+	if len(answers.Projects) > 0 {
+		return answers.Projects
+	}
+	return []ProjectAnswers{answers.ProjectAnswers}
+}
+
+func quotedValues(values []string) string {
+	quoted := []string{}
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// optionName returns the option's title and env key, to identify the option in the errors.
+func optionName(option models.OptionModel) string {
+	if option.EnvKey == "" || option.EnvKey == "_" {
+		return option.Title
+	}
+	return fmt.Sprintf("%s (%s)", option.Title, option.EnvKey)
+}
+
+// AnswerOptions selects the config and the app envs of the options, based on the answers,
+// like AskForOptions does based on the user's input.
+func AnswerOptions(options models.OptionModel, answers ProjectAnswers) (string, []envmanModels.EnvironmentItemModel, error) {
+	if len(answers.Values) > 0 && len(answers.Path) > 0 {
+		return "", nil, errors.New("answer the options either by values or by path, not both")
+	}
+
+	configPth := ""
+	appEnvs := []envmanModels.EnvironmentItemModel{}
+	usedValues := map[string]bool{}
+	depth := 0
+
+	var walkDepth func(option models.OptionModel) error
+
+	walkDepth = func(option models.OptionModel) error {
+		if option.Config != "" {
+			// last option selected, config got
+			configPth = option.Config
+			return nil
+		}
+
+		optionValues := option.GetValues()
+		isFreeValue := len(optionValues) == 1 && optionValues[0] == "_"
+
+		selectedValue, answered := "", false
+		if len(answers.Path) > 0 {
+			if depth < len(answers.Path) {
+				selectedValue, answered = answers.Path[depth], true
+			}
+		} else if value, ok := answers.Values[option.EnvKey]; ok {
+			selectedValue, answered = value, true
+			usedValues[option.EnvKey] = true
+		}
+		depth++
+
+		if !answered {
+			if len(optionValues) != 1 || isFreeValue {
+				return fmt.Errorf("no answer for option: %s, available values: %s", optionName(option), quotedValues(optionValues))
+			}
+			// auto select the only one value
+			selectedValue = optionValues[0]
+		} else if isFreeValue {
+			if selectedValue == "" {
+				return fmt.Errorf("empty answer for option: %s", optionName(option))
+			}
+		} else if _, ok := option.ChildOptionMap[selectedValue]; !ok {
+			return fmt.Errorf("answer (%s) does not match any value of option: %s, available values: %s", selectedValue, optionName(option), quotedValues(optionValues))
+		}
+
+		if option.EnvKey != "" && option.EnvKey != "_" {
+			appEnvs = append(appEnvs, envmanModels.EnvironmentItemModel{
+				option.EnvKey: selectedValue,
+			})
+		}
+
+		nestedOptions := option.ChildOptionMap[selectedValue]
+		if isFreeValue {
+			nestedOptions = option.ChildOptionMap["_"]
+		}
+		if nestedOptions == nil {
+			return nil
+		}
+		return walkDepth(*nestedOptions)
+	}
+
+	if err := walkDepth(options); err != nil {
+		return "", nil, err
+	}
+
+	if len(answers.Path) > depth {
+		return "", nil, fmt.Errorf("too many values in the answer path, the options are answered by the first %d: %s", depth, quotedValues(answers.Path[depth:]))
+	}
+
+	unusedEnvKeys := []string{}
+	for envKey := range answers.Values {
+		if !usedValues[envKey] {
+			unusedEnvKeys = append(unusedEnvKeys, envKey)
+		}
+	}
+	if len(unusedEnvKeys) > 0 {
+		sort.Strings(unusedEnvKeys)
+		return "", nil, fmt.Errorf("answers do not match any option of the selected branch: %s", strings.Join(unusedEnvKeys, ", "))
+	}
+
+	if configPth == "" {
+		return "", nil, errors.New("no config selected")
+	}
+
+	return configPth, appEnvs, nil
+}
+
+// answerProjectSelection selects the platform and the config of a project, based on the answers.
+func answerProjectSelection(scanResult models.ScanResultModel, answers ProjectAnswers) (ProjectSelection, error) {
+	platforms := scanResult.RankedPlatforms()
+
+	platform := answers.Platform
+	if len(platforms) == 0 {
+		return ProjectSelection{}, errors.New("no platform detected")
+	} else if platform == "" {
+		if len(platforms) > 1 {
+			return ProjectSelection{}, fmt.Errorf("no platform answered, detected platforms: %s", strings.Join(platforms, ", "))
+		}
+		platform = platforms[0]
+	}
+
+	options, ok := scanResult.PlatformOptionMap[platform]
+	if !ok {
+		return ProjectSelection{}, fmt.Errorf("platform (%s) not detected, detected platforms: %s", platform, strings.Join(platforms, ", "))
+	}
+
+	configPth, appEnvs, err := AnswerOptions(options, answers)
+	if err != nil {
+		return ProjectSelection{}, fmt.Errorf("failed to answer the %s options, error: %s", platform, err)
+	}
+
+	return ProjectSelection{
+		Platform: platform,
+		Config:   configPth,
+		AppEnvs:  appEnvs,
+	}, nil
+}
+
+// AnswerConfig returns the config of the scan result selected by the answers, like AskForConfig does based on the user's input.
+// If the answers list multiple projects, their configs are merged into a single multi-workflow config (see: MergeConfigs).
+func AnswerConfig(scanResult models.ScanResultModel, answers Answers) (bitriseModels.BitriseDataModel, error) {
+	selections := []ProjectSelection{}
+	for _, projectAnswers := range answers.projects() {
+		selection, err := answerProjectSelection(scanResult, projectAnswers)
+		if err != nil {
+			return bitriseModels.BitriseDataModel{}, err
+		}
+		selections = append(selections, selection)
+	}
+
+	if len(answers.Projects) > 0 {
+		return MergeConfigs(scanResult, selections)
+	}
+	return selectedConfig(scanResult, selections[0])
+}
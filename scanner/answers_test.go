@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func testIOSOptions() models.OptionModel {
+	projectPathOption := models.NewOption("Project (or Workspace) path", "BITRISE_PROJECT_PATH")
+
+	for _, projectPath := range []string{"ios/Sample.xcodeproj", "ios/Sample.xcworkspace"} {
+		schemeOption := models.NewOption("Scheme name", "BITRISE_SCHEME")
+		projectPathOption.AddOption(projectPath, schemeOption)
+
+		exportMethodOption := models.NewOption("ipa export method", "BITRISE_EXPORT_METHOD")
+		schemeOption.AddOption("Sample", exportMethodOption)
+
+		for _, exportMethod := range []string{"app-store", "development"} {
+			exportMethodOption.AddConfig(exportMethod, models.NewConfigOption("ios-config"))
+		}
+	}
+
+	return *projectPathOption
+}
+
+func TestAnswerOptions(t *testing.T) {
+	options := testIOSOptions()
+
+	t.Log("answers by env key, the single value options are selected automatically")
+	{
+		configPth, appEnvs, err := AnswerOptions(options, ProjectAnswers{Values: map[string]string{
+			"BITRISE_PROJECT_PATH":  "ios/Sample.xcworkspace",
+			"BITRISE_EXPORT_METHOD": "development",
+		}})
+		require.NoError(t, err)
+		require.Equal(t, "ios-config", configPth)
+		require.Equal(t, []envmanModels.EnvironmentItemModel{
+			{"BITRISE_PROJECT_PATH": "ios/Sample.xcworkspace"},
+			{"BITRISE_SCHEME": "Sample"},
+			{"BITRISE_EXPORT_METHOD": "development"},
+		}, appEnvs)
+	}
+
+	t.Log("answers by path")
+	{
+		configPth, appEnvs, err := AnswerOptions(options, ProjectAnswers{Path: []string{"ios/Sample.xcodeproj", "Sample", "app-store"}})
+		require.NoError(t, err)
+		require.Equal(t, "ios-config", configPth)
+		require.Equal(t, []envmanModels.EnvironmentItemModel{
+			{"BITRISE_PROJECT_PATH": "ios/Sample.xcodeproj"},
+			{"BITRISE_SCHEME": "Sample"},
+			{"BITRISE_EXPORT_METHOD": "app-store"},
+		}, appEnvs)
+	}
+
+	t.Log("free value option")
+	{
+		option := models.NewOption("Gradlew file path", "GRADLEW_PATH")
+		option.AddConfig("_", models.NewConfigOption("android-config"))
+
+		configPth, appEnvs, err := AnswerOptions(*option, ProjectAnswers{Values: map[string]string{"GRADLEW_PATH": "./gradlew"}})
+		require.NoError(t, err)
+		require.Equal(t, "android-config", configPth)
+		require.Equal(t, []envmanModels.EnvironmentItemModel{{"GRADLEW_PATH": "./gradlew"}}, appEnvs)
+
+		_, _, err = AnswerOptions(*option, ProjectAnswers{})
+		require.EqualError(t, err, `no answer for option: Gradlew file path (GRADLEW_PATH), available values: "_"`)
+	}
+
+	t.Log("invalid answers")
+	{
+		_, _, err := AnswerOptions(options, ProjectAnswers{Values: map[string]string{
+			"BITRISE_PROJECT_PATH":  "ios/Missing.xcodeproj",
+			"BITRISE_EXPORT_METHOD": "development",
+		}})
+		require.EqualError(t, err, `answer (ios/Missing.xcodeproj) does not match any value of option: Project (or Workspace) path (BITRISE_PROJECT_PATH), available values: "ios/Sample.xcodeproj", "ios/Sample.xcworkspace"`)
+
+		_, _, err = AnswerOptions(options, ProjectAnswers{Values: map[string]string{"BITRISE_PROJECT_PATH": "ios/Sample.xcodeproj"}})
+		require.EqualError(t, err, `no answer for option: ipa export method (BITRISE_EXPORT_METHOD), available values: "app-store", "development"`)
+
+		_, _, err = AnswerOptions(options, ProjectAnswers{Values: map[string]string{
+			"BITRISE_PROJECT_PATH":  "ios/Sample.xcodeproj",
+			"BITRISE_EXPORT_METHOD": "development",
+			"BITRISE_CONFIGURATION": "Release",
+		}})
+		require.EqualError(t, err, "answers do not match any option of the selected branch: BITRISE_CONFIGURATION")
+
+		_, _, err = AnswerOptions(options, ProjectAnswers{Path: []string{"ios/Sample.xcodeproj", "Sample", "app-store", "Release"}})
+		require.EqualError(t, err, `too many values in the answer path, the options are answered by the first 3: "Release"`)
+
+		_, _, err = AnswerOptions(options, ProjectAnswers{
+			Values: map[string]string{"BITRISE_PROJECT_PATH": "ios/Sample.xcodeproj"},
+			Path:   []string{"ios/Sample.xcodeproj"},
+		})
+		require.EqualError(t, err, "answer the options either by values or by path, not both")
+	}
+}
+
+func TestAnswerConfig(t *testing.T) {
+	scanResult := models.ScanResultModel{
+		PlatformOptionMap: map[string]models.OptionModel{
+			"ios": testIOSOptions(),
+		},
+		PlatformConfigMapMap: map[string]models.BitriseConfigMap{
+			"ios": {"ios-config": testIOSConfig},
+		},
+	}
+
+	t.Log("single platform, the platform can be omitted")
+	{
+		config, err := AnswerConfig(scanResult, Answers{ProjectAnswers: ProjectAnswers{Path: []string{"ios/Sample.xcodeproj", "Sample", "app-store"}}})
+		require.NoError(t, err)
+		require.Equal(t, []envmanModels.EnvironmentItemModel{
+			{"BITRISE_EXPORT_METHOD": "development"},
+			{"BITRISE_PROJECT_PATH": "ios/Sample.xcodeproj"},
+			{"BITRISE_SCHEME": "Sample"},
+			{"BITRISE_EXPORT_METHOD": "app-store"},
+		}, config.App.Environments)
+	}
+
+	t.Log("platform not detected")
+	{
+		_, err := AnswerConfig(scanResult, Answers{ProjectAnswers: ProjectAnswers{Platform: "android"}})
+		require.EqualError(t, err, "platform (android) not detected, detected platforms: ios")
+	}
+
+	t.Log("multiple projects")
+	{
+		config, err := AnswerConfig(scanResult, Answers{Projects: []ProjectAnswers{
+			{Platform: "ios", Path: []string{"ios/Sample.xcodeproj", "Sample", "app-store"}},
+			{Platform: "ios", Path: []string{"ios/Sample.xcworkspace", "Sample", "development"}},
+		}})
+		require.NoError(t, err)
+		require.Equal(t, "ios", config.ProjectType)
+		require.Equal(t, []string{"ios-primary", "ios-2-primary"}, config.Workflows["primary"].AfterRun)
+	}
+}
+
+func TestReadAnswers(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__answers__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	t.Log("single project")
+	{
+		pth := filepath.Join(tmpDir, "answers.yml")
+		require.NoError(t, fileutil.WriteStringToFile(pth, `platform: ios
+values:
+  BITRISE_PROJECT_PATH: ios/Sample.xcodeproj
+`))
+
+		answers, err := ReadAnswers(pth)
+		require.NoError(t, err)
+		require.Equal(t, "ios", answers.Platform)
+		require.Equal(t, map[string]string{"BITRISE_PROJECT_PATH": "ios/Sample.xcodeproj"}, answers.Values)
+	}
+
+	t.Log("json projects")
+	{
+		pth := filepath.Join(tmpDir, "answers.json")
+		require.NoError(t, fileutil.WriteStringToFile(pth, `{"projects": [{"platform": "android", "path": ["./gradlew"]}]}`))
+
+		answers, err := ReadAnswers(pth)
+		require.NoError(t, err)
+		require.Equal(t, []ProjectAnswers{{Platform: "android", Path: []string{"./gradlew"}}}, answers.Projects)
+	}
+
+	t.Log("both a single project and projects")
+	{
+		pth := filepath.Join(tmpDir, "invalid.yml")
+		require.NoError(t, fileutil.WriteStringToFile(pth, `platform: ios
+projects:
+- platform: android
+`))
+
+		_, err := ReadAnswers(pth)
+		require.EqualError(t, err, "answers file should either answer a single project or list the projects, not both")
+	}
+}
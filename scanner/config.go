@@ -1,143 +1,654 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/bitrise-core/bitrise-init/events"
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/bitrise-core/bitrise-init/utility"
 	"github.com/bitrise-io/go-utils/colorstring"
-	"github.com/bitrise-io/go-utils/log"
 	"github.com/bitrise-io/go-utils/pathutil"
-	"github.com/bitrise-io/go-utils/sliceutil"
 )
 
-// Config ...
-func Config(searchDir string) models.ScanResultModel {
-	result := models.ScanResultModel{}
+// scannerOutput holds the outputs of a single scanner run.
+type scannerOutput struct {
+	detected bool
 
-	//
-	// Setup
-	currentDir, err := os.Getwd()
+	explanation models.Explanation
+	confidence  models.Confidence
+	options     models.OptionModel
+	hasOptions  bool
+	configs     models.BitriseConfigMap
+	// claimedPaths are the paths of the detected projects, claimsPaths is set if the scanner claims paths (see: scanners.PathClaimer)
+	claimedPaths []string
+	claimsPaths  bool
+	// warnings and errors hold the diagnostics, stored in the legacy PlatformWarningsMap and PlatformErrorsMap
+	warnings    models.Diagnostics
+	hasWarnings bool
+	errors      models.Diagnostics
+
+	// duration is the run time of the scanner, stopped is set if the scanner's context was done by the time it finished
+	duration time.Duration
+	stopped  bool
+
+	log *logger.BufferedLogger
+}
+
+// DefaultScannerTimeout is the default time budget of a single scanner run.
+const DefaultScannerTimeout = 10 * time.Minute
+
+// Diagnostic codes of the scan engine.
+const (
+	ScannerFailedCode          = "scanner-failed"
+	AnalyzerFailedCode         = "analyzer-failed"
+	ConfigGenerationFailedCode = "config-generation-failed"
+	ScannerTimedOutCode        = "scanner-timed-out"
+	ScannerCanceledCode        = "scanner-canceled"
+	ArchiveEntrySkippedCode    = "archive-entry-skipped"
+	ConfigAugmentFailedCode    = "config-augment-failed"
+)
+
+// runScanner runs the detection, option and config generation of the given scanner.
+// The scanner's log is collected in the returned output.
+// If the ctx is done, the scanner run stops after the current step, the output of the stopped run should be dropped.
+func runScanner(ctx context.Context, detector scanners.ScannerInterface, fileIndex *utility.FileIndex) scannerOutput {
+	output := scannerOutput{
+		log: logger.NewBufferedLogger(),
+	}
+	detector.SetLogger(output.log)
+
+	detectorWarnings := models.Diagnostics{}
+	detectorErrors := models.Diagnostics{}
+
+	output.log.Printft("+------------------------------------------------------------------------------+")
+	output.log.Printft("|                                                                              |")
+
+	detected, err := detector.DetectPlatform(ctx, fileIndex)
 	if err != nil {
-		result.AddError("general", fmt.Sprintf("Failed to expand current directory path, error: %s", err))
-		return result
+		output.log.Errorft("Scanner failed, error: %s", err)
+		detectorWarnings = append(detectorWarnings, models.DiagnosticFromError(err, ScannerFailedCode, models.SeverityError))
+		output.warnings = detectorWarnings
+		output.hasWarnings = true
+		detected = false
 	}
 
-	if searchDir == "" {
-		searchDir = currentDir
-	} else {
-		absScerach, err := pathutil.AbsPath(searchDir)
+	output.explanation = detector.Explanation()
+	output.explanation.Detected = detected
+	if err != nil {
+		output.explanation.Reason = fmt.Sprintf("scanner failed: %s", err)
+	}
+	if len(output.explanation.Candidates) > 0 {
+		events.Emit(ctx, events.Event{Type: events.FilesFound, Paths: output.explanation.Candidates})
+	}
+
+	if !detected || ctx.Err() != nil {
+		output.log.Printft("|                                                                              |")
+		output.log.Printft("+------------------------------------------------------------------------------+")
+		return output
+	}
+
+	output.confidence = detector.Confidence()
+	output.log.Printft("detection confidence: %d", output.confidence.Score)
+
+	options, projectWarnings, err := detector.Options(ctx)
+	detectorWarnings = append(detectorWarnings, projectWarnings...)
+
+	if err != nil {
+		output.log.Errorft("Analyzer failed, error: %s", err)
+		output.explanation.Reason = fmt.Sprintf("analyzer failed: %s", err)
+		detectorWarnings = append(detectorWarnings, models.DiagnosticFromError(err, AnalyzerFailedCode, models.SeverityError))
+		output.warnings = detectorWarnings
+		output.hasWarnings = true
+
+		output.log.Printft("|                                                                              |")
+		output.log.Printft("+------------------------------------------------------------------------------+")
+		return output
+	}
+
+	output.warnings = detectorWarnings
+	output.hasWarnings = true
+	output.options = options
+	output.hasOptions = true
+	emitOptions(ctx, &options)
+
+	if ctx.Err() != nil {
+		output.log.Printft("|                                                                              |")
+		output.log.Printft("+------------------------------------------------------------------------------+")
+		return output
+	}
+
+	// Generate configs
+	configs, err := detector.Configs(ctx)
+	if err != nil {
+		output.log.Errorft("Failed to generate config, error: %s", err)
+		detectorErrors = append(detectorErrors, models.DiagnosticFromError(err, ConfigGenerationFailedCode, models.SeverityError))
+		output.errors = detectorErrors
+
+		output.log.Printft("|                                                                              |")
+		output.log.Printft("+------------------------------------------------------------------------------+")
+		return output
+	}
+
+	output.configs = configs
+	output.detected = true
+	if claimer, ok := detector.(scanners.PathClaimer); ok {
+		output.claimedPaths = claimer.ClaimedPaths()
+		output.claimsPaths = true
+	}
+
+	output.log.Printft("|                                                                              |")
+	output.log.Printft("+------------------------------------------------------------------------------+")
+
+	return output
+}
+
+// emitOptions emits an option_added event for every option (question) of the option tree.
+func emitOptions(ctx context.Context, option *models.OptionModel) {
+	if option == nil || len(option.ChildOptionMap) == 0 {
+		return
+	}
+
+	values := option.GetValues()
+	events.Emit(ctx, events.Event{
+		Type:   events.OptionAdded,
+		Option: &events.Option{Title: option.Title, EnvKey: option.EnvKey, Values: values},
+	})
+
+	for _, value := range values {
+		emitOptions(ctx, option.ChildOptionMap[value])
+	}
+}
+
+// runScannerWithEvents runs the scanner (see: runScanner), measures its run time
+// and emits its start, diagnostics and finish, unless the scanner's context is done by the time it finished.
+func runScannerWithEvents(ctx context.Context, detector scanners.ScannerInterface, fileIndex *utility.FileIndex) scannerOutput {
+	started := time.Now()
+	events.Emit(ctx, events.Event{Type: events.ScannerStarted})
+
+	output := runScanner(ctx, detector, fileIndex)
+	output.duration = time.Since(started)
+	output.stopped = ctx.Err() != nil
+	if output.stopped {
+		// the scan reports the stopped scanners
+		return output
+	}
+
+	for _, diagnostic := range append(append(models.Diagnostics{}, output.warnings...), output.errors...) {
+		diagnostic := diagnostic
+		events.Emit(ctx, events.Event{Type: events.DiagnosticRaised, Diagnostic: &diagnostic})
+	}
+	events.Emit(ctx, events.Event{
+		Type:       events.ScannerFinished,
+		DurationMs: events.Milliseconds(output.duration),
+		Detected:   output.detected,
+	})
+
+	return output
+}
+
+// completedExplanation returns the scanner's explanation with the scanner name and a default reason, if the scanner gave none.
+func completedExplanation(scannerName string, explanation models.Explanation) models.Explanation {
+	explanation.Scanner = scannerName
+	if explanation.Reason == "" {
+		explanation.Reason = "platform not detected"
+		if explanation.Detected {
+			explanation.Reason = "platform detected"
+		}
+	}
+	return explanation
+}
+
+// scannerTimeoutError returns the error message of a scanner run, stopped by the given (done) context.
+func scannerTimeoutError(scannerCtx context.Context, timeout time.Duration) string {
+	if scannerCtx.Err() == context.DeadlineExceeded {
+		if timeout > 0 {
+			return fmt.Sprintf("scanner timed out after %s", timeout)
+		}
+		return "scanner timed out"
+	}
+	return "scanner canceled"
+}
+
+// ScanOptions configures a scan.
+type ScanOptions struct {
+	// ScannerNames selects the scanners to run, by default every scanner runs.
+	ScannerNames []string
+	// SkippedScannerNames lists the scanners to skip.
+	SkippedScannerNames []string
+	// PluginExecutablePths and PluginDirs define the external scanner plugins (see: scanners.NewPluginScanners).
+	PluginExecutablePths []string
+	PluginDirs           []string
+	// ScannerTimeout is the time budget of a single scanner run, zero or negative value disables it.
+	ScannerTimeout time.Duration
+	// LogWriter receives the scan log, the log is discarded if it is nil.
+	LogWriter io.Writer
+	// Explain collects the explanations of the scanners' verdicts into the result.
+	Explain bool
+	// Events receives the progress events of the scan, the result includes the scanner timings if it is set.
+	Events events.Emitter
+}
+
+// NewScanners creates the scanners of a single scan: the built-in and the plugin scanners, selected by the options.
+func (opts ScanOptions) NewScanners() ([]scanners.ScannerInterface, error) {
+	projectScanners := scanners.NewScanners()
+
+	if len(opts.PluginExecutablePths) > 0 || len(opts.PluginDirs) > 0 {
+		pluginScanners, err := scanners.NewPluginScanners(opts.PluginExecutablePths, opts.PluginDirs)
 		if err != nil {
-			result.AddError("general", fmt.Sprintf("Failed to expand path (%s), error: %s", searchDir, err))
-			return result
+			return nil, fmt.Errorf("failed to load scanner plugins, error: %s", err)
 		}
-		searchDir = absScerach
+		projectScanners = append(projectScanners, pluginScanners...)
+	}
+
+	selectedScanners, err := scanners.SelectScanners(projectScanners, opts.ScannerNames, opts.SkippedScannerNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select scanners, error: %s", err)
+	}
+
+	return selectedScanners, nil
+}
+
+// eventContext returns a copy of the ctx, which carries the event emitter of the options (if any).
+func (opts ScanOptions) eventContext(ctx context.Context) context.Context {
+	if opts.Events == nil {
+		return ctx
+	}
+	return events.WithEmitter(ctx, opts.Events)
+}
+
+func (opts ScanOptions) logWriter() io.Writer {
+	if opts.LogWriter == nil {
+		return ioutil.Discard
+	}
+	return opts.LogWriter
+}
+
+// Scan scans the project in the rootDir, with scanners created for this scan.
+// The rootDir can also be a zip, tar, tar.gz archive or a git bundle, which is scanned in place (see: ScanArchive).
+// It does not change the process working directory and does not share state with other scans,
+// so it is safe to run multiple scans concurrently.
+// The returned error reports invalid options, the scan issues are reported in the result.
+func Scan(ctx context.Context, rootDir string, opts ScanOptions) (models.ScanResultModel, error) {
+	projectScanners, err := opts.NewScanners()
+	if err != nil {
+		return models.ScanResultModel{}, err
+	}
+
+	if info, err := os.Stat(rootDir); err == nil && !info.IsDir() {
+		return configArchive(ctx, rootDir, projectScanners, opts), nil
+	}
+
+	return Config(ctx, rootDir, projectScanners, opts), nil
+}
+
+// ScanArchive scans the project in the zip, tar, tar.gz archive or git bundle, without extracting it to the disk.
+// The paths in the result are relative to the archive root,
+// the skipped (unsafe or unsupported) archive entries are reported as warnings.
+func ScanArchive(ctx context.Context, archivePth string, opts ScanOptions) (models.ScanResultModel, error) {
+	projectScanners, err := opts.NewScanners()
+	if err != nil {
+		return models.ScanResultModel{}, err
 	}
 
-	if searchDir != currentDir {
-		if err := os.Chdir(searchDir); err != nil {
-			result.AddError("general", fmt.Sprintf("Failed to change dir, to (%s), error: %s", searchDir, err))
-			return result
+	return configArchive(ctx, archivePth, projectScanners, opts), nil
+}
+
+func configArchive(ctx context.Context, archivePth string, projectScanners []scanners.ScannerInterface, opts ScanOptions) models.ScanResultModel {
+	archive, err := utility.OpenArchive(opts.eventContext(ctx), archivePth)
+	if err != nil {
+		result := models.ScanResultModel{}
+		result.AddError("general", fmt.Sprintf("Failed to open archive, error: %s", err))
+		return result
+	}
+
+	result := ConfigFS(ctx, archive, projectScanners, opts)
+	if len(archive.SkippedEntries) > 0 {
+		result.AddDiagnostic(models.NewWarning(ArchiveEntrySkippedCode, "Archive entries pointing outside of the archive root, not supported or over the size limits are skipped", archive.SkippedEntries...))
+	}
+	return result
+}
+
+// ScanFS scans the project in the given file system, like an in-memory tree or an archive (see: Scan).
+func ScanFS(ctx context.Context, fs filesystem.FileSystem, opts ScanOptions) (models.ScanResultModel, error) {
+	projectScanners, err := opts.NewScanners()
+	if err != nil {
+		return models.ScanResultModel{}, err
+	}
+
+	return ConfigFS(ctx, fs, projectScanners, opts), nil
+}
+
+// Config runs the given scanners on the searchDir, the scanner selection and plugin options are not used.
+// The scanners store the state of the scan, they should not be used by multiple scans (see: Scan).
+// Every scanner run is limited by the ctx and the opts.ScannerTimeout,
+// the scanners, which are not finished in time, are recorded in the returned (partial) scan result.
+func Config(ctx context.Context, searchDir string, projectScanners []scanners.ScannerInterface, opts ScanOptions) models.ScanResultModel {
+	if searchDir == "" {
+		searchDir = "./"
+	}
+	absSearchDir, err := pathutil.AbsPath(searchDir)
+	if err != nil {
+		result := models.ScanResultModel{}
+		result.AddError("general", fmt.Sprintf("Failed to expand path (%s), error: %s", searchDir, err))
+		return result
+	}
+
+	fs, err := filesystem.NewOSFileSystem(absSearchDir)
+	if err != nil {
+		result := models.ScanResultModel{}
+		result.AddError("general", fmt.Sprintf("Failed to open (%s), error: %s", absSearchDir, err))
+		return result
+	}
+
+	return ConfigFS(ctx, fs, projectScanners, opts)
+}
+
+// ConfigFS runs the given scanners on the file system, see: Config.
+// The scanners read the project files through the fs, the paths in the result are relative to its root.
+func ConfigFS(ctx context.Context, fs filesystem.FileSystem, projectScanners []scanners.ScannerInterface, opts ScanOptions) models.ScanResultModel {
+	result := models.ScanResultModel{}
+	scannerTimeout := opts.ScannerTimeout
+	logWriter := opts.logWriter()
+	log := logger.NewWriterLogger(logWriter)
+
+	ctx = opts.eventContext(ctx)
+	scanStarted := time.Now()
+	events.Emit(ctx, events.Event{Type: events.ScanStarted})
+	defer func() {
+		events.Emit(ctx, events.Event{Type: events.ScanFinished, DurationMs: events.Milliseconds(time.Since(scanStarted))})
+	}()
+
+	//
+	// Setup
+	fileIndex, err := utility.NewFileIndexFS(fs, utility.DefaultIgnoreFileNames...)
+	if err != nil {
+		if rootDir, ok := filesystem.RootDir(fs); ok {
+			result.AddError("general", fmt.Sprintf("Failed to search for files in (%s), error: %s", rootDir, err))
+		} else {
+			result.AddError("general", fmt.Sprintf("Failed to search for files, error: %s", err))
 		}
-		defer func() {
-			if err := os.Chdir(currentDir); err != nil {
-				log.Warnft("Failed to change dir, to (%s), error: %s", searchDir, err)
-			}
-		}()
+		return result
+	}
+	events.Emit(ctx, events.Event{Type: events.FilesIndexed, Count: len(fileIndex.Paths())})
+
+	plan, err := scanners.NewPlan(projectScanners)
+	if err != nil {
+		result.AddError("general", fmt.Sprintf("Failed to resolve scanner relationships, error: %s", err))
+		return result
 	}
 	// ---
 
 	//
 	// Scan
-	projectScanners := scanners.ActiveScanners
-
 	projectTypeErrorMap := map[string]models.Errors{}
 	projectTypeWarningMap := map[string]models.Warnings{}
 	projectTypeOptionMap := map[string]models.OptionModel{}
 	projectTypeConfigMap := map[string]models.BitriseConfigMap{}
+	projectTypeConfidenceMap := map[string]models.Confidence{}
 
-	excludedScannerNames := []string{}
+	scannerNames := []string{}
+	timedOutScannerNames := []string{}
+	diagnostics := models.Diagnostics{}
+	explanations := []models.Explanation{}
+	timings := []models.ScannerTiming{}
+	// detectedScannerMap holds the scanners, which detected the project and their outputs are kept
+	detectedScannerMap := map[string]bool{}
 
 	log.Infoft(colorstring.Blue("Running scanners:"))
-	fmt.Println()
+	fmt.Fprintln(logWriter)
 
-	for _, detector := range projectScanners {
-		detectorName := detector.Name()
-		detectorWarnings := []string{}
-		detectorErrors := []string{}
+	// Every scanner runs in its own goroutine, the outputs are processed in the order of the plan,
+	// as soon as the given scanner and all of the previous scanners finished (or timed out).
+	// The overridden scanners (see: scanners.Plan.OverriddenBy) start once their overriding scanners are processed,
+	// so the paths claimed by the overriding scanners (see: scanners.PathClaimer) can be hidden from them.
+	// The scanner names are read upfront, the running scanners should not be accessed.
+	outputChannels := make([]chan scannerOutput, len(plan.Scanners))
+	scannerContexts := make([]context.Context, len(plan.Scanners))
+	scannerStarts := make([]time.Time, len(plan.Scanners))
+	scannerCancels := []context.CancelFunc{}
+	defer func() {
+		for _, cancel := range scannerCancels {
+			cancel()
+		}
+	}()
+	detectorNames := make([]string, len(plan.Scanners))
+	planIndexMap := map[string]int{}
+	for i, detector := range plan.Scanners {
+		detectorNames[i] = detector.Name()
+		planIndexMap[detectorNames[i]] = i
+		outputChannels[i] = make(chan scannerOutput, 1)
+	}
+
+	startScanner := func(i int, fileIndex *utility.FileIndex) {
+		var scannerCtx context.Context
+		var cancel context.CancelFunc
+		if scannerTimeout > 0 {
+			scannerCtx, cancel = context.WithTimeout(ctx, scannerTimeout)
+		} else {
+			scannerCtx, cancel = context.WithCancel(ctx)
+		}
+		scannerCancels = append(scannerCancels, cancel)
+		scannerCtx = events.WithScanner(scannerCtx, detectorNames[i])
+		scannerContexts[i] = scannerCtx
+		scannerStarts[i] = time.Now()
+
+		go func(detector scanners.ScannerInterface, outputChannel chan scannerOutput) {
+			outputChannel <- runScannerWithEvents(scannerCtx, detector, fileIndex)
+		}(plan.Scanners[i], outputChannels[i])
+	}
+
+	// claimedPathsMap holds the claimed paths of the detected, path claiming scanners
+	claimedPathsMap := map[string][]string{}
+	// resolvedScanners marks the scanners, which are started or skipped
+	resolvedScanners := make([]bool, len(plan.Scanners))
+	// overridingScannersMap holds the detected, overriding scanners, which do not claim paths, their overridden scanners are skipped
+	overridingScannersMap := map[int][]string{}
+	// claimingScannersMap and hiddenPathsMap hold the detected, path claiming overriding scanners and their claimed paths
+	claimingScannersMap := map[int][]string{}
+	hiddenPathsMap := map[int][]string{}
+
+	// resolveScanners starts (or skips) the scanners, whose overriding scanners are processed,
+	// the scanners before the given plan index are processed.
+	resolveScanners := func(processed int) {
+		for i := processed; i < len(plan.Scanners); i++ {
+			if resolvedScanners[i] {
+				continue
+			}
+
+			ready := true
+			overridingScannerNames, claimingScannerNames, hiddenPaths := []string{}, []string{}, []string{}
+			for _, name := range plan.OverriddenBy(detectorNames[i]) {
+				if planIndexMap[name] >= processed {
+					ready = false
+					break
+				}
+				if !detectedScannerMap[name] {
+					continue
+				}
+
+				if claimedPaths, ok := claimedPathsMap[name]; ok {
+					claimingScannerNames = append(claimingScannerNames, name)
+					hiddenPaths = append(hiddenPaths, claimedPaths...)
+				} else {
+					overridingScannerNames = append(overridingScannerNames, name)
+				}
+			}
+			if !ready {
+				continue
+			}
+
+			resolvedScanners[i] = true
+			if len(overridingScannerNames) > 0 {
+				overridingScannersMap[i] = overridingScannerNames
+				continue
+			}
+
+			scannerFileIndex := fileIndex
+			if len(hiddenPaths) > 0 {
+				claimingScannersMap[i] = claimingScannerNames
+				hiddenPathsMap[i] = hiddenPaths
+				scannerFileIndex = fileIndex.Without(hiddenPaths...)
+			}
+			startScanner(i, scannerFileIndex)
+		}
+	}
+
+	for i, detectorName := range detectorNames {
+		// The plan ensures, that the overriding scanners are processed first.
+		resolveScanners(i)
 
 		log.Infoft("Scanner: %s", colorstring.Blue(detectorName))
 
-		if sliceutil.IsStringInSlice(detectorName, excludedScannerNames) {
-			log.Warnft("scanner is marked as excluded, skipping...")
-			fmt.Println()
+		// the overridden scanner is not started, if its overriding scanner does not claim paths
+		if overridingScannerNames := overridingScannersMap[i]; len(overridingScannerNames) > 0 {
+			log.Warnft("scanner is overridden by: %s, skipping...", strings.Join(overridingScannerNames, ", "))
+			reason := fmt.Sprintf("overridden by: %s", strings.Join(overridingScannerNames, ", "))
+			explanations = append(explanations, models.Explanation{Scanner: detectorName, Reason: reason})
+			events.Emit(ctx, events.Event{Type: events.ScannerSkipped, Scanner: detectorName, Message: reason})
+			fmt.Fprintln(logWriter)
 			continue
 		}
 
-		log.Printft("+------------------------------------------------------------------------------+")
-		log.Printft("|                                                                              |")
-
-		detected, err := detector.DetectPlatform(searchDir)
-		if err != nil {
-			log.Errorft("Scanner failed, error: %s", err)
-			detectorWarnings = append(detectorWarnings, err.Error())
-			projectTypeWarningMap[detectorName] = detectorWarnings
-			detected = false
+		if hiddenPaths := hiddenPathsMap[i]; len(hiddenPaths) > 0 {
+			log.Printft("skipping the paths claimed by: %s", strings.Join(claimingScannersMap[i], ", "))
+			for _, pth := range hiddenPaths {
+				log.Printft("- %s", pth)
+			}
 		}
 
-		if !detected {
-			log.Printft("|                                                                              |")
-			log.Printft("+------------------------------------------------------------------------------+")
-			fmt.Println()
-			continue
+		var output scannerOutput
+		timedOut := false
+		select {
+		case output = <-outputChannels[i]:
+		case <-scannerContexts[i].Done():
+			// prefer the output, if the scanner finished right at the deadline
+			select {
+			case output = <-outputChannels[i]:
+			default:
+				timedOut = true
+			}
 		}
 
-		options, projectWarnings, err := detector.Options()
-		detectorWarnings = append(detectorWarnings, projectWarnings...)
+		if timedOut {
+			errorMessage := scannerTimeoutError(scannerContexts[i], scannerTimeout)
+			log.Errorft("%s", errorMessage)
+			fmt.Fprintln(logWriter)
 
-		if err != nil {
-			log.Errorft("Analyzer failed, error: %s", err)
-			detectorWarnings = append(detectorWarnings, err.Error())
-			projectTypeWarningMap[detectorName] = detectorWarnings
+			code := ScannerCanceledCode
+			if scannerContexts[i].Err() == context.DeadlineExceeded {
+				code = ScannerTimedOutCode
+			}
+			diagnostic := models.NewError(code, errorMessage)
+			diagnostic.Scanner = detectorName
+
+			timedOutScannerNames = append(timedOutScannerNames, detectorName)
+			projectTypeErrorMap[detectorName] = models.Errors{diagnostic.LegacyString()}
+			diagnostics = append(diagnostics, diagnostic)
+			explanations = append(explanations, models.Explanation{Scanner: detectorName, Reason: errorMessage})
 
-			log.Printft("|                                                                              |")
-			log.Printft("+------------------------------------------------------------------------------+")
-			fmt.Println()
+			waited := time.Since(scannerStarts[i])
+			timings = append(timings, models.ScannerTiming{Scanner: detectorName, DurationMs: events.Milliseconds(waited), Stopped: true})
+			events.Emit(ctx, events.Event{Type: events.DiagnosticRaised, Scanner: detectorName, Diagnostic: &diagnostic})
+			events.Emit(ctx, events.Event{Type: events.ScannerFinished, Scanner: detectorName, DurationMs: events.Milliseconds(waited), Message: errorMessage})
 			continue
 		}
 
-		projectTypeWarningMap[detectorName] = detectorWarnings
-		projectTypeOptionMap[detectorName] = options
+		if output.stopped {
+			// the scanner finished right at the deadline, its output is kept, but its finish is not reported yet
+			events.Emit(ctx, events.Event{Type: events.ScannerFinished, Scanner: detectorName, DurationMs: events.Milliseconds(output.duration), Detected: output.detected})
+		}
 
-		// Generate configs
-		configs, err := detector.Configs()
-		if err != nil {
-			log.Errorft("Failed to generate config, error: %s", err)
-			detectorErrors = append(detectorErrors, err.Error())
-			projectTypeErrorMap[detectorName] = detectorErrors
-			continue
+		if err := output.log.Flush(logWriter); err != nil {
+			log.Errorft("Failed to print scanner log, error: %s", err)
 		}
 
-		projectTypeConfigMap[detectorName] = configs
+		scannerNames = append(scannerNames, detectorName)
+		timings = append(timings, models.ScannerTiming{Scanner: detectorName, DurationMs: events.Milliseconds(output.duration)})
+		explanation := completedExplanation(detectorName, output.explanation)
+		explanation.ClaimedPaths = hiddenPathsMap[i]
+		explanations = append(explanations, explanation)
 
-		log.Printft("|                                                                              |")
-		log.Printft("+------------------------------------------------------------------------------+")
+		if output.hasWarnings {
+			projectTypeWarningMap[detectorName] = output.warnings.LegacyStrings()
+		}
+		if output.hasOptions {
+			projectTypeOptionMap[detectorName] = output.options
+			projectTypeConfidenceMap[detectorName] = output.confidence
+		}
+		if len(output.errors) > 0 {
+			projectTypeErrorMap[detectorName] = output.errors.LegacyStrings()
+		}
+		for _, diagnostic := range append(output.warnings, output.errors...) {
+			if diagnostic.Scanner == "" {
+				diagnostic.Scanner = detectorName
+			}
+			diagnostics = append(diagnostics, diagnostic)
+		}
+		if output.detected {
+			projectTypeConfigMap[detectorName] = output.configs
+			detectedScannerMap[detectorName] = true
+			if output.claimsPaths {
+				claimedPathsMap[detectorName] = output.claimedPaths
+			}
+		}
+
+		// the augmented scanners are processed first (see: scanners.NewPlan), their configs are complemented by the augmenting scanner
+		if augmenter, ok := plan.Scanners[i].(scanners.ConfigAugmenter); ok {
+			for _, augmentedName := range plan.Scanners[i].Relationships().Augments {
+				configs, ok := projectTypeConfigMap[augmentedName]
+				if !ok {
+					continue
+				}
+
+				augmentedConfigs, err := augmenter.AugmentConfigs(augmentedName, configs)
+				if err != nil {
+					errorMessage := fmt.Sprintf("Failed to augment the %s configs by the %s scanner, error: %s", augmentedName, detectorName, err)
+					log.Errorft("%s", errorMessage)
+
+					// reported under the augmented scanner, the augmenting scanner may not be part of the result
+					diagnostic := models.NewError(ConfigAugmentFailedCode, errorMessage)
+					diagnostic.Scanner = augmentedName
 
-		exludedScanners := detector.ExcludedScannerNames()
-		if len(exludedScanners) > 0 {
-			log.Warnft("Scanner will exclude scanners: %v", exludedScanners)
-			excludedScannerNames = append(excludedScannerNames, exludedScanners...)
+					projectTypeErrorMap[augmentedName] = append(projectTypeErrorMap[augmentedName], diagnostic.LegacyString())
+					diagnostics = append(diagnostics, diagnostic)
+					events.Emit(ctx, events.Event{Type: events.DiagnosticRaised, Scanner: augmentedName, Diagnostic: &diagnostic})
+					continue
+				}
+
+				projectTypeConfigMap[augmentedName] = augmentedConfigs
+			}
 		}
 
-		fmt.Println()
+		fmt.Fprintln(logWriter)
 	}
 	// ---
 
-	return models.ScanResultModel{
-		PlatformOptionMap:    projectTypeOptionMap,
-		PlatformConfigMapMap: projectTypeConfigMap,
-		PlatformWarningsMap:  projectTypeWarningMap,
-		PlatformErrorsMap:    projectTypeErrorMap,
+	result = models.ScanResultModel{
+		PlatformOptionMap:     projectTypeOptionMap,
+		PlatformConfigMapMap:  projectTypeConfigMap,
+		PlatformWarningsMap:   projectTypeWarningMap,
+		PlatformErrorsMap:     projectTypeErrorMap,
+		ScannerNames:          scannerNames,
+		TimedOutScannerNames:  timedOutScannerNames,
+		Diagnostics:           diagnostics,
+		PlatformConfidenceMap: projectTypeConfidenceMap,
+	}
+	result.Ranking = result.RankedPlatforms()
+	if opts.Explain {
+		result.Explanations = explanations
 	}
+	if opts.Events != nil {
+		result.Timings = timings
+	}
+
+	return result
 }
@@ -0,0 +1,531 @@
+package scanner
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/events"
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners"
+	"github.com/bitrise-core/bitrise-init/utility"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeScanner struct {
+	name      string
+	detected  bool
+	detectErr error
+	score     int
+	// block blocks the detection until it gets closed, the scanner does not respect its context
+	block chan struct{}
+	// cancel is called by the option generation, to stop the scan right after it
+	cancel        context.CancelFunc
+	relationships models.ScannerRelationships
+	// paths are the paths seen by the detection
+	paths  []string
+	logger logger.Logger
+}
+
+func (scanner *fakeScanner) Name() string {
+	if scanner.name != "" {
+		return scanner.name
+	}
+	return "fake"
+}
+
+func (scanner *fakeScanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
+func (scanner *fakeScanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	scanner.logger.Printft("searching in: %s", fileIndex.Root())
+	scanner.paths = fileIndex.Paths()
+	if scanner.block != nil {
+		<-scanner.block
+	}
+	return scanner.detected, scanner.detectErr
+}
+
+func (scanner *fakeScanner) Explanation() models.Explanation {
+	return models.NewExplanation("fake files", "build.fake")
+}
+
+func (scanner *fakeScanner) Confidence() models.Confidence {
+	return models.NewConfidence(models.NewEvidence(scanner.score, "fake evidence"))
+}
+
+func (scanner *fakeScanner) Relationships() models.ScannerRelationships {
+	return scanner.relationships
+}
+
+func (scanner *fakeScanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	if scanner.cancel != nil {
+		scanner.cancel()
+	}
+	option := models.NewOption("Title", "ENV_KEY")
+	option.AddConfig("value", models.NewConfigOption("fake-config"))
+	return *option, models.Diagnostics{models.NewWarning("fake-warning", "warning", "build.fake")}, nil
+}
+
+func (scanner *fakeScanner) DefaultOptions() models.OptionModel {
+	return models.OptionModel{}
+}
+
+func (scanner *fakeScanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
+	return models.BitriseConfigMap{"fake-config": "config"}, nil
+}
+
+func (scanner *fakeScanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	return models.BitriseConfigMap{}, nil
+}
+
+// fakeAugmenter is a fakeScanner, which fails to augment the configs
+type fakeAugmenter struct {
+	*fakeScanner
+}
+
+func (augmenter fakeAugmenter) AugmentConfigs(scannerName string, configs models.BitriseConfigMap) (models.BitriseConfigMap, error) {
+	return models.BitriseConfigMap{}, errors.New("failed")
+}
+
+func TestRunScanner(t *testing.T) {
+	fileIndex, err := utility.NewFileIndex("./")
+	require.NoError(t, err)
+
+	t.Log("detected")
+	{
+		output := runScanner(context.Background(), &fakeScanner{detected: true}, fileIndex)
+		require.Equal(t, true, output.detected)
+		require.Equal(t, models.Diagnostics{models.NewWarning("fake-warning", "warning", "build.fake")}, output.warnings)
+		require.Equal(t, models.BitriseConfigMap{"fake-config": "config"}, output.configs)
+		require.True(t, strings.Contains(output.log.String(), "searching in: "+fileIndex.Root()))
+	}
+
+	t.Log("not detected")
+	{
+		output := runScanner(context.Background(), &fakeScanner{detected: false}, fileIndex)
+		require.Equal(t, false, output.detected)
+		require.Equal(t, false, output.hasWarnings)
+	}
+
+	t.Log("detect failed")
+	{
+		output := runScanner(context.Background(), &fakeScanner{detectErr: errors.New("failed")}, fileIndex)
+		require.Equal(t, false, output.detected)
+		require.Equal(t, models.Diagnostics{models.NewError(ScannerFailedCode, "failed")}, output.warnings)
+	}
+
+	t.Log("canceled after the option generation closes the log box")
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		output := runScanner(ctx, &fakeScanner{detected: true, cancel: cancel}, fileIndex)
+		require.Equal(t, false, output.detected)
+		require.True(t, strings.HasSuffix(strings.TrimSpace(output.log.String()), "+------------------------------------------------------------------------------+"))
+	}
+}
+
+func TestConfigRanking(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__config_ranking_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	result := Config(context.Background(), tmpDir, []scanners.ScannerInterface{
+		&fakeScanner{name: "weak", detected: true, score: 20},
+		&fakeScanner{name: "strong", detected: true, score: 150},
+		&fakeScanner{name: "missing", detected: false, score: 100},
+		&fakeScanner{name: "also-weak", detected: true, score: 20},
+	}, ScanOptions{})
+
+	require.Equal(t, []string{"strong", "also-weak", "weak"}, result.Ranking)
+	require.Equal(t, 100, result.PlatformConfidenceMap["strong"].Score)
+	require.Equal(t, []models.Evidence{models.NewEvidence(150, "fake evidence")}, result.PlatformConfidenceMap["strong"].Evidence)
+	_, ok := result.PlatformConfidenceMap["missing"]
+	require.False(t, ok)
+}
+
+func TestConfigExplain(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__config_explain_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	newScanners := func() []scanners.ScannerInterface {
+		return []scanners.ScannerInterface{
+			&fakeScanner{name: "detected", detected: true},
+			&fakeScanner{name: "missing", detected: false},
+			&fakeScanner{name: "failing", detectErr: errors.New("broken project file")},
+		}
+	}
+
+	t.Log("explanations are collected on request")
+	{
+		result := Config(context.Background(), tmpDir, newScanners(), ScanOptions{Explain: true})
+		require.Equal(t, []models.Explanation{
+			{Scanner: "detected", Detected: true, LookedFor: "fake files", Candidates: []string{"build.fake"}, Reason: "platform detected"},
+			{Scanner: "missing", Detected: false, LookedFor: "fake files", Candidates: []string{"build.fake"}, Reason: "platform not detected"},
+			{Scanner: "failing", Detected: false, LookedFor: "fake files", Candidates: []string{"build.fake"}, Reason: "scanner failed: broken project file"},
+		}, result.Explanations)
+	}
+
+	t.Log("explanations are not collected by default")
+	{
+		result := Config(context.Background(), tmpDir, newScanners(), ScanOptions{})
+		require.Equal(t, 0, len(result.Explanations))
+	}
+}
+
+func TestConfigConflicts(t *testing.T) {
+	t.Log("the claimed paths are hidden from the conflicting scanners")
+	{
+		memoryFS, err := filesystem.NewMemoryFileSystem(map[string]string{
+			"cordovaapp/config.xml":                     `<widget id="io.x" version="1.0.0" xmlns="http://www.w3.org/ns/widgets" xmlns:cdv="http://cordova.apache.org/ns/1.0"></widget>`,
+			"cordovaapp/package.json":                   `{"dependencies":{}}`,
+			"cordovaapp/platforms/android/build.gradle": "",
+			"cordovaapp/platforms/android/gradlew":      "",
+			"androidapp/build.gradle":                   "",
+			"androidapp/gradlew":                        "",
+		})
+		require.NoError(t, err)
+
+		result, err := ScanFS(context.Background(), memoryFS, ScanOptions{ScannerNames: []string{"cordova", "android"}, Explain: true})
+		require.NoError(t, err)
+		t.Logf("%+v %+v %+v", result.Ranking, result.Explanations, result.PlatformWarningsMap)
+		require.Equal(t, 2, len(result.Ranking))
+
+		gradlewOption := result.PlatformOptionMap["android"]
+		require.Equal(t, []string{"androidapp/gradlew"}, gradlewOption.GetValues())
+
+		explanation := result.Explanations[1]
+		require.Equal(t, "android", explanation.Scanner)
+		require.Equal(t, true, explanation.Detected)
+		require.Equal(t, []string{"androidapp/build.gradle"}, explanation.Candidates)
+		require.Equal(t, []string{"cordovaapp/platforms"}, explanation.ClaimedPaths)
+	}
+
+	t.Log("the whole output is overridden by a scanner without claimed paths")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__config_conflicts_test__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		overridden := &fakeScanner{name: "overridden", detected: true}
+		result := Config(context.Background(), tmpDir, []scanners.ScannerInterface{
+			overridden,
+			&fakeScanner{name: "overriding", detected: true, relationships: models.ScannerRelationships{
+				Precedes:      []string{"overridden"},
+				ConflictsWith: []string{"overridden"},
+			}},
+		}, ScanOptions{Explain: true})
+
+		require.Equal(t, []string{"overriding"}, result.Ranking)
+		require.Equal(t, models.Explanation{Scanner: "overridden", Reason: "overridden by: overriding"}, result.Explanations[1])
+		require.Equal(t, 0, len(overridden.paths))
+	}
+}
+
+func TestConfigAugment(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__config_augment_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	t.Log("the augment error of an undetected augmenter is reported under the augmented scanner")
+	{
+		result := Config(context.Background(), tmpDir, []scanners.ScannerInterface{
+			&fakeScanner{name: "augmented", detected: true},
+			fakeAugmenter{&fakeScanner{name: "augmenter", detected: false, relationships: models.ScannerRelationships{
+				Augments: []string{"augmented"},
+			}}},
+		}, ScanOptions{})
+
+		message := "Failed to augment the augmented configs by the augmenter scanner, error: failed"
+		require.Equal(t, models.Errors{message}, result.PlatformErrorsMap["augmented"])
+		require.Equal(t, 0, len(result.PlatformErrorsMap["augmenter"]))
+		require.Equal(t, models.BitriseConfigMap{"fake-config": "config"}, result.PlatformConfigMapMap["augmented"])
+
+		augmentFailed := models.NewError(ConfigAugmentFailedCode, message)
+		augmentFailed.Scanner = "augmented"
+		require.Equal(t, augmentFailed, result.Diagnostics[len(result.Diagnostics)-1])
+	}
+}
+
+func TestConfigTimeout(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__config_timeout_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	block := make(chan struct{})
+	defer close(block)
+
+	t.Log("scanner timeout")
+	{
+		result := Config(context.Background(), tmpDir, []scanners.ScannerInterface{
+			&fakeScanner{name: "hanging", detected: true, block: block},
+			&fakeScanner{name: "finishing", detected: true},
+		}, ScanOptions{ScannerTimeout: 100 * time.Millisecond})
+
+		require.Equal(t, []string{"hanging"}, result.TimedOutScannerNames)
+		require.Equal(t, models.Errors{"scanner timed out after 100ms"}, result.PlatformErrorsMap["hanging"])
+		require.Equal(t, []string{"finishing"}, result.ScannerNames)
+		require.Equal(t, models.BitriseConfigMap{"fake-config": "config"}, result.PlatformConfigMapMap["finishing"])
+		require.Equal(t, models.Warnings{"warning"}, result.PlatformWarningsMap["finishing"])
+
+		timedOut := models.NewError(ScannerTimedOutCode, "scanner timed out after 100ms")
+		timedOut.Scanner = "hanging"
+		warning := models.NewWarning("fake-warning", "warning", "build.fake")
+		warning.Scanner = "finishing"
+		require.Equal(t, models.Diagnostics{timedOut, warning}, result.Diagnostics)
+	}
+
+	t.Log("canceled scan")
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := Config(ctx, tmpDir, []scanners.ScannerInterface{
+			&fakeScanner{name: "hanging", detected: true, block: block},
+		}, ScanOptions{})
+
+		require.Equal(t, []string{"hanging"}, result.TimedOutScannerNames)
+		require.Equal(t, models.Errors{"scanner canceled"}, result.PlatformErrorsMap["hanging"])
+		require.Equal(t, ScannerCanceledCode, result.Diagnostics[0].Code)
+	}
+}
+
+func TestConfigEvents(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__config_events_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	block := make(chan struct{})
+	defer close(block)
+
+	recorder := events.NewRecorder()
+	result := Config(context.Background(), tmpDir, []scanners.ScannerInterface{
+		&fakeScanner{name: "detected", detected: true},
+		&fakeScanner{name: "missing", detected: false},
+		&fakeScanner{name: "hanging", detected: true, block: block},
+	}, ScanOptions{ScannerTimeout: 100 * time.Millisecond, Events: recorder})
+
+	// the scanners run concurrently, the events are grouped by scanner
+	scanEventTypes := []events.Type{}
+	scannerEventTypes := map[string][]events.Type{}
+	var option *events.Option
+	for _, event := range recorder.Events() {
+		if event.Scanner == "" {
+			scanEventTypes = append(scanEventTypes, event.Type)
+		} else {
+			scannerEventTypes[event.Scanner] = append(scannerEventTypes[event.Scanner], event.Type)
+		}
+		if event.Type == events.OptionAdded {
+			option = event.Option
+		}
+	}
+
+	require.Equal(t, []events.Type{events.ScanStarted, events.FilesIndexed, events.ScanFinished}, scanEventTypes)
+	require.Equal(t, []events.Type{events.ScannerStarted, events.FilesFound, events.OptionAdded, events.DiagnosticRaised, events.ScannerFinished}, scannerEventTypes["detected"])
+	require.Equal(t, []events.Type{events.ScannerStarted, events.FilesFound, events.ScannerFinished}, scannerEventTypes["missing"])
+	require.Equal(t, []events.Type{events.ScannerStarted, events.DiagnosticRaised, events.ScannerFinished}, scannerEventTypes["hanging"])
+	require.Equal(t, &events.Option{Title: "Title", EnvKey: "ENV_KEY", Values: []string{"value"}}, option)
+
+	t.Log("timings")
+	{
+		require.Equal(t, 3, len(result.Timings))
+		require.Equal(t, "detected", result.Timings[0].Scanner)
+		require.False(t, result.Timings[0].Stopped)
+		require.Equal(t, "hanging", result.Timings[2].Scanner)
+		require.True(t, result.Timings[2].Stopped)
+		require.True(t, result.Timings[2].DurationMs >= 100)
+
+		result := Config(context.Background(), tmpDir, []scanners.ScannerInterface{&fakeScanner{detected: true}}, ScanOptions{})
+		require.Equal(t, 0, len(result.Timings))
+	}
+}
+
+func TestScan(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__scan_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	projectDirs := []string{filepath.Join(tmpDir, "first"), filepath.Join(tmpDir, "second")}
+	moduleDirs := []string{"app", "mobile"}
+	for i, projectDir := range projectDirs {
+		for _, pth := range []string{filepath.Join(moduleDirs[i], "build.gradle"), filepath.Join(moduleDirs[i], "gradlew")} {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(projectDir, pth)), 0700))
+			require.NoError(t, fileutil.WriteStringToFile(filepath.Join(projectDir, pth), ""))
+		}
+	}
+
+	t.Log("concurrent scans")
+	{
+		currentDir, err := os.Getwd()
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		results := make([]models.ScanResultModel, len(projectDirs))
+		errs := make([]error, len(projectDirs))
+		for i, projectDir := range projectDirs {
+			wg.Add(1)
+			go func(i int, projectDir string) {
+				defer wg.Done()
+				results[i], errs[i] = Scan(context.Background(), projectDir, ScanOptions{ScannerNames: []string{"android"}})
+			}(i, projectDir)
+		}
+		wg.Wait()
+
+		for i, moduleDir := range moduleDirs {
+			require.NoError(t, errs[i])
+			gradlewOption := results[i].PlatformOptionMap["android"]
+			require.Equal(t, []string{moduleDir + "/gradlew"}, gradlewOption.GetValues())
+			require.Equal(t, []string{moduleDir + "/build.gradle"}, gradlewOption.ChildOptionMap[moduleDir+"/gradlew"].GetValues())
+		}
+
+		dir, err := os.Getwd()
+		require.NoError(t, err)
+		require.Equal(t, currentDir, dir)
+	}
+
+	t.Log("invalid options")
+	{
+		_, err := Scan(context.Background(), projectDirs[0], ScanOptions{ScannerNames: []string{"unknown"}})
+		require.EqualError(t, err, "failed to select scanners, error: unknown scanner (unknown), available scanners: ionic, cordova, react-native, flutter, unity, ios, macos, android, xamarin, fastlane, spm")
+	}
+}
+
+const testSolutionContent = `Microsoft Visual Studio Solution File, Format Version 12.00
+Global
+	GlobalSection(SolutionConfigurationPlatforms) = preSolution
+		Debug|iPhone = Debug|iPhone
+		Release|iPhone = Release|iPhone
+	EndGlobalSection
+EndGlobal
+`
+
+func TestScanFS(t *testing.T) {
+	files := map[string]string{
+		"app/build.gradle":        "",
+		"app/gradlew":             "",
+		"xamarin/Sample.sln":      testSolutionContent,
+		"ignored/build.gradle":    "",
+		".gitignore":              "ignored/",
+		"xamarin/packages.config": "",
+	}
+
+	memoryFS, err := filesystem.NewMemoryFileSystem(files)
+	require.NoError(t, err)
+
+	result, err := ScanFS(context.Background(), memoryFS, ScanOptions{ScannerNames: []string{"android", "xamarin"}})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(result.Diagnostics))
+
+	gradlewOption := result.PlatformOptionMap["android"]
+	require.Equal(t, []string{"app/gradlew"}, gradlewOption.GetValues())
+	require.Equal(t, []string{"app/build.gradle"}, gradlewOption.ChildOptionMap["app/gradlew"].GetValues())
+
+	solutionOption := result.PlatformOptionMap["xamarin"]
+	require.Equal(t, []string{"xamarin/Sample.sln"}, solutionOption.GetValues())
+	configurations := solutionOption.ChildOptionMap["xamarin/Sample.sln"].GetValues()
+	sort.Strings(configurations)
+	require.Equal(t, []string{"Debug", "Release"}, configurations)
+
+	t.Log("the same scan on the disk")
+	{
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__scan_fs_test__")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(tmpDir))
+		}()
+
+		for pth, content := range files {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, pth)), 0700))
+			require.NoError(t, fileutil.WriteStringToFile(filepath.Join(tmpDir, pth), content))
+		}
+
+		diskResult, err := Scan(context.Background(), tmpDir, ScanOptions{ScannerNames: []string{"android", "xamarin"}})
+		require.NoError(t, err)
+		require.Equal(t, diskResult, result)
+	}
+}
+
+func TestScanArchive(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__scan_archive_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	files := map[string]string{
+		"app/build.gradle": "",
+		"app/gradlew":      "",
+	}
+
+	archivePth := filepath.Join(tmpDir, "project.tar.gz")
+	archiveFile, err := os.Create(archivePth)
+	require.NoError(t, err)
+	gzipWriter := gzip.NewWriter(archiveFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range []string{"app/build.gradle", "app/gradlew", "../evil"} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644}))
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, archiveFile.Close())
+
+	result, err := Scan(context.Background(), archivePth, ScanOptions{ScannerNames: []string{"android"}})
+	require.NoError(t, err)
+
+	gradlewOption := result.PlatformOptionMap["android"]
+	require.Equal(t, []string{"app/gradlew"}, gradlewOption.GetValues())
+	require.Equal(t, []string{"app/build.gradle"}, gradlewOption.ChildOptionMap["app/gradlew"].GetValues())
+
+	require.Equal(t, 1, len(result.Diagnostics))
+	require.Equal(t, ArchiveEntrySkippedCode, result.Diagnostics[0].Code)
+	require.Equal(t, []string{"../evil"}, result.Diagnostics[0].Paths)
+
+	t.Log("the same scan on the in-memory files")
+	{
+		memoryFS, err := filesystem.NewMemoryFileSystem(files)
+		require.NoError(t, err)
+
+		memoryResult, err := ScanFS(context.Background(), memoryFS, ScanOptions{ScannerNames: []string{"android"}})
+		require.NoError(t, err)
+		require.Equal(t, memoryResult.PlatformOptionMap, result.PlatformOptionMap)
+		require.Equal(t, memoryResult.PlatformConfigMapMap, result.PlatformConfigMapMap)
+	}
+
+	t.Log("not an archive")
+	{
+		pth := filepath.Join(tmpDir, "build.gradle")
+		require.NoError(t, fileutil.WriteStringToFile(pth, ""))
+
+		result, err := ScanArchive(context.Background(), pth, ScanOptions{ScannerNames: []string{"android"}})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(result.Diagnostics))
+		require.Equal(t, models.SeverityError, result.Diagnostics[0].Severity)
+	}
+}
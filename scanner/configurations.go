@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+)
+
+// SelectedOption is an option (question) on the path to a configuration, with the value leading to the configuration.
+type SelectedOption struct {
+	Title  string `json:"title" yaml:"title"`
+	EnvKey string `json:"env_key,omitempty" yaml:"env_key,omitempty"`
+	Value  string `json:"value,omitempty" yaml:"value,omitempty"`
+	// Input marks an option without predefined values (the _ placeholder value), its value has to be provided by the user.
+	Input bool `json:"input,omitempty" yaml:"input,omitempty"`
+}
+
+// Configuration is a leaf of a platform's option tree: a concrete, buildable configuration.
+type Configuration struct {
+	Platform string `json:"platform" yaml:"platform"`
+	// Title is the human readable name of the configuration, like: ios: ios/Sample.xcodeproj, Sample, app-store.
+	Title string `json:"title" yaml:"title"`
+	// Config is the key of the configuration's bitrise config in the scan result.
+	Config string `json:"config" yaml:"config"`
+	// Options lists the options leading to the configuration, from the platform's first option.
+	Options []SelectedOption `json:"options,omitempty" yaml:"options,omitempty"`
+	// AppEnvs are the app envs of the options in order, the options waiting for an input are left out.
+	AppEnvs []envmanModels.EnvironmentItemModel `json:"app_envs,omitempty" yaml:"app_envs,omitempty"`
+	// BitriseYML is the materialized bitrise config of the configuration, if requested (see: Configurations).
+	BitriseYML string `json:"bitrise_yml,omitempty" yaml:"bitrise_yml,omitempty"`
+}
+
+func configurationTitle(platform string, options []SelectedOption) string {
+	values := []string{}
+	for _, option := range options {
+		if option.Input {
+			values = append(values, fmt.Sprintf("<%s>", option.Title))
+		} else {
+			values = append(values, option.Value)
+		}
+	}
+	if len(values) == 0 {
+		return platform
+	}
+	return fmt.Sprintf("%s: %s", platform, strings.Join(values, ", "))
+}
+
+// FlattenOptions expands the platform's option tree into the list of its leaf configurations, in option value order.
+// The branches without a config are left out.
+func FlattenOptions(platform string, options models.OptionModel) []Configuration {
+	configurations := []Configuration{}
+
+	var walk func(option models.OptionModel, selectedOptions []SelectedOption)
+	walk = func(option models.OptionModel, selectedOptions []SelectedOption) {
+		if option.Config != "" {
+			configuration := Configuration{
+				Platform: platform,
+				Title:    configurationTitle(platform, selectedOptions),
+				Config:   option.Config,
+				Options:  selectedOptions,
+			}
+			for _, selectedOption := range selectedOptions {
+				if selectedOption.Input || selectedOption.EnvKey == "" || selectedOption.EnvKey == "_" {
+					continue
+				}
+				configuration.AppEnvs = append(configuration.AppEnvs, envmanModels.EnvironmentItemModel{selectedOption.EnvKey: selectedOption.Value})
+			}
+			configurations = append(configurations, configuration)
+			return
+		}
+
+		for _, value := range option.GetValues() {
+			childOption := option.ChildOptionMap[value]
+			if childOption == nil {
+				continue
+			}
+
+			selectedOption := SelectedOption{Title: option.Title, EnvKey: option.EnvKey, Value: value}
+			if value == "_" {
+				selectedOption = SelectedOption{Title: option.Title, EnvKey: option.EnvKey, Input: true}
+			}
+
+			// every branch gets its own copy of the path
+			path := append(append([]SelectedOption{}, selectedOptions...), selectedOption)
+			walk(*childOption, path)
+		}
+	}
+
+	walk(options, nil)
+
+	return configurations
+}
+
+// Configurations returns the leaf configurations of every platform in the scan result, the best match platform comes first.
+// If materialize is set, the configurations carry their bitrise config, extended with the app envs.
+func Configurations(scanResult models.ScanResultModel, materialize bool) ([]Configuration, error) {
+	configurations := []Configuration{}
+	for _, platform := range scanResult.RankedPlatforms() {
+		platformConfigurations := FlattenOptions(platform, scanResult.PlatformOptionMap[platform])
+
+		if materialize {
+			for i, configuration := range platformConfigurations {
+				config, err := selectedConfig(scanResult, ProjectSelection{
+					Platform: platform,
+					Config:   configuration.Config,
+					AppEnvs:  configuration.AppEnvs,
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				content, err := yaml.Marshal(config)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal config, error: %s", err)
+				}
+				platformConfigurations[i].BitriseYML = string(content)
+			}
+		}
+
+		configurations = append(configurations, platformConfigurations...)
+	}
+	return configurations, nil
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenOptions(t *testing.T) {
+	t.Log("every leaf config is listed in value order")
+	{
+		configurations := FlattenOptions("ios", testIOSOptions())
+		require.Equal(t, 4, len(configurations))
+
+		titles := []string{}
+		for _, configuration := range configurations {
+			titles = append(titles, configuration.Title)
+		}
+		require.Equal(t, []string{
+			"ios: ios/Sample.xcodeproj, Sample, app-store",
+			"ios: ios/Sample.xcodeproj, Sample, development",
+			"ios: ios/Sample.xcworkspace, Sample, app-store",
+			"ios: ios/Sample.xcworkspace, Sample, development",
+		}, titles)
+
+		require.Equal(t, Configuration{
+			Platform: "ios",
+			Title:    "ios: ios/Sample.xcworkspace, Sample, development",
+			Config:   "ios-config",
+			Options: []SelectedOption{
+				{Title: "Project (or Workspace) path", EnvKey: "BITRISE_PROJECT_PATH", Value: "ios/Sample.xcworkspace"},
+				{Title: "Scheme name", EnvKey: "BITRISE_SCHEME", Value: "Sample"},
+				{Title: "ipa export method", EnvKey: "BITRISE_EXPORT_METHOD", Value: "development"},
+			},
+			AppEnvs: []envmanModels.EnvironmentItemModel{
+				{"BITRISE_PROJECT_PATH": "ios/Sample.xcworkspace"},
+				{"BITRISE_SCHEME": "Sample"},
+				{"BITRISE_EXPORT_METHOD": "development"},
+			},
+		}, configurations[3])
+	}
+
+	t.Log("placeholder values wait for an input, the branches without config are left out")
+	{
+		option := models.NewOption("Gradlew file path", "GRADLEW_PATH")
+		taskOption := models.NewOption("Gradle task to run", "GRADLE_TASK")
+		option.AddOption("_", taskOption)
+		taskOption.AddConfig("assembleDebug", models.NewConfigOption("android-config"))
+		taskOption.AddOption("lint", nil)
+
+		configurations := FlattenOptions("android", *option)
+		require.Equal(t, []Configuration{
+			{
+				Platform: "android",
+				Title:    "android: <Gradlew file path>, assembleDebug",
+				Config:   "android-config",
+				Options: []SelectedOption{
+					{Title: "Gradlew file path", EnvKey: "GRADLEW_PATH", Input: true},
+					{Title: "Gradle task to run", EnvKey: "GRADLE_TASK", Value: "assembleDebug"},
+				},
+				AppEnvs: []envmanModels.EnvironmentItemModel{{"GRADLE_TASK": "assembleDebug"}},
+			},
+		}, configurations)
+	}
+}
+
+func TestConfigurations(t *testing.T) {
+	androidOption := models.NewOption("Gradlew file path", "GRADLEW_PATH")
+	androidOption.AddConfig("app/gradlew", models.NewConfigOption("android-config"))
+
+	scanResult := models.ScanResultModel{
+		PlatformOptionMap: map[string]models.OptionModel{
+			"android": *androidOption,
+			"ios":     testIOSOptions(),
+		},
+		PlatformConfigMapMap: map[string]models.BitriseConfigMap{
+			"android": {"android-config": testAndroidConfig},
+			"ios":     {"ios-config": testIOSConfig},
+		},
+		PlatformConfidenceMap: map[string]models.Confidence{
+			"ios": {Score: 80},
+		},
+	}
+
+	t.Log("best match platform first")
+	{
+		configurations, err := Configurations(scanResult, false)
+		require.NoError(t, err)
+		require.Equal(t, 5, len(configurations))
+		require.Equal(t, "ios", configurations[0].Platform)
+		require.Equal(t, "android", configurations[4].Platform)
+		require.Equal(t, "", configurations[4].BitriseYML)
+	}
+
+	t.Log("materialized bitrise config")
+	{
+		configurations, err := Configurations(scanResult, true)
+		require.NoError(t, err)
+		require.True(t, strings.Contains(configurations[4].BitriseYML, "- GRADLEW_PATH: app/gradlew"), configurations[4].BitriseYML)
+	}
+
+	t.Log("missing config")
+	{
+		scanResult.PlatformConfigMapMap = map[string]models.BitriseConfigMap{}
+		_, err := Configurations(scanResult, true)
+		require.EqualError(t, err, "config (ios-config) not found for platform: ios")
+	}
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// The golden fixtures are small synthetic projects, every fixture dir (testdata/golden/NAME) holds
+// the project to scan (project/) and the expected scan result (result.yml), including the scanner explanations.
+// Run the tests with -update-golden to rewrite the expected results after an intended scanner change:
+// go test ./scanner -run TestGoldenFixtures -update-golden
+var updateGolden = flag.Bool("update-golden", false, "rewrite the expected results of the golden fixtures")
+
+const (
+	goldenFixturesDir     = "testdata/golden"
+	goldenProjectDirName  = "project"
+	goldenResultFileName  = "result.yml"
+	goldenProjectDirToken = "$PROJECT_DIR"
+)
+
+// normalizedResult returns the scan result in YAML, the project dir is replaced with a placeholder,
+// so the result does not depend on the fixture's location.
+func normalizedResult(result models.ScanResultModel, projectDir string) (string, error) {
+	content, err := yaml.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	normalized := strings.Replace(string(content), projectDir, goldenProjectDirToken, -1)
+	return strings.Replace(normalized, filepath.ToSlash(projectDir), goldenProjectDirToken, -1), nil
+}
+
+func TestGoldenFixtures(t *testing.T) {
+	fixtureDirs, err := filepath.Glob(filepath.Join(goldenFixturesDir, "*"))
+	require.NoError(t, err)
+	require.NotEqual(t, 0, len(fixtureDirs))
+
+	for _, fixtureDir := range fixtureDirs {
+		fixtureDir := fixtureDir
+		t.Run(filepath.Base(fixtureDir), func(t *testing.T) {
+			projectDir, err := filepath.Abs(filepath.Join(fixtureDir, goldenProjectDirName))
+			require.NoError(t, err)
+
+			result, err := Scan(context.Background(), projectDir, ScanOptions{Explain: true})
+			require.NoError(t, err)
+
+			actual, err := normalizedResult(result, projectDir)
+			require.NoError(t, err)
+
+			resultPth := filepath.Join(fixtureDir, goldenResultFileName)
+			if *updateGolden {
+				require.NoError(t, fileutil.WriteStringToFile(resultPth, actual))
+				return
+			}
+
+			expected, err := fileutil.ReadStringFromFile(resultPth)
+			require.NoError(t, err, "run the tests with -update-golden to create the expected result")
+			require.Equal(t, expected, actual)
+		})
+	}
+}
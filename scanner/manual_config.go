@@ -7,9 +7,13 @@ import (
 	"github.com/bitrise-core/bitrise-init/scanners"
 )
 
-// ManualConfig ...
-func ManualConfig() (models.ScanResultModel, error) {
-	projectScanners := scanners.ActiveScanners
+// ManualConfig returns the default options and configs of the scanners, created by the opts.
+func ManualConfig(opts ScanOptions) (models.ScanResultModel, error) {
+	projectScanners, err := opts.NewScanners()
+	if err != nil {
+		return models.ScanResultModel{}, err
+	}
+
 	projectTypeOptionMap := map[string]models.OptionModel{}
 	projectTypeConfigMap := map[string]models.BitriseConfigMap{}
 
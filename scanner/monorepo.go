@@ -0,0 +1,233 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+)
+
+// ProjectSelection selects a project of a scan result: a leaf config of a platform's options, with the selected option values.
+type ProjectSelection struct {
+	// Name identifies the project in the merged config, the workflow ids and the env keys are derived from it.
+	// Defaults to the Platform.
+	Name string
+	// Platform is the name of the scanner, which detected the project.
+	Platform string
+	// Config is the name of the selected config in the platform's config map.
+	Config string
+	// AppEnvs are the envs of the selected option values, like BITRISE_PROJECT_PATH.
+	AppEnvs []envmanModels.EnvironmentItemModel
+}
+
+// otherProjectType is the project type of the merged configs, containing projects of different types.
+const otherProjectType = "other"
+
+var (
+	nonWorkflowIDCharRegexp = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+	nonEnvKeyCharRegexp     = regexp.MustCompile(`[^A-Z0-9_]+`)
+)
+
+// mergedProject holds a selected project's config and the ids derived from its name.
+type mergedProject struct {
+	name      string
+	envPrefix string
+	config    bitriseModels.BitriseDataModel
+}
+
+// triggeredWorkflow is a project workflow, triggered by a trigger map item.
+type triggeredWorkflow struct {
+	id         string
+	originalID string
+}
+
+// workflowID returns the id of the project's workflow in the merged config.
+func (project mergedProject) workflowID(workflowID string) string {
+	return project.name + "-" + workflowID
+}
+
+// projectNames returns the unique names of the selected projects, the repeated names get a numbered suffix (like: android-2).
+func projectNames(selections []ProjectSelection) []string {
+	names := make([]string, len(selections))
+	used := map[string]bool{}
+	for i, selection := range selections {
+		name := selection.Name
+		if name == "" {
+			name = selection.Platform
+		}
+		name = strings.Trim(nonWorkflowIDCharRegexp.ReplaceAllString(name, "-"), "-")
+		if name == "" {
+			name = "project"
+		}
+
+		uniqueName := name
+		for n := 2; used[uniqueName]; n++ {
+			uniqueName = fmt.Sprintf("%s-%d", name, n)
+		}
+		used[uniqueName] = true
+		names[i] = uniqueName
+	}
+	return names
+}
+
+// envPrefix returns the prefix of the project's namespaced env keys, like: ANDROID_2_ for the android-2 project.
+func envPrefix(projectName string) string {
+	return strings.Trim(nonEnvKeyCharRegexp.ReplaceAllString(strings.ToUpper(projectName), "_"), "_") + "_"
+}
+
+// namespacedEnvs returns the envs with the prefixed keys, and the workflow envs, which map the original keys to the prefixed ones.
+func namespacedEnvs(prefix string, envs []envmanModels.EnvironmentItemModel) ([]envmanModels.EnvironmentItemModel, []envmanModels.EnvironmentItemModel, error) {
+	appEnvs := []envmanModels.EnvironmentItemModel{}
+	workflowEnvs := []envmanModels.EnvironmentItemModel{}
+	for _, env := range envs {
+		key, _, err := env.GetKeyValuePair()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid env, error: %s", err)
+		}
+
+		namespacedEnv := envmanModels.EnvironmentItemModel{}
+		for k, v := range env {
+			if k == key {
+				k = prefix + key
+			}
+			namespacedEnv[k] = v
+		}
+
+		appEnvs = append(appEnvs, namespacedEnv)
+		workflowEnvs = append(workflowEnvs, envmanModels.EnvironmentItemModel{key: "$" + prefix + key})
+	}
+	return appEnvs, workflowEnvs, nil
+}
+
+func renameWorkflowIDs(project mergedProject, workflowIDs []string) []string {
+	if workflowIDs == nil {
+		return nil
+	}
+	renamed := make([]string, len(workflowIDs))
+	for i, workflowID := range workflowIDs {
+		renamed[i] = project.workflowID(workflowID)
+	}
+	return renamed
+}
+
+// MergeConfigs builds a single multi-workflow config of the selected projects, which may come from different scanners.
+// Every workflow of a project is renamed to <project name>-<workflow id>.
+// The app envs of a project are namespaced (BITRISE_PROJECT_PATH of the ios project becomes IOS_BITRISE_PROJECT_PATH),
+// and the project's workflows map the namespaced envs back to the original keys, so the steps read their usual envs.
+// The trigger map routes to the project workflows, if multiple projects are triggered by the same event,
+// the trigger routes to a workflow, which runs the projects' workflows after each other.
+func MergeConfigs(scanResult models.ScanResultModel, selections []ProjectSelection) (bitriseModels.BitriseDataModel, error) {
+	if len(selections) == 0 {
+		return bitriseModels.BitriseDataModel{}, errors.New("no project selected")
+	}
+
+	names := projectNames(selections)
+	projects := make([]mergedProject, len(selections))
+	for i, selection := range selections {
+		config, err := selectedConfig(scanResult, selection)
+		if err != nil {
+			return bitriseModels.BitriseDataModel{}, err
+		}
+
+		projects[i] = mergedProject{
+			name:      names[i],
+			envPrefix: envPrefix(names[i]),
+			config:    config,
+		}
+	}
+
+	merged := bitriseModels.BitriseDataModel{
+		FormatVersion:        projects[0].config.FormatVersion,
+		DefaultStepLibSource: projects[0].config.DefaultStepLibSource,
+		ProjectType:          projects[0].config.ProjectType,
+		Workflows:            map[string]bitriseModels.WorkflowModel{},
+	}
+
+	// triggers holds the project workflows of every trigger (the trigger map item without the workflow id), in the order of appearance
+	triggers := []bitriseModels.TriggerMapItemModel{}
+	triggeredWorkflows := map[bitriseModels.TriggerMapItemModel][]triggeredWorkflow{}
+
+	for _, project := range projects {
+		config := project.config
+		if config.ProjectType != merged.ProjectType {
+			merged.ProjectType = otherProjectType
+		}
+		if merged.DefaultStepLibSource == "" {
+			merged.DefaultStepLibSource = config.DefaultStepLibSource
+		}
+
+		appEnvs, workflowEnvs, err := namespacedEnvs(project.envPrefix, config.App.Environments)
+		if err != nil {
+			return bitriseModels.BitriseDataModel{}, fmt.Errorf("failed to namespace the envs of %s, error: %s", project.name, err)
+		}
+		merged.App.Environments = append(merged.App.Environments, appEnvs...)
+
+		for workflowID, workflow := range config.Workflows {
+			workflow.BeforeRun = renameWorkflowIDs(project, workflow.BeforeRun)
+			workflow.AfterRun = renameWorkflowIDs(project, workflow.AfterRun)
+			workflow.Environments = append(append([]envmanModels.EnvironmentItemModel{}, workflowEnvs...), workflow.Environments...)
+
+			merged.Workflows[project.workflowID(workflowID)] = workflow
+		}
+
+		for _, item := range config.TriggerMap {
+			workflowID := item.WorkflowID
+			item.WorkflowID = ""
+			if _, ok := triggeredWorkflows[item]; !ok {
+				triggers = append(triggers, item)
+			}
+			triggeredWorkflows[item] = append(triggeredWorkflows[item], triggeredWorkflow{
+				id:         project.workflowID(workflowID),
+				originalID: workflowID,
+			})
+		}
+	}
+
+	for _, item := range triggers {
+		workflows := triggeredWorkflows[item]
+		if len(workflows) == 1 {
+			item.WorkflowID = workflows[0].id
+		} else {
+			item.WorkflowID = addRunnerWorkflow(merged.Workflows, workflows)
+		}
+		merged.TriggerMap = append(merged.TriggerMap, item)
+	}
+
+	return merged, nil
+}
+
+// addRunnerWorkflow adds a workflow, which runs the given project workflows after each other, and returns its id.
+// The runner workflow is named after the original workflow id (like: primary), if the project workflows share it.
+func addRunnerWorkflow(workflows map[string]bitriseModels.WorkflowModel, triggered []triggeredWorkflow) string {
+	id := triggered[0].originalID
+	workflowIDs := []string{}
+	for _, workflow := range triggered {
+		workflowIDs = append(workflowIDs, workflow.id)
+		if workflow.originalID != id {
+			id = "all"
+		}
+	}
+
+	runner := bitriseModels.WorkflowModel{
+		Summary:  "Runs the workflows of the projects: " + strings.Join(workflowIDs, ", "),
+		AfterRun: workflowIDs,
+	}
+
+	uniqueID := id
+	for n := 2; ; n++ {
+		existing, ok := workflows[uniqueID]
+		if !ok {
+			workflows[uniqueID] = runner
+			return uniqueID
+		}
+		if reflect.DeepEqual(existing, runner) {
+			return uniqueID
+		}
+		uniqueID = fmt.Sprintf("%s-%d", id, n)
+	}
+}
@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/stretchr/testify/require"
+)
+
+const testAndroidConfig = `format_version: "3"
+default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+project_type: android
+trigger_map:
+- push_branch: '*'
+  workflow: primary
+- pull_request_source_branch: '*'
+  workflow: primary
+workflows:
+  primary:
+    steps:
+    - gradle-runner:
+        inputs:
+        - gradlew_path: $GRADLEW_PATH
+`
+
+const testIOSConfig = `format_version: "3"
+default_step_lib_source: https://github.com/bitrise-io/bitrise-steplib.git
+project_type: ios
+app:
+  envs:
+  - BITRISE_EXPORT_METHOD: development
+trigger_map:
+- push_branch: '*'
+  workflow: primary
+- tag: '*'
+  workflow: deploy
+workflows:
+  deploy:
+    before_run:
+    - primary
+  primary:
+    steps:
+    - xcode-test:
+        inputs:
+        - project_path: $BITRISE_PROJECT_PATH
+`
+
+func TestMergeConfigs(t *testing.T) {
+	scanResult := models.ScanResultModel{
+		PlatformConfigMapMap: map[string]models.BitriseConfigMap{
+			"android": {"android-config": testAndroidConfig},
+			"ios":     {"ios-config": testIOSConfig},
+		},
+	}
+
+	config, err := MergeConfigs(scanResult, []ProjectSelection{
+		{Platform: "android", Config: "android-config", AppEnvs: []envmanModels.EnvironmentItemModel{{"GRADLEW_PATH": "app/gradlew"}}},
+		{Platform: "ios", Config: "ios-config", AppEnvs: []envmanModels.EnvironmentItemModel{{"BITRISE_PROJECT_PATH": "ios/Sample.xcworkspace"}}},
+		{Platform: "android", Config: "android-config", AppEnvs: []envmanModels.EnvironmentItemModel{{"GRADLEW_PATH": "wear/gradlew"}}},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "other", config.ProjectType)
+	require.Equal(t, "3", config.FormatVersion)
+
+	t.Log("namespaced app envs")
+	{
+		require.Equal(t, []envmanModels.EnvironmentItemModel{
+			{"ANDROID_GRADLEW_PATH": "app/gradlew"},
+			{"IOS_BITRISE_EXPORT_METHOD": "development"},
+			{"IOS_BITRISE_PROJECT_PATH": "ios/Sample.xcworkspace"},
+			{"ANDROID_2_GRADLEW_PATH": "wear/gradlew"},
+		}, config.App.Environments)
+	}
+
+	t.Log("project workflows")
+	{
+		require.Equal(t, 6, len(config.Workflows))
+
+		workflow := config.Workflows["android-2-primary"]
+		require.Equal(t, []envmanModels.EnvironmentItemModel{{"GRADLEW_PATH": "$ANDROID_2_GRADLEW_PATH"}}, workflow.Environments)
+		require.Equal(t, 1, len(workflow.Steps))
+
+		workflow = config.Workflows["ios-deploy"]
+		require.Equal(t, []string{"ios-primary"}, workflow.BeforeRun)
+		require.Equal(t, []envmanModels.EnvironmentItemModel{
+			{"BITRISE_EXPORT_METHOD": "$IOS_BITRISE_EXPORT_METHOD"},
+			{"BITRISE_PROJECT_PATH": "$IOS_BITRISE_PROJECT_PATH"},
+		}, workflow.Environments)
+	}
+
+	t.Log("trigger map")
+	{
+		require.Equal(t, bitriseModels.TriggerMapModel{
+			{PushBranch: "*", WorkflowID: "primary"},
+			{PullRequestSourceBranch: "*", WorkflowID: "primary-2"},
+			{Tag: "*", WorkflowID: "ios-deploy"},
+		}, config.TriggerMap)
+
+		require.Equal(t, []string{"android-primary", "ios-primary", "android-2-primary"}, config.Workflows["primary"].AfterRun)
+		require.Equal(t, []string{"android-primary", "android-2-primary"}, config.Workflows["primary-2"].AfterRun)
+	}
+
+	t.Log("missing config")
+	{
+		_, err := MergeConfigs(scanResult, []ProjectSelection{{Platform: "ios", Config: "missing"}})
+		require.EqualError(t, err, "config (missing) not found for platform: ios")
+
+		_, err = MergeConfigs(scanResult, nil)
+		require.EqualError(t, err, "no project selected")
+	}
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+)
+
+// ReadScanResult reads a scan result, saved by the config command, the .json files are decoded as JSON, the others as YAML.
+func ReadScanResult(pth string) (models.ScanResultModel, error) {
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return models.ScanResultModel{}, fmt.Errorf("failed to read scan result (%s), error: %s", pth, err)
+	}
+
+	var scanResult models.ScanResultModel
+	if strings.ToLower(filepath.Ext(pth)) == ".json" {
+		err = json.Unmarshal(content, &scanResult)
+	} else {
+		err = yaml.Unmarshal(content, &scanResult)
+	}
+	if err != nil {
+		return models.ScanResultModel{}, fmt.Errorf("failed to parse scan result (%s), error: %s", pth, err)
+	}
+	return scanResult, nil
+}
+
+// Resolve returns the config of a (stored) scan result, selected by the platform and the selected value of every option level,
+// from the platform's first option down to the config, extended with the collected app envs.
+// The selection is validated against the option tree (see: AnswerOptions), the levels with a single value can be omitted at the end.
+func Resolve(scanResult models.ScanResultModel, platform string, values []string) (bitriseModels.BitriseDataModel, error) {
+	return AnswerConfig(scanResult, Answers{ProjectAnswers: ProjectAnswers{Platform: platform, Path: values}})
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/output"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__resolve__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	scanResult := models.ScanResultModel{
+		PlatformOptionMap: map[string]models.OptionModel{
+			"ios": testIOSOptions(),
+		},
+		PlatformConfigMapMap: map[string]models.BitriseConfigMap{
+			"ios": {"ios-config": testIOSConfig},
+		},
+	}
+
+	for _, format := range []output.Format{output.YAMLFormat, output.JSONFormat} {
+		t.Logf("stored %s scan result", format)
+		{
+			pth, err := output.WriteToFile(scanResult, format, filepath.Join(tmpDir, "result"))
+			require.NoError(t, err)
+
+			storedResult, err := ReadScanResult(pth)
+			require.NoError(t, err)
+
+			options := storedResult.PlatformOptionMap["ios"]
+			require.Equal(t, []string{"ios/Sample.xcodeproj", "ios/Sample.xcworkspace"}, options.GetValues())
+
+			config, err := Resolve(storedResult, "ios", []string{"ios/Sample.xcworkspace", "Sample", "development"})
+			require.NoError(t, err)
+			require.Equal(t, "ios", config.ProjectType)
+			require.Equal(t, []envmanModels.EnvironmentItemModel{
+				{"BITRISE_EXPORT_METHOD": "development"},
+				{"BITRISE_PROJECT_PATH": "ios/Sample.xcworkspace"},
+				{"BITRISE_SCHEME": "Sample"},
+				{"BITRISE_EXPORT_METHOD": "development"},
+			}, config.App.Environments)
+		}
+	}
+
+	t.Log("invalid selection")
+	{
+		_, err := Resolve(scanResult, "ios", []string{"ios/Sample.xcworkspace", "Missing"})
+		require.EqualError(t, err, `failed to answer the ios options, error: answer (Missing) does not match any value of option: Scheme name (BITRISE_SCHEME), available values: "Sample"`)
+	}
+
+	t.Log("missing scan result")
+	{
+		_, err := ReadScanResult(filepath.Join(tmpDir, "missing.yml"))
+		require.Error(t, err)
+	}
+}
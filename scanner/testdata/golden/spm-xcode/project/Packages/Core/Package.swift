@@ -0,0 +1,14 @@
+// swift-tools-version:5.3
+import PackageDescription
+
+let package = Package(
+    name: "Core",
+    platforms: [.iOS(.v13)],
+    products: [
+        .library(name: "Core", targets: ["Core"]),
+    ],
+    targets: [
+        .target(name: "Core"),
+        .testTarget(name: "CoreTests", dependencies: ["Core"]),
+    ]
+)
@@ -0,0 +1,23 @@
+// swift-tools-version:5.3
+import PackageDescription
+
+let package = Package(
+    name: "Sample",
+    platforms: [
+        .macOS(.v10_15),
+        .iOS(.v13),
+    ],
+    products: [
+        // the library of the package
+        .library(name: "Sample", targets: ["Sample"]),
+        .executable(name: "sample-cli", targets: ["SampleCLI"]),
+    ],
+    dependencies: [
+        .package(url: "https://github.com/apple/swift-argument-parser", from: "0.3.0"),
+    ],
+    targets: [
+        .target(name: "Sample", dependencies: []),
+        .target(name: "SampleCLI", dependencies: ["Sample", .product(name: "ArgumentParser", package: "swift-argument-parser")]),
+        .testTarget(name: "SampleTests", dependencies: ["Sample"]),
+    ]
+)
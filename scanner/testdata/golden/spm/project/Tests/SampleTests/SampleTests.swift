@@ -0,0 +1,4 @@
+import XCTest
+@testable import Sample
+
+final class SampleTests: XCTestCase {}
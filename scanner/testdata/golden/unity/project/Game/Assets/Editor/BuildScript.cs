@@ -0,0 +1,8 @@
+using UnityEditor;
+
+public static class BuildScript
+{
+    public static void Export()
+    {
+    }
+}
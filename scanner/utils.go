@@ -98,26 +98,23 @@ func AskForOptions(options models.OptionModel) (string, []envmanModels.Environme
 	return configPth, appEnvs, nil
 }
 
-// AskForConfig ...
-func AskForConfig(scanResult models.ScanResultModel) (bitriseModels.BitriseDataModel, error) {
+// askForProjectSelection asks for a platform of the scan result and for a leaf config of the platform's options.
+func askForProjectSelection(scanResult models.ScanResultModel) (ProjectSelection, error) {
 
 	//
-	// Select platform
-	platforms := []string{}
-	for platform := range scanResult.PlatformOptionMap {
-		platforms = append(platforms, platform)
-	}
+	// Select platform, the best match comes first
+	platforms := scanResult.RankedPlatforms()
 
 	platform := ""
 	if len(platforms) == 0 {
-		return bitriseModels.BitriseDataModel{}, errors.New("no platform detected")
+		return ProjectSelection{}, errors.New("no platform detected")
 	} else if len(platforms) == 1 {
 		platform = platforms[0]
 	} else {
 		var err error
 		platform, err = goinp.SelectFromStrings("Select platform", platforms)
 		if err != nil {
-			return bitriseModels.BitriseDataModel{}, err
+			return ProjectSelection{}, err
 		}
 	}
 	// ---
@@ -126,27 +123,69 @@ func AskForConfig(scanResult models.ScanResultModel) (bitriseModels.BitriseDataM
 	// Select config
 	options, ok := scanResult.PlatformOptionMap[platform]
 	if !ok {
-		return bitriseModels.BitriseDataModel{}, fmt.Errorf("invalid platform selected: %s", platform)
+		return ProjectSelection{}, fmt.Errorf("invalid platform selected: %s", platform)
 	}
 
 	configPth, appEnvs, err := AskForOptions(options)
 	if err != nil {
-		return bitriseModels.BitriseDataModel{}, err
+		return ProjectSelection{}, err
 	}
 	// --
 
-	//
-	// Build config
-	configMap := scanResult.PlatformConfigMapMap[platform]
-	configStr := configMap[configPth]
+	return ProjectSelection{
+		Platform: platform,
+		Config:   configPth,
+		AppEnvs:  appEnvs,
+	}, nil
+}
+
+// selectedConfig returns the selected config of the scan result, extended with the selected app envs.
+func selectedConfig(scanResult models.ScanResultModel, selection ProjectSelection) (bitriseModels.BitriseDataModel, error) {
+	configMap := scanResult.PlatformConfigMapMap[selection.Platform]
+	configStr, ok := configMap[selection.Config]
+	if !ok {
+		return bitriseModels.BitriseDataModel{}, fmt.Errorf("config (%s) not found for platform: %s", selection.Config, selection.Platform)
+	}
 
 	var config bitriseModels.BitriseDataModel
 	if err := yaml.Unmarshal([]byte(configStr), &config); err != nil {
 		return bitriseModels.BitriseDataModel{}, fmt.Errorf("failed to unmarshal config, error: %s", err)
 	}
 
-	config.App.Environments = append(config.App.Environments, appEnvs...)
-	// ---
+	config.App.Environments = append(config.App.Environments, selection.AppEnvs...)
 
 	return config, nil
 }
+
+// AskForConfig ...
+func AskForConfig(scanResult models.ScanResultModel) (bitriseModels.BitriseDataModel, error) {
+	selection, err := askForProjectSelection(scanResult)
+	if err != nil {
+		return bitriseModels.BitriseDataModel{}, err
+	}
+
+	return selectedConfig(scanResult, selection)
+}
+
+// AskForMonorepoConfig asks for multiple projects of the scan result (possibly of different platforms),
+// and merges their configs into a single multi-workflow config (see: MergeConfigs).
+func AskForMonorepoConfig(scanResult models.ScanResultModel) (bitriseModels.BitriseDataModel, error) {
+	selections := []ProjectSelection{}
+	for {
+		selection, err := askForProjectSelection(scanResult)
+		if err != nil {
+			return bitriseModels.BitriseDataModel{}, err
+		}
+		selections = append(selections, selection)
+
+		addProject, err := goinp.AskForBoolWithDefault("Add another project?", false)
+		if err != nil {
+			return bitriseModels.BitriseDataModel{}, err
+		}
+		if !addProject {
+			break
+		}
+	}
+
+	return MergeConfigs(scanResult, selections)
+}
@@ -1,21 +1,33 @@
 package android
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/bitrise-core/bitrise-init/logger"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/steps"
 	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
 	envmanModels "github.com/bitrise-io/envman/models"
-	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/sliceutil"
 )
 
 // ScannerName ...
 const ScannerName = "android"
 
+// Diagnostic codes of the scanner.
+const (
+	LocalPropertiesCommittedCode = "android-local-properties-committed"
+	GradlewNotFoundCode          = "android-gradlew-not-found"
+)
+
+const gradleWrapperDocURL = "https://docs.gradle.org/current/userguide/gradle_wrapper.html"
+
 const (
 	configName        = "android-config"
 	defaultConfigName = "default-android-config"
@@ -52,14 +64,19 @@ var defaultGradleTasks = []string{
 
 // Scanner ...
 type Scanner struct {
-	FileList         []string
+	FileIndex        *utility.FileIndex
 	BuildGradleFiles []string
 	SearchDir        string
+
+	explanation models.Explanation
+	logger      logger.Logger
 }
 
 // NewScanner ...
 func NewScanner() *Scanner {
-	return &Scanner{}
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
 }
 
 // Name ...
@@ -67,89 +84,135 @@ func (scanner Scanner) Name() string {
 	return ScannerName
 }
 
-// DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
-	scanner.SearchDir = searchDir
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
 
-	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
-	if err != nil {
-		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
-	}
-	scanner.FileList = fileList
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	scanner.SearchDir = fileIndex.Root()
+	scanner.FileIndex = fileIndex
 
 	// Search for gradle file
-	log.Infoft("Searching for build.gradle files")
+	scanner.logger.Infoft("Searching for build.gradle files")
 
-	gradleFiles, err := utility.FilterRootBuildGradleFiles(fileList)
+	candidates := fileIndex.ByBase("build.gradle")
+	scanner.explanation = models.NewExplanation("build.gradle files", candidates...)
+
+	gradleFiles, err := utility.FilterRootBuildGradleFiles(candidates)
 	if err != nil {
 		return false, fmt.Errorf("failed to search for build.gradle files, error: %s", err)
 	}
 	scanner.BuildGradleFiles = gradleFiles
 
-	log.Printft("%d build.gradle files detected", len(gradleFiles))
+	for _, pth := range candidates {
+		if !sliceutil.IsStringInSlice(pth, gradleFiles) {
+			scanner.explanation.Reject(pth, "nested below the root level build.gradle files")
+		}
+	}
+
+	scanner.logger.Printft("%d build.gradle files detected", len(gradleFiles))
 	for _, file := range gradleFiles {
-		log.Printft("- %s", file)
+		scanner.logger.Printft("- %s", file)
 	}
 
 	if len(gradleFiles) == 0 {
-		log.Printft("platform not detected")
+		scanner.explanation.Reason = "no build.gradle file found"
+		scanner.logger.Printft("platform not detected")
 		return false, nil
 	}
 
-	log.Doneft("Platform detected")
+	scanner.explanation.Reason = fmt.Sprintf("root level build.gradle found: %s", strings.Join(gradleFiles, ", "))
+	scanner.logger.Doneft("Platform detected")
 
 	return true, nil
 }
 
-// ExcludedScannerNames ...
-func (scanner *Scanner) ExcludedScannerNames() []string {
-	return []string{}
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
+
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "build.gradle found", scanner.BuildGradleFiles...),
+		models.DepthEvidence(scanner.BuildGradleFiles...),
+	}
+
+	if gradlewFiles, err := utility.FilterGradlewFiles(scanner.FileIndex.ByBase("gradlew")); err == nil && len(gradlewFiles) > 0 {
+		for i, pth := range gradlewFiles {
+			gradlewFiles[i] = filepath.Clean(pth)
+		}
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "Gradle wrapper found", gradlewFiles...))
+	}
+	if manifests := scanner.FileIndex.ByBase("AndroidManifest.xml"); len(manifests) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "AndroidManifest.xml found", manifests...))
+	}
+
+	return models.NewConfidence(evidence...)
+}
+
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	return models.ScannerRelationships{}
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
-	warnings := models.Warnings{}
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	return GradleOptions(scanner.FileIndex, "", scanner.BuildGradleFiles, scanner.logger, func() *models.OptionModel {
+		return models.NewConfigOption(configName)
+	})
+}
+
+// GradleOptions returns the gradlew path, gradle file and gradle task options of the root level build.gradle files.
+// The gradle wrapper and the committed local.properties files are searched in the given (search dir relative) directory,
+// an empty dir stands for the search dir. Every gradle task gets the option returned by nextOption,
+// like the config option of the android scanner, or the next question of a scanner building on the gradle project.
+func GradleOptions(fileIndex *utility.FileIndex, dir string, buildGradleFiles []string, logger logger.Logger, nextOption func() *models.OptionModel) (models.OptionModel, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
 
 	// Search for local.properties file
-	for _, filePath := range scanner.FileList {
-		if strings.Contains(filePath, "local.properties") {
-			warningText := fmt.Sprintf(`the local.properties file should not be committed into the repository. The location of the file is:
-%s`, filePath)
-			log.Warnft(warningText)
-			warnings = append(warnings, fmt.Sprintf(warningText))
-		}
+	for _, filePath := range utility.PathsUnder(fileIndex.ByBase("local.properties"), dir) {
+		warning := models.NewWarning(LocalPropertiesCommittedCode, fmt.Sprintf(`the local.properties file should not be committed into the repository. The location of the file is:
+%s`, filePath), filePath)
+		logger.Warnft("%s", warning.Message)
+		warnings = append(warnings, warning)
 	}
 
 	// Search for gradle wrapper
-	log.Infoft("Searching for gradlew files")
+	logger.Infoft("Searching for gradlew files")
 
-	gradlewFiles, err := utility.FilterGradlewFiles(scanner.FileList)
+	gradlewFiles, err := utility.FilterGradlewFiles(utility.PathsUnder(fileIndex.ByBase("gradlew"), dir))
 	if err != nil {
 		return models.OptionModel{}, warnings, fmt.Errorf("Failed to list gradlew files, error: %s", err)
 	}
 
-	log.Printft("%d gradlew files detected", len(gradlewFiles))
+	logger.Printft("%d gradlew files detected", len(gradlewFiles))
 	for _, file := range gradlewFiles {
-		log.Printft("- %s", file)
+		logger.Printft("- %s", file)
 	}
 
 	rootGradlewPath := ""
 	gradlewFilesCount := len(gradlewFiles)
 	switch {
 	case gradlewFilesCount == 0:
-		log.Errorft("No gradle wrapper (gradlew) found")
-		return models.OptionModel{}, warnings, fmt.Errorf(`<b>No Gradle Wrapper (gradlew) found.</b> 
+		logger.Errorft("No gradle wrapper (gradlew) found")
+		return models.OptionModel{}, warnings, models.NewError(GradlewNotFoundCode, `No Gradle Wrapper (gradlew) found.
+Using a Gradle Wrapper (gradlew) is required, as the wrapper is what makes sure
+that the right Gradle version is installed and used for the build.`).WithDocURL(gradleWrapperDocURL).WithLegacyMessage(`<b>No Gradle Wrapper (gradlew) found.</b> 
 Using a Gradle Wrapper (gradlew) is required, as the wrapper is what makes sure
 that the right Gradle version is installed and used for the build. More info/guide: <a>https://docs.gradle.org/current/userguide/gradle_wrapper.html</a>`)
 	case gradlewFilesCount == 1:
 		rootGradlewPath = gradlewFiles[0]
 	case gradlewFilesCount > 1:
 		rootGradlewPath = gradlewFiles[0]
-		log.Warnft("Multiple gradlew file, detected:")
+		logger.Warnft("Multiple gradlew file, detected:")
 		for _, gradlewPth := range gradlewFiles {
-			log.Warnft("- %s", gradlewPth)
+			logger.Warnft("- %s", gradlewPth)
 		}
-		log.Warnft("Using: %s", rootGradlewPath)
+		logger.Warnft("Using: %s", rootGradlewPath)
 	}
 	// ---
 
@@ -159,19 +222,18 @@ that the right Gradle version is installed and used for the build. More info/gui
 	gradleFileOption := models.NewOption(gradleFileInputTitle, gradleFileInputEnvKey)
 	gradlewPthOption.AddOption(rootGradlewPath, gradleFileOption)
 
-	for _, gradleFile := range scanner.BuildGradleFiles {
-		log.Infoft("Inspecting gradle file: %s", gradleFile)
+	for _, gradleFile := range buildGradleFiles {
+		logger.Infoft("Inspecting gradle file: %s", gradleFile)
 
 		gradleTaskOption := models.NewOption(gradleTaskInputTitle, gradleTaskInputEnvKey)
 		gradleFileOption.AddOption(gradleFile, gradleTaskOption)
 
-		log.Printft("%d gradle tasks", len(defaultGradleTasks))
+		logger.Printft("%d gradle tasks", len(defaultGradleTasks))
 
 		for _, gradleTask := range defaultGradleTasks {
-			log.Printft("- %s", gradleTask)
+			logger.Printft("- %s", gradleTask)
 
-			configOption := models.NewConfigOption(configName)
-			gradleTaskOption.AddConfig(gradleTask, configOption)
+			gradleTaskOption.AddOption(gradleTask, nextOption())
 		}
 	}
 	// ---
@@ -179,8 +241,23 @@ that the right Gradle version is installed and used for the build. More info/gui
 	return *gradlewPthOption, warnings, nil
 }
 
+// GradleRunnerStepListItem returns the gradle-runner step, running the selected gradle task (see: GradleOptions).
+func GradleRunnerStepListItem() bitriseModels.StepListItemModel {
+	return steps.GradleRunnerStepListItem(
+		envmanModels.EnvironmentItemModel{gradleFileInputKey: "$" + gradleFileInputEnvKey},
+		envmanModels.EnvironmentItemModel{gradleTaskInputKey: "$" + gradleTaskInputEnvKey},
+		envmanModels.EnvironmentItemModel{gradlewPathInputKey: "$" + gradlewPathInputEnvKey},
+	)
+}
+
 // DefaultOptions ...
 func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	return DefaultGradleOptions(models.NewConfigOption(defaultConfigName))
+}
+
+// DefaultGradleOptions returns the gradlew path, gradle file and gradle task options to fill in by the user,
+// the gradle task is followed by the given option.
+func DefaultGradleOptions(nextOption *models.OptionModel) models.OptionModel {
 	gradlewPthOption := models.NewOption(gradlewPathInputTitle, gradlewPathInputEnvKey)
 
 	gradleFileOption := models.NewOption(gradleFileInputTitle, gradleFileInputEnvKey)
@@ -189,23 +266,18 @@ func (scanner *Scanner) DefaultOptions() models.OptionModel {
 	gradleTaskOption := models.NewOption(gradleTaskInputTitle, gradleTaskInputEnvKey)
 	gradleFileOption.AddOption("_", gradleTaskOption)
 
-	configOption := models.NewConfigOption(defaultConfigName)
-	gradleTaskOption.AddConfig("_", configOption)
+	gradleTaskOption.AddOption("_", nextOption)
 
 	return *gradlewPthOption
 }
 
 // Configs ...
-func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
 	configBuilder := models.NewDefaultConfigBuilder()
 
 	configBuilder.AppendPreparStepList(steps.InstallMissingAndroidToolsStepListItem())
 
-	configBuilder.AppendMainStepList(steps.GradleRunnerStepListItem(
-		envmanModels.EnvironmentItemModel{gradleFileInputKey: "$" + gradleFileInputEnvKey},
-		envmanModels.EnvironmentItemModel{gradleTaskInputKey: "$" + gradleTaskInputEnvKey},
-		envmanModels.EnvironmentItemModel{gradlewPathInputKey: "$" + gradlewPathInputEnvKey},
-	))
+	configBuilder.AppendMainStepList(GradleRunnerStepListItem())
 
 	config, err := configBuilder.Generate(ScannerName)
 	if err != nil {
@@ -227,11 +299,7 @@ func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
 	configBuilder := models.NewDefaultConfigBuilder()
 
 	configBuilder.AppendPreparStepList(steps.InstallMissingAndroidToolsStepListItem())
-	configBuilder.AppendMainStepList(steps.GradleRunnerStepListItem(
-		envmanModels.EnvironmentItemModel{gradleFileInputKey: "$" + gradleFileInputEnvKey},
-		envmanModels.EnvironmentItemModel{gradleTaskInputKey: "$" + gradleTaskInputEnvKey},
-		envmanModels.EnvironmentItemModel{gradlewPathInputKey: "$" + gradlewPathInputEnvKey},
-	))
+	configBuilder.AppendMainStepList(GradleRunnerStepListItem())
 
 	config, err := configBuilder.Generate(ScannerName)
 	if err != nil {
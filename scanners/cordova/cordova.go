@@ -1,22 +1,25 @@
 package cordova
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners/android"
 	"github.com/bitrise-core/bitrise-init/steps"
 	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
 	envmanModels "github.com/bitrise-io/envman/models"
-	"github.com/bitrise-io/go-utils/log"
-	"github.com/bitrise-io/go-utils/pathutil"
 )
 
-const scannerName = "cordova"
+// ScannerName ...
+const ScannerName = "cordova"
 
 const (
 	configName        = "cordova-config"
@@ -41,187 +44,241 @@ const (
 	targetEmulator = "emulator"
 )
 
+// TestRunner is the javascript test runner of a project.
+type TestRunner string
+
+// Test runners
+const (
+	NoTestRunner           TestRunner = ""
+	KarmaJasmineTestRunner TestRunner = "karma-jasmine"
+	JasmineTestRunner      TestRunner = "jasmine"
+)
+
+func hasDependencyContaining(packages utility.PackagesModel, name string) bool {
+	for dependency := range packages.Dependencies {
+		if strings.Contains(dependency, name) {
+			return true
+		}
+	}
+	for dependency := range packages.DevDependencies {
+		if strings.Contains(dependency, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectTestRunner detects the karma/jasmine or the jasmine tests of the project in the given (search dir relative) directory,
+// by the dependencies of the project's package.json and the test runner's config file.
+func DetectTestRunner(fs filesystem.FileSystem, projectDir string, packages utility.PackagesModel, logger logger.Logger) (TestRunner, error) {
+	// Search for karma/jasmine tests
+	logger.Printft("Searching for karma/jasmine test")
+
+	karmaJasmineDependencyFound := hasDependencyContaining(packages, "karma-jasmine")
+	logger.Printft("karma-jasmine dependency found: %v", karmaJasmineDependencyFound)
+
+	if karmaJasmineDependencyFound {
+		karmaConfigJSONPth := filepath.Join(projectDir, "karma.conf.js")
+		if exist, err := filesystem.IsPathExists(fs, karmaConfigJSONPth); err != nil {
+			return NoTestRunner, err
+		} else if exist {
+			logger.Printft("karma.conf.js found: %v", true)
+			return KarmaJasmineTestRunner, nil
+		}
+	}
+	logger.Printft("karma.conf.js found: %v", false)
+	// ---
+
+	// Search for jasmine tests
+	logger.Printft("Searching for jasmine test")
+
+	jasmineDependencyFound := hasDependencyContaining(packages, "jasmine")
+	logger.Printft("jasmine dependency found: %v", jasmineDependencyFound)
+
+	if jasmineDependencyFound {
+		jasmineConfigJSONPth := filepath.Join(projectDir, "spec", "support", "jasmine.json")
+		if exist, err := filesystem.IsPathExists(fs, jasmineConfigJSONPth); err != nil {
+			return NoTestRunner, err
+		} else if exist {
+			logger.Printft("jasmine.json found: %v", true)
+			return JasmineTestRunner, nil
+		}
+	}
+	logger.Printft("jasmine.json found: %v", false)
+	// ---
+
+	return NoTestRunner, nil
+}
+
+// TestRunnerStepListItem returns the step running the tests of the given test runner.
+func TestRunnerStepListItem(testRunner TestRunner, inputs ...envmanModels.EnvironmentItemModel) (bitriseModels.StepListItemModel, bool) {
+	switch testRunner {
+	case KarmaJasmineTestRunner:
+		return steps.KarmaJasmineTestRunnerStepListItem(inputs...), true
+	case JasmineTestRunner:
+		return steps.JasmineTestRunnerStepListItem(inputs...), true
+	}
+	return bitriseModels.StepListItemModel{}, false
+}
+
 //------------------
 // ScannerInterface
 //------------------
 
 // Scanner ...
 type Scanner struct {
+	fs                  filesystem.FileSystem
 	cordovaConfigPth    string
 	relCordovaConfigDir string
-	searchDir           string
-	hasKarmaJasmineTest bool
-	hasJasmineTest      bool
+	testRunner          TestRunner
+
+	explanation models.Explanation
+	logger      logger.Logger
 }
 
 // NewScanner ...
 func NewScanner() *Scanner {
-	return &Scanner{}
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
 }
 
 // Name ...
 func (scanner Scanner) Name() string {
-	return scannerName
+	return ScannerName
 }
 
-// DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
-	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
-	if err != nil {
-		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
-	}
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
 
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
 	// Search for config.xml file
-	log.Infoft("Searching for config.xml file")
+	scanner.logger.Infoft("Searching for config.xml file")
+
+	candidates := fileIndex.ByBase("config.xml")
+	scanner.explanation = models.NewExplanation("config.xml of a Cordova widget", candidates...)
 
-	configXMLPth, err := utility.FilterRootConfigXMLFile(fileList)
+	configXMLPth, err := utility.FilterRootConfigXMLFile(candidates)
 	if err != nil {
 		return false, fmt.Errorf("failed to search for config.xml file, error: %s", err)
 	}
 
-	log.Printft("config.xml: %s", configXMLPth)
+	scanner.logger.Printft("config.xml: %s", configXMLPth)
 
 	if configXMLPth == "" {
-		log.Printft("platform not detected")
+		scanner.explanation.Reason = "no config.xml found"
+		scanner.logger.Printft("platform not detected")
 		return false, nil
 	}
 
-	widget, err := utility.ParseConfigXML(configXMLPth)
+	for _, pth := range candidates {
+		if pth != configXMLPth {
+			scanner.explanation.Reject(pth, "not the root level config.xml")
+		}
+	}
+
+	fs := fileIndex.FS()
+
+	widget, err := utility.ParseConfigXML(fs, configXMLPth)
 	if err != nil {
-		log.Printft("can not parse config.xml as a Cordova widget, error: %s", err)
-		log.Printft("platform not detected")
+		scanner.explanation.Reject(configXMLPth, fmt.Sprintf("can not parse as a Cordova widget: %s", err))
+		scanner.explanation.Reason = "the config.xml is not a Cordova widget"
+		scanner.logger.Printft("can not parse config.xml as a Cordova widget, error: %s", err)
+		scanner.logger.Printft("platform not detected")
 		return false, nil
 	}
 
 	// ensure it is a cordova widget
 	if !strings.Contains(widget.XMLNSCDV, "cordova.apache.org") {
-		log.Printft("config.xml propert: xmlns:cdv does not contain cordova.apache.org")
-		log.Printft("platform not detected")
+		scanner.explanation.Reject(configXMLPth, "the xmlns:cdv namespace does not contain cordova.apache.org")
+		scanner.explanation.Reason = "the config.xml is not a Cordova widget"
+		scanner.logger.Printft("config.xml propert: xmlns:cdv does not contain cordova.apache.org")
+		scanner.logger.Printft("platform not detected")
 		return false, nil
 	}
 
-	// ensure it is not an ionic project
-	projectBaseDir := filepath.Dir(configXMLPth)
+	scanner.explanation.Reason = fmt.Sprintf("Cordova widget found: %s", configXMLPth)
+	scanner.logger.Doneft("Platform detected")
 
-	if exist, err := pathutil.IsPathExists(filepath.Join(projectBaseDir, "ionic.project")); err != nil {
-		return false, fmt.Errorf("failed to check if project is an ionic project, error: %s", err)
-	} else if exist {
-		log.Printft("ionic.project file found seems to be an ionic project")
-		return false, nil
-	}
+	scanner.fs = fs
+	scanner.cordovaConfigPth = configXMLPth
 
-	if exist, err := pathutil.IsPathExists(filepath.Join(projectBaseDir, "ionic.config.json")); err != nil {
-		return false, fmt.Errorf("failed to check if project is an ionic project, error: %s", err)
-	} else if exist {
-		log.Printft("ionic.config.json file found seems to be an ionic project")
-		return false, nil
-	}
+	return true, nil
+}
+
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
 
-	log.Doneft("Platform detected")
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "Cordova config.xml found", scanner.cordovaConfigPth),
+		models.DepthEvidence(scanner.cordovaConfigPth),
+	}
 
-	scanner.cordovaConfigPth = configXMLPth
-	scanner.searchDir = searchDir
+	projectDir := filepath.Dir(scanner.cordovaConfigPth)
+	for _, supportingFile := range []struct {
+		name        string
+		description string
+	}{
+		{"package.json", "package.json found"},
+		{"package-lock.json", "npm lock file found"},
+		{"yarn.lock", "yarn lock file found"},
+	} {
+		pth := filepath.Join(projectDir, supportingFile.name)
+		if exist, err := filesystem.IsPathExists(scanner.fs, pth); err == nil && exist {
+			evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, supportingFile.description, pth))
+		}
+	}
 
-	return true, nil
+	return models.NewConfidence(evidence...)
 }
 
-// ExcludedScannerNames ...
-func (scanner *Scanner) ExcludedScannerNames() []string {
-	return []string{
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	// the native projects of the cordova platforms (platforms/ios, platforms/android) are generated by cordova
+	nativeScannerNames := []string{
 		string(utility.XcodeProjectTypeIOS),
 		string(utility.XcodeProjectTypeMacOS),
 		android.ScannerName,
 	}
+
+	return models.ScannerRelationships{
+		Precedes:      nativeScannerNames,
+		ConflictsWith: nativeScannerNames,
+	}
+}
+
+// ClaimedPaths returns the platforms directory of the detected project,
+// the native projects in it are hidden from the conflicting scanners.
+func (scanner *Scanner) ClaimedPaths() []string {
+	return []string{filepath.Join(filepath.Dir(scanner.cordovaConfigPth), "platforms")}
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
-	warnings := models.Warnings{}
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
 	projectRootDir := filepath.Dir(scanner.cordovaConfigPth)
 
 	packagesJSONPth := filepath.Join(projectRootDir, "package.json")
-	packages, err := utility.ParsePackagesJSON(packagesJSONPth)
+	packages, err := utility.ParsePackagesJSON(scanner.fs, packagesJSONPth)
 	if err != nil {
 		return models.OptionModel{}, warnings, err
 	}
 
-	// Search for karma/jasmine tests
-	log.Printft("Searching for karma/jasmine test")
-
-	karmaTestDetected := false
-
-	karmaJasmineDependencyFound := false
-	for dependency := range packages.Dependencies {
-		if strings.Contains(dependency, "karma-jasmine") {
-			karmaJasmineDependencyFound = true
-		}
-	}
-	if !karmaJasmineDependencyFound {
-		for dependency := range packages.DevDependencies {
-			if strings.Contains(dependency, "karma-jasmine") {
-				karmaJasmineDependencyFound = true
-			}
-		}
-	}
-	log.Printft("karma-jasmine dependency found: %v", karmaJasmineDependencyFound)
-
-	if karmaJasmineDependencyFound {
-		karmaConfigJSONPth := filepath.Join(projectRootDir, "karma.conf.js")
-		if exist, err := pathutil.IsPathExists(karmaConfigJSONPth); err != nil {
-			return models.OptionModel{}, warnings, err
-		} else if exist {
-			karmaTestDetected = true
-		}
-	}
-	log.Printft("karma.conf.js found: %v", karmaTestDetected)
-
-	scanner.hasKarmaJasmineTest = karmaTestDetected
-	// ---
-
-	// Search for jasmine tests
-	jasminTestDetected := false
-
-	if !karmaTestDetected {
-		log.Printft("Searching for jasmine test")
-
-		jasmineDependencyFound := false
-		for dependency := range packages.Dependencies {
-			if strings.Contains(dependency, "jasmine") {
-				jasmineDependencyFound = true
-				break
-			}
-		}
-		if !jasmineDependencyFound {
-			for dependency := range packages.DevDependencies {
-				if strings.Contains(dependency, "jasmine") {
-					jasmineDependencyFound = true
-					break
-				}
-			}
-		}
-		log.Printft("jasmine dependency found: %v", jasmineDependencyFound)
-
-		if jasmineDependencyFound {
-			jasmineConfigJSONPth := filepath.Join(projectRootDir, "spec", "support", "jasmine.json")
-			if exist, err := pathutil.IsPathExists(jasmineConfigJSONPth); err != nil {
-				return models.OptionModel{}, warnings, err
-			} else if exist {
-				jasminTestDetected = true
-			}
-		}
-
-		log.Printft("jasmine.json found: %v", jasminTestDetected)
-
-		scanner.hasJasmineTest = jasminTestDetected
-	}
-	// ---
-
-	// Get relative config.xml dir
-	cordovaConfigDir := filepath.Dir(scanner.cordovaConfigPth)
-	relCordovaConfigDir, err := utility.RelPath(scanner.searchDir, cordovaConfigDir)
+	testRunner, err := DetectTestRunner(scanner.fs, projectRootDir, packages, scanner.logger)
 	if err != nil {
-		return models.OptionModel{}, warnings, fmt.Errorf("Failed to get relative config.xml dir path, error: %s", err)
+		return models.OptionModel{}, warnings, err
 	}
+	scanner.testRunner = testRunner
+
+	// Get relative config.xml dir, the indexed paths are relative to the search dir
+	relCordovaConfigDir := filepath.Dir(scanner.cordovaConfigPth)
 	if relCordovaConfigDir == "." {
 		// config.xml placed in the search dir, no need to change-dir in the workflows
 		relCordovaConfigDir = ""
@@ -278,7 +335,7 @@ func (scanner *Scanner) DefaultOptions() models.OptionModel {
 }
 
 // Configs ...
-func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
 	configBuilder := models.NewDefaultConfigBuilder()
 
 	workdirEnvList := []envmanModels.EnvironmentItemModel{}
@@ -286,22 +343,14 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 		workdirEnvList = append(workdirEnvList, envmanModels.EnvironmentItemModel{workDirInputKey: "$" + workDirInputEnvKey})
 	}
 
-	if scanner.hasJasmineTest || scanner.hasKarmaJasmineTest {
+	if testStep, ok := TestRunnerStepListItem(scanner.testRunner, workdirEnvList...); ok {
 		// CI
-		if scanner.hasKarmaJasmineTest {
-			configBuilder.AppendMainStepList(steps.KarmaJasmineTestRunnerStepListItem(workdirEnvList...))
-		} else if scanner.hasJasmineTest {
-			configBuilder.AppendMainStepList(steps.JasmineTestRunnerStepListItem(workdirEnvList...))
-		}
+		configBuilder.AppendMainStepList(testStep)
 
 		// CD
 		configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
 
-		if scanner.hasKarmaJasmineTest {
-			configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.KarmaJasmineTestRunnerStepListItem(workdirEnvList...))
-		} else if scanner.hasJasmineTest {
-			configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.JasmineTestRunnerStepListItem(workdirEnvList...))
-		}
+		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, testStep)
 
 		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.GenerateCordovaBuildConfigStepListItem())
 
@@ -314,7 +363,7 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 		}
 		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.CordovaArchiveStepListItem(cordovaArchiveEnvs...))
 
-		config, err := configBuilder.Generate(scannerName)
+		config, err := configBuilder.Generate(ScannerName)
 		if err != nil {
 			return models.BitriseConfigMap{}, err
 		}
@@ -340,7 +389,7 @@ func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
 	}
 	configBuilder.AppendMainStepList(steps.CordovaArchiveStepListItem(cordovaArchiveEnvs...))
 
-	config, err := configBuilder.Generate(scannerName)
+	config, err := configBuilder.Generate(ScannerName)
 	if err != nil {
 		return models.BitriseConfigMap{}, err
 	}
@@ -367,7 +416,7 @@ func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
 	}
 	configBuilder.AppendMainStepList(steps.CordovaArchiveStepListItem(cordovaArchiveEnvs...))
 
-	config, err := configBuilder.Generate(scannerName)
+	config, err := configBuilder.Generate(ScannerName)
 	if err != nil {
 		return models.BitriseConfigMap{}, err
 	}
@@ -1,19 +1,30 @@
 package fastlane
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/steps"
 	"github.com/bitrise-core/bitrise-init/utility"
 	envmanModels "github.com/bitrise-io/envman/models"
-	"github.com/bitrise-io/go-utils/log"
 )
 
 const scannerName = "fastlane"
 
+// Diagnostic codes of the scanner.
+const (
+	InspectFastfileFailedCode = "fastlane-inspect-fastfile-failed"
+	NoLanesCode               = "fastlane-no-lanes"
+	NoValidFastfileCode       = "fastlane-no-valid-fastfile"
+)
+
 const (
 	configName        = "fastlane-config"
 	defaultConfigName = "default-fastlane-config"
@@ -44,11 +55,18 @@ const (
 // Scanner ...
 type Scanner struct {
 	Fastfiles []string
+
+	fileIndex *utility.FileIndex
+
+	explanation models.Explanation
+	logger      logger.Logger
 }
 
 // NewScanner ...
 func NewScanner() *Scanner {
-	return &Scanner{}
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
 }
 
 // Name ...
@@ -56,46 +74,93 @@ func (scanner Scanner) Name() string {
 	return scannerName
 }
 
-// DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
-	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
-	if err != nil {
-		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
-	}
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
 
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
 	// Search for Fastfile
-	log.Infoft("Searching for Fastfiles")
+	scanner.logger.Infoft("Searching for Fastfiles")
 
-	fastfiles, err := utility.FilterFastfiles(fileList)
+	candidates := fileIndex.ByBase("Fastfile")
+	scanner.explanation = models.NewExplanation("Fastfiles", candidates...)
+
+	fastfiles, err := utility.FilterFastfiles(candidates)
 	if err != nil {
-		return false, fmt.Errorf("failed to search for Fastfile in (%s), error: %s", searchDir, err)
+		return false, fmt.Errorf("failed to search for Fastfile in (%s), error: %s", fileIndex.Root(), err)
 	}
 
 	scanner.Fastfiles = fastfiles
+	scanner.fileIndex = fileIndex
 
-	log.Printft("%d Fastfiles detected", len(fastfiles))
+	scanner.logger.Printft("%d Fastfiles detected", len(fastfiles))
 	for _, file := range fastfiles {
-		log.Printft("- %s", file)
+		scanner.logger.Printft("- %s", file)
 	}
 
 	if len(fastfiles) == 0 {
-		log.Printft("platform not detected")
+		scanner.explanation.Reason = "no Fastfile found"
+		scanner.logger.Printft("platform not detected")
 		return false, nil
 	}
 
-	log.Doneft("Platform detected")
+	scanner.explanation.Reason = fmt.Sprintf("Fastfile found: %s", strings.Join(fastfiles, ", "))
+	scanner.logger.Doneft("Platform detected")
 
 	return true, nil
 }
 
-// ExcludedScannerNames ...
-func (scanner *Scanner) ExcludedScannerNames() []string {
-	return []string{}
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
+
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	workDirFastfiles := []string{}
+	appfiles := []string{}
+	for _, fastfile := range scanner.Fastfiles {
+		workDirFastfiles = append(workDirFastfiles, filepath.Join(utility.FastlaneWorkDir(fastfile), filepath.Base(fastfile)))
+
+		appfile := filepath.Join(filepath.Dir(fastfile), "Appfile")
+		if exist, err := filesystem.IsPathExists(scanner.fileIndex.FS(), appfile); err == nil && exist {
+			appfiles = append(appfiles, appfile)
+		}
+	}
+
+	// the depth of a Fastfile is the depth of its fastlane work dir, but the evidence refers to the Fastfile itself
+	depthEvidence := models.DepthEvidence(workDirFastfiles...)
+	for i, pth := range workDirFastfiles {
+		if len(depthEvidence.Paths) > 0 && depthEvidence.Paths[0] == pth {
+			depthEvidence.Paths = []string{scanner.Fastfiles[i]}
+			break
+		}
+	}
+
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "Fastfile found", scanner.Fastfiles...),
+		depthEvidence,
+	}
+	if len(appfiles) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "Appfile found", appfiles...))
+	}
+
+	return models.NewConfidence(evidence...)
+}
+
+// Relationships ...
+// The lanes of a Fastfile build the native projects next to it, but they do not replace the ios and android configs of those projects:
+// the fastlane config runs a lane selected by the user, while the native configs do not depend on the Fastfile,
+// so the scanners neither conflict, nor augment each other's configs and run independently.
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	return models.ScannerRelationships{}
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
-	warnings := models.Warnings{}
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
 
 	isValidFastfileFound := false
 
@@ -104,23 +169,23 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 	workDirOption := models.NewOption(workDirInputTitle, workDirInputEnvKey)
 
 	for _, fastfile := range scanner.Fastfiles {
-		log.Infoft("Inspecting Fastfile: %s", fastfile)
+		scanner.logger.Infoft("Inspecting Fastfile: %s", fastfile)
 
 		workDir := utility.FastlaneWorkDir(fastfile)
-		log.Printft("fastlane work dir: %s", workDir)
+		scanner.logger.Printft("fastlane work dir: %s", workDir)
 
-		lanes, err := utility.InspectFastfile(fastfile)
+		lanes, err := utility.InspectFastfile(scanner.fileIndex.FS(), fastfile)
 		if err != nil {
-			log.Warnft("Failed to inspect Fastfile, error: %s", err)
-			warnings = append(warnings, fmt.Sprintf("Failed to inspect Fastfile (%s), error: %s", fastfile, err))
+			scanner.logger.Warnft("Failed to inspect Fastfile, error: %s", err)
+			warnings = append(warnings, models.NewWarning(InspectFastfileFailedCode, fmt.Sprintf("Failed to inspect Fastfile (%s), error: %s", fastfile, err), fastfile))
 			continue
 		}
 
-		log.Printft("%d lanes found", len(lanes))
+		scanner.logger.Printft("%d lanes found", len(lanes))
 
 		if len(lanes) == 0 {
-			log.Warnft("No lanes found")
-			warnings = append(warnings, fmt.Sprintf("No lanes found for Fastfile: %s", fastfile))
+			scanner.logger.Warnft("No lanes found")
+			warnings = append(warnings, models.NewWarning(NoLanesCode, fmt.Sprintf("No lanes found for Fastfile: %s", fastfile), fastfile))
 			continue
 		}
 
@@ -130,7 +195,7 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 		workDirOption.AddOption(workDir, laneOption)
 
 		for _, lane := range lanes {
-			log.Printft("- %s", lane)
+			scanner.logger.Printft("- %s", lane)
 
 			configOption := models.NewConfigOption(configName)
 			laneOption.AddConfig(lane, configOption)
@@ -138,8 +203,8 @@ func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
 	}
 
 	if !isValidFastfileFound {
-		log.Errorft("No valid Fastfile found")
-		warnings = append(warnings, "No valid Fastfile found")
+		scanner.logger.Errorft("No valid Fastfile found")
+		warnings = append(warnings, models.NewWarning(NoValidFastfileCode, "No valid Fastfile found"))
 		return models.OptionModel{}, warnings, nil
 	}
 
@@ -160,7 +225,7 @@ func (scanner *Scanner) DefaultOptions() models.OptionModel {
 }
 
 // Configs ...
-func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
 	configBuilder := models.NewDefaultConfigBuilder()
 
 	configBuilder.AppendPreparStepList(steps.CertificateAndProfileInstallerStepListItem())
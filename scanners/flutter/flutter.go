@@ -0,0 +1,398 @@
+package flutter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners/android"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+)
+
+// ScannerName ...
+const ScannerName = "flutter"
+
+const (
+	configName        = "flutter-config"
+	defaultConfigName = "default-flutter-config"
+)
+
+const (
+	pubspecBasePath = "pubspec.yaml"
+	// symlinksDirName is the directory of the plugin symlinks in the ios folder of a Flutter project
+	symlinksDirName = ".symlinks"
+	testDirName     = "test"
+	androidDirName  = "android"
+	iosDirName      = "ios"
+)
+
+// Platforms of the flutter build
+const (
+	platformAndroid = "android"
+	platformIOS     = "ios"
+	platformBoth    = "both"
+)
+
+// Step Inputs
+const (
+	projectLocationInputKey    = "project_location"
+	projectLocationInputTitle  = "Project location"
+	projectLocationInputEnvKey = "FLUTTER_PROJECT_LOCATION"
+)
+
+const (
+	platformInputKey    = "platform"
+	platformInputTitle  = "Platform"
+	platformInputEnvKey = "FLUTTER_PLATFORM"
+)
+
+const (
+	versionInputKey = "version"
+)
+
+// project is a detected Flutter app.
+type project struct {
+	pubspecPth string
+	pubspec    utility.PubspecModel
+	hasTest    bool
+	hasAndroid bool
+	hasIOS     bool
+}
+
+func (proj project) dir() string {
+	return filepath.Dir(proj.pubspecPth)
+}
+
+// platforms returns the build platforms of the project, by the platform folders.
+func (proj project) platforms() []string {
+	platforms := []string{}
+	if proj.hasAndroid {
+		platforms = append(platforms, platformAndroid)
+	}
+	if proj.hasIOS {
+		platforms = append(platforms, platformIOS)
+	}
+	if proj.hasAndroid && proj.hasIOS {
+		platforms = append(platforms, platformBoth)
+	}
+	return platforms
+}
+
+// configDescriptor describes a config of the scanner.
+type configDescriptor struct {
+	hasTest bool
+	// platform is the platform of the flutter build, empty if the project has no platform folder.
+	platform string
+	// flutterVersion is the Flutter SDK version to install, empty if the project does not pin a version.
+	flutterVersion string
+}
+
+func (descriptor configDescriptor) configName() string {
+	name := configName
+	if descriptor.hasTest {
+		name += "-test"
+	}
+	if descriptor.platform != "" {
+		name += "-app-" + descriptor.platform
+	}
+	if descriptor.flutterVersion != "" {
+		name += "-sdk-" + descriptor.flutterVersion
+	}
+	return name
+}
+
+//------------------
+// ScannerInterface
+//------------------
+
+// Scanner ...
+type Scanner struct {
+	fs       filesystem.FileSystem
+	projects []project
+
+	configDescriptors []configDescriptor
+
+	explanation models.Explanation
+	logger      logger.Logger
+}
+
+// NewScanner ...
+func NewScanner() *Scanner {
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
+}
+
+// Name ...
+func (scanner Scanner) Name() string {
+	return ScannerName
+}
+
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	fs := fileIndex.FS()
+
+	// Search for pubspec.yaml files
+	scanner.logger.Infoft("Searching for pubspec.yaml files")
+
+	candidates := fileIndex.ByBase(pubspecBasePath)
+	scanner.explanation = models.NewExplanation("pubspec.yaml with a flutter dependency", candidates...)
+
+	forbidSymlinksFilter := utility.ComponentFilter(symlinksDirName, false)
+
+	for _, pth := range candidates {
+		if allowed, err := forbidSymlinksFilter(pth); err != nil {
+			return false, err
+		} else if !allowed {
+			scanner.explanation.Reject(pth, "inside the plugin symlinks directory of a Flutter project")
+			continue
+		}
+
+		pubspec, err := utility.ParsePubspec(fs, pth)
+		if err != nil {
+			scanner.explanation.Reject(pth, fmt.Sprintf("can not parse: %s", err))
+			continue
+		}
+
+		if !pubspec.HasFlutterDependency() {
+			scanner.explanation.Reject(pth, "no flutter dependency, not a Flutter project")
+			continue
+		}
+
+		dir := filepath.Dir(pth)
+		proj := project{
+			pubspecPth: pth,
+			pubspec:    pubspec,
+			hasTest:    fileIndex.IsDir(filepath.Join(dir, testDirName)),
+			hasAndroid: fileIndex.IsDir(filepath.Join(dir, androidDirName)),
+			hasIOS:     fileIndex.IsDir(filepath.Join(dir, iosDirName)),
+		}
+		scanner.projects = append(scanner.projects, proj)
+
+		scanner.logger.Printft("- %s", pth)
+		scanner.logger.Printft("  flutter SDK constraint: %s, test dir: %v, android: %v, ios: %v", pubspec.Environment.Flutter, proj.hasTest, proj.hasAndroid, proj.hasIOS)
+	}
+
+	if len(scanner.projects) == 0 {
+		scanner.explanation.Reason = "no pubspec.yaml with a flutter dependency found"
+		scanner.logger.Printft("platform not detected")
+		return false, nil
+	}
+
+	scanner.explanation.Reason = fmt.Sprintf("%d Flutter project(s) found", len(scanner.projects))
+	scanner.logger.Doneft("Platform detected")
+
+	scanner.fs = fs
+
+	return true, nil
+}
+
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
+
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	pubspecPths := []string{}
+	testDirs := []string{}
+	platformDirs := []string{}
+	lockFiles := []string{}
+	for _, proj := range scanner.projects {
+		pubspecPths = append(pubspecPths, proj.pubspecPth)
+		if proj.hasTest {
+			testDirs = append(testDirs, filepath.Join(proj.dir(), testDirName))
+		}
+		if proj.hasAndroid {
+			platformDirs = append(platformDirs, filepath.Join(proj.dir(), androidDirName))
+		}
+		if proj.hasIOS {
+			platformDirs = append(platformDirs, filepath.Join(proj.dir(), iosDirName))
+		}
+
+		lockFilePth := filepath.Join(proj.dir(), "pubspec.lock")
+		if exist, err := filesystem.IsPathExists(scanner.fs, lockFilePth); err == nil && exist {
+			lockFiles = append(lockFiles, lockFilePth)
+		}
+	}
+
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "pubspec.yaml with a flutter dependency found", pubspecPths...),
+		models.DepthEvidence(pubspecPths[0]),
+	}
+	if len(platformDirs) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "platform folder found", platformDirs...))
+	}
+	if len(testDirs) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "test directory found", testDirs...))
+	}
+	if len(lockFiles) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "pubspec lock file found", lockFiles...))
+	}
+
+	return models.NewConfidence(evidence...)
+}
+
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	// the android and ios folders of a Flutter project hold the native runners of the Flutter app
+	nativeScannerNames := []string{
+		string(utility.XcodeProjectTypeIOS),
+		android.ScannerName,
+	}
+
+	return models.ScannerRelationships{
+		Precedes:      nativeScannerNames,
+		ConflictsWith: nativeScannerNames,
+	}
+}
+
+// ClaimedPaths returns the android and ios directories of the detected projects,
+// the native runners in them are hidden from the conflicting scanners.
+func (scanner *Scanner) ClaimedPaths() []string {
+	claimedPaths := []string{}
+	for _, proj := range scanner.projects {
+		claimedPaths = append(claimedPaths, filepath.Join(proj.dir(), androidDirName), filepath.Join(proj.dir(), iosDirName))
+	}
+	return claimedPaths
+}
+
+func (scanner *Scanner) addConfigDescriptor(descriptor configDescriptor) {
+	for _, configDescriptor := range scanner.configDescriptors {
+		if configDescriptor == descriptor {
+			return
+		}
+	}
+	scanner.configDescriptors = append(scanner.configDescriptors, descriptor)
+}
+
+// Options ...
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
+
+	projectLocationOption := models.NewOption(projectLocationInputTitle, projectLocationInputEnvKey)
+
+	for _, proj := range scanner.projects {
+		platforms := proj.platforms()
+		if len(platforms) == 0 {
+			descriptor := configDescriptor{hasTest: proj.hasTest, flutterVersion: proj.pubspec.FlutterVersion()}
+			scanner.addConfigDescriptor(descriptor)
+
+			projectLocationOption.AddConfig(proj.dir(), models.NewConfigOption(descriptor.configName()))
+			continue
+		}
+
+		platformOption := models.NewOption(platformInputTitle, platformInputEnvKey)
+		projectLocationOption.AddOption(proj.dir(), platformOption)
+
+		for _, platform := range platforms {
+			descriptor := configDescriptor{hasTest: proj.hasTest, platform: platform, flutterVersion: proj.pubspec.FlutterVersion()}
+			scanner.addConfigDescriptor(descriptor)
+
+			platformOption.AddConfig(platform, models.NewConfigOption(descriptor.configName()))
+		}
+	}
+
+	return *projectLocationOption, warnings, nil
+}
+
+// DefaultOptions ...
+func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	projectLocationOption := models.NewOption(projectLocationInputTitle, projectLocationInputEnvKey)
+
+	platformOption := models.NewOption(platformInputTitle, platformInputEnvKey)
+	projectLocationOption.AddOption("_", platformOption)
+
+	for _, platform := range []string{platformAndroid, platformIOS, platformBoth} {
+		platformOption.AddConfig(platform, models.NewConfigOption(defaultConfigName))
+	}
+
+	return *projectLocationOption
+}
+
+func generateConfig(descriptor configDescriptor) (string, error) {
+	configBuilder := models.NewDefaultConfigBuilder()
+	projectLocationInput := envmanModels.EnvironmentItemModel{projectLocationInputKey: "$" + projectLocationInputEnvKey}
+
+	installerInputs := []envmanModels.EnvironmentItemModel{}
+	if descriptor.flutterVersion != "" {
+		installerInputs = append(installerInputs, envmanModels.EnvironmentItemModel{versionInputKey: descriptor.flutterVersion})
+	}
+
+	testStepList := []bitriseModels.StepListItemModel{
+		steps.FlutterInstallerStepListItem(installerInputs...),
+		steps.FlutterAnalyzeStepListItem(projectLocationInput),
+	}
+	if descriptor.hasTest {
+		testStepList = append(testStepList, steps.FlutterTestStepListItem(projectLocationInput))
+	}
+
+	// CI
+	configBuilder.AppendMainStepList(testStepList...)
+
+	// CD
+	if descriptor.platform != "" {
+		configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
+
+		if descriptor.platform != platformAndroid {
+			configBuilder.AppendPreparStepListTo(models.DeployWorkflowID, steps.CertificateAndProfileInstallerStepListItem())
+		}
+
+		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, testStepList...)
+		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.FlutterBuildStepListItem(
+			projectLocationInput,
+			envmanModels.EnvironmentItemModel{platformInputKey: "$" + platformInputEnvKey},
+		))
+	}
+
+	config, err := configBuilder.Generate(ScannerName)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Configs ...
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
+	configMap := models.BitriseConfigMap{}
+	for _, descriptor := range scanner.configDescriptors {
+		config, err := generateConfig(descriptor)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+		configMap[descriptor.configName()] = config
+	}
+
+	return configMap, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	config, err := generateConfig(configDescriptor{hasTest: true, platform: platformBoth})
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+
+	return models.BitriseConfigMap{
+		defaultConfigName: config,
+	}, nil
+}
@@ -0,0 +1,522 @@
+package ionic
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners/android"
+	"github.com/bitrise-core/bitrise-init/scanners/cordova"
+	"github.com/bitrise-core/bitrise-init/scanners/xcode"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+)
+
+// ScannerName ...
+const ScannerName = "ionic"
+
+const (
+	configNameFormat          = "ionic-%s-config"
+	capacitorConfigNameFormat = "ionic-capacitor-%s-config"
+	defaultConfigName         = "default-ionic-config"
+)
+
+const (
+	nodeModulesDirName = "node_modules"
+	androidDirName     = "android"
+	iosDirName         = "ios"
+	platformsDirName   = "platforms"
+	// ionic1ProjectType is the project type of the Ionic 1 projects, their web assets are not built
+	ionic1ProjectType = "ionic1"
+	// capacitorCoreDependency is the core package of the Capacitor runtime
+	capacitorCoreDependency = "@capacitor/core"
+)
+
+// Step Inputs
+const (
+	workDirInputKey    = "workdir"
+	workDirInputTitle  = "Directory of the Ionic project"
+	workDirInputEnvKey = "IONIC_WORK_DIR"
+)
+
+const (
+	platformInputKey    = "platform"
+	platformInputTitle  = "Platform to use in ionic-cli commands"
+	platformInputEnvKey = "IONIC_PLATFORM"
+)
+
+const (
+	targetInputKey = "target"
+	targetEmulator = "emulator"
+)
+
+const (
+	commandInputKey          = "command"
+	contentInputKey          = "content"
+	scriptWorkingDirInputKey = "working_dir"
+)
+
+const (
+	gradleFileInputKey  = "gradle_file"
+	gradleTaskInputKey  = "gradle_task"
+	gradlewPathInputKey = "gradlew_path"
+	// capacitorGradleTask builds the release apk of the Capacitor android project
+	capacitorGradleTask = "assembleRelease"
+)
+
+const (
+	// capacitorWorkspacePth is the Xcode workspace of the Capacitor ios project, relative to the project dir
+	capacitorWorkspacePth = "ios/App/App.xcworkspace"
+	capacitorScheme       = "App"
+)
+
+const capacitorSyncTitle = "Sync the Capacitor native projects"
+
+// capacitorSyncContent syncs the web assets and the native plugins into the native projects of the selected platforms
+const capacitorSyncContent = `#!/usr/bin/env bash
+set -ex
+
+for platform in ${` + platformInputEnvKey + `//,/ } ; do
+  npx cap sync "$platform"
+done
+`
+
+var platforms = []string{"ios", "android", "ios,android"}
+
+// configName returns the config name of the given integration and platform,
+// the Capacitor configs build the native projects of the selected platform, so every platform has its own config.
+func configName(integration utility.IonicIntegration, platform string) string {
+	if integration == utility.IonicCapacitorIntegration {
+		return fmt.Sprintf(capacitorConfigNameFormat, strings.Replace(platform, ",", "-", -1))
+	}
+	return fmt.Sprintf(configNameFormat, integration)
+}
+
+// hasWebBuild returns whether the web assets of the given project type are built,
+// the Ionic 1 projects serve the www directory as it is.
+func hasWebBuild(projectType string) bool {
+	return projectType != ionic1ProjectType
+}
+
+//------------------
+// ScannerInterface
+//------------------
+
+// Scanner ...
+type Scanner struct {
+	fs             filesystem.FileSystem
+	ionicConfigPth string
+	ionicConfig    utility.IonicConfigModel
+	integration    utility.IonicIntegration
+	packages       utility.PackagesModel
+
+	relProjectDir string
+	testRunner    cordova.TestRunner
+	hasYarnLock   bool
+
+	explanation models.Explanation
+	logger      logger.Logger
+}
+
+// NewScanner ...
+func NewScanner() *Scanner {
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
+}
+
+// Name ...
+func (scanner Scanner) Name() string {
+	return ScannerName
+}
+
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
+// detectIntegration returns the native runtime of the Ionic project in the given dir,
+// Capacitor is preferred, as the Cordova config.xml can be left behind by a migrated project.
+func detectIntegration(fs filesystem.FileSystem, projectDir string, config utility.IonicConfigModel, packages utility.PackagesModel) (utility.IonicIntegration, error) {
+	if config.HasIntegration(utility.IonicCapacitorIntegration) || packages.HasDependency(capacitorCoreDependency) {
+		return utility.IonicCapacitorIntegration, nil
+	}
+	if exist, err := filesystem.IsPathExists(fs, filepath.Join(projectDir, "capacitor.config.json")); err != nil {
+		return "", err
+	} else if exist {
+		return utility.IonicCapacitorIntegration, nil
+	}
+
+	if config.HasIntegration(utility.IonicCordovaIntegration) {
+		return utility.IonicCordovaIntegration, nil
+	}
+	if exist, err := filesystem.IsPathExists(fs, filepath.Join(projectDir, "config.xml")); err != nil {
+		return "", err
+	} else if exist {
+		return utility.IonicCordovaIntegration, nil
+	}
+
+	return "", nil
+}
+
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	fs := fileIndex.FS()
+
+	// Search for ionic.config.json file
+	scanner.logger.Infoft("Searching for ionic.config.json files")
+
+	// ionic.project is the project file of the Ionic 1 cli
+	candidates := append(fileIndex.ByBase("ionic.config.json"), fileIndex.ByBase("ionic.project")...)
+	scanner.explanation = models.NewExplanation("ionic.config.json (or ionic.project) of a Cordova or Capacitor based Ionic project", candidates...)
+
+	forbidNodeModulesFilter := utility.ComponentFilter(nodeModulesDirName, false)
+
+	for _, pth := range candidates {
+		if allowed, err := forbidNodeModulesFilter(pth); err != nil {
+			return false, err
+		} else if !allowed {
+			scanner.explanation.Reject(pth, "inside a node_modules directory")
+			continue
+		}
+
+		if scanner.ionicConfigPth != "" {
+			scanner.explanation.Reject(pth, fmt.Sprintf("an Ionic project is already found: %s", scanner.ionicConfigPth))
+			continue
+		}
+
+		config, err := utility.ParseIonicConfig(fs, pth)
+		if err != nil {
+			scanner.explanation.Reject(pth, fmt.Sprintf("can not parse: %s", err))
+			continue
+		}
+
+		projectDir := filepath.Dir(pth)
+
+		// the package.json is optional, the Ionic 1 projects may not have one
+		packages := utility.PackagesModel{}
+		packagesJSONPth := filepath.Join(projectDir, "package.json")
+		if exist, err := filesystem.IsPathExists(fs, packagesJSONPth); err != nil {
+			return false, err
+		} else if exist {
+			if packages, err = utility.ParsePackagesJSON(fs, packagesJSONPth); err != nil {
+				scanner.explanation.Reject(pth, fmt.Sprintf("can not parse %s: %s", packagesJSONPth, err))
+				continue
+			}
+		}
+
+		integration, err := detectIntegration(fs, projectDir, config, packages)
+		if err != nil {
+			return false, fmt.Errorf("failed to detect the integration of the ionic project, error: %s", err)
+		}
+		if integration == "" {
+			scanner.explanation.Reject(pth, "neither Cordova nor Capacitor integration found")
+			continue
+		}
+
+		scanner.ionicConfigPth = pth
+		scanner.ionicConfig = config
+		scanner.integration = integration
+		scanner.packages = packages
+	}
+
+	scanner.logger.Printft("ionic.config.json: %s", scanner.ionicConfigPth)
+
+	if scanner.ionicConfigPth == "" {
+		scanner.explanation.Reason = "no Cordova or Capacitor based Ionic project found"
+		scanner.logger.Printft("platform not detected")
+		return false, nil
+	}
+
+	scanner.logger.Printft("project type: %s, integration: %s", scanner.ionicConfig.Type, scanner.integration)
+
+	hasYarnLock, err := filesystem.IsPathExists(fs, filepath.Join(filepath.Dir(scanner.ionicConfigPth), "yarn.lock"))
+	if err != nil {
+		return false, err
+	}
+
+	scanner.explanation.Reason = fmt.Sprintf("%s based Ionic project found: %s", scanner.integration, scanner.ionicConfigPth)
+	scanner.logger.Doneft("Platform detected")
+
+	scanner.fs = fs
+	scanner.hasYarnLock = hasYarnLock
+
+	return true, nil
+}
+
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
+
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "Ionic project file found", scanner.ionicConfigPth),
+		models.DepthEvidence(scanner.ionicConfigPth),
+	}
+
+	projectDir := filepath.Dir(scanner.ionicConfigPth)
+	for _, supportingFile := range []struct {
+		name        string
+		description string
+	}{
+		{"config.xml", "Cordova config.xml found"},
+		{"capacitor.config.json", "Capacitor config found"},
+		{"package.json", "package.json found"},
+		{"package-lock.json", "npm lock file found"},
+		{"yarn.lock", "yarn lock file found"},
+	} {
+		pth := filepath.Join(projectDir, supportingFile.name)
+		if exist, err := filesystem.IsPathExists(scanner.fs, pth); err == nil && exist {
+			evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, supportingFile.description, pth))
+		}
+	}
+
+	return models.NewConfidence(evidence...)
+}
+
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	// the Cordova based projects are Cordova projects too,
+	// the native projects (platforms/ios, platforms/android or ios, android) are generated by the Ionic cli
+	overriddenScannerNames := []string{
+		cordova.ScannerName,
+		string(utility.XcodeProjectTypeIOS),
+		string(utility.XcodeProjectTypeMacOS),
+		android.ScannerName,
+	}
+
+	return models.ScannerRelationships{
+		Precedes:      overriddenScannerNames,
+		ConflictsWith: overriddenScannerNames,
+	}
+}
+
+// ClaimedPaths returns the native project directories and the Cordova config.xml of the detected project,
+// they are hidden from the conflicting scanners.
+func (scanner *Scanner) ClaimedPaths() []string {
+	projectDir := filepath.Dir(scanner.ionicConfigPth)
+	return []string{
+		filepath.Join(projectDir, androidDirName),
+		filepath.Join(projectDir, iosDirName),
+		filepath.Join(projectDir, platformsDirName),
+		filepath.Join(projectDir, "config.xml"),
+	}
+}
+
+// Options ...
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
+	projectDir := filepath.Dir(scanner.ionicConfigPth)
+
+	testRunner, err := cordova.DetectTestRunner(scanner.fs, projectDir, scanner.packages, scanner.logger)
+	if err != nil {
+		return models.OptionModel{}, warnings, err
+	}
+	scanner.testRunner = testRunner
+
+	// Get relative project dir, the indexed paths are relative to the search dir
+	relProjectDir := projectDir
+	if relProjectDir == "." {
+		// ionic.config.json placed in the search dir, no need to change-dir in the workflows
+		relProjectDir = ""
+	}
+	scanner.relProjectDir = relProjectDir
+	// ---
+
+	// Options
+	var rootOption *models.OptionModel
+	platformOption := models.NewOption(platformInputTitle, platformInputEnvKey)
+
+	if relProjectDir != "" {
+		rootOption = models.NewOption(workDirInputTitle, workDirInputEnvKey)
+		rootOption.AddOption(relProjectDir, platformOption)
+	} else {
+		rootOption = platformOption
+	}
+
+	for _, platform := range platforms {
+		configOption := models.NewConfigOption(configName(scanner.integration, platform))
+		platformOption.AddConfig(platform, configOption)
+	}
+	// ---
+
+	return *rootOption, warnings, nil
+}
+
+// DefaultOptions ...
+func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	workDirOption := models.NewOption(workDirInputTitle, workDirInputEnvKey)
+
+	platformOption := models.NewOption(platformInputTitle, platformInputEnvKey)
+	workDirOption.AddOption("_", platformOption)
+
+	for _, platform := range platforms {
+		configOption := models.NewConfigOption(defaultConfigName)
+		platformOption.AddConfig(platform, configOption)
+	}
+
+	return *workDirOption
+}
+
+// cordovaBuildStepList returns the steps preparing the Cordova platforms and archiving them with the Ionic cli.
+func cordovaBuildStepList(workdirEnvList []envmanModels.EnvironmentItemModel) []bitriseModels.StepListItemModel {
+	ionicArchiveEnvs := []envmanModels.EnvironmentItemModel{
+		envmanModels.EnvironmentItemModel{platformInputKey: "$" + platformInputEnvKey},
+		envmanModels.EnvironmentItemModel{targetInputKey: targetEmulator},
+	}
+	ionicArchiveEnvs = append(ionicArchiveEnvs, workdirEnvList...)
+
+	return []bitriseModels.StepListItemModel{
+		steps.GenerateCordovaBuildConfigStepListItem(),
+		steps.IonicArchiveStepListItem(ionicArchiveEnvs...),
+	}
+}
+
+// capacitorBuildStepList returns the steps building the web assets, syncing them into the Capacitor native projects
+// and building the native projects of the given platform (ios, android or both).
+func capacitorBuildStepList(packageManagerStepListItem func(...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel, workdirEnvList []envmanModels.EnvironmentItemModel, webBuild bool, platform string) []bitriseModels.StepListItemModel {
+	stepList := []bitriseModels.StepListItemModel{}
+
+	if webBuild {
+		buildEnvs := append([]envmanModels.EnvironmentItemModel{}, workdirEnvList...)
+		buildEnvs = append(buildEnvs, envmanModels.EnvironmentItemModel{commandInputKey: "run build"})
+		stepList = append(stepList, packageManagerStepListItem(buildEnvs...))
+	}
+
+	// the native projects are placed in the project dir
+	nativePth := func(pth string) string {
+		if len(workdirEnvList) > 0 {
+			return "$" + workDirInputEnvKey + "/" + pth
+		}
+		return pth
+	}
+
+	syncEnvs := []envmanModels.EnvironmentItemModel{}
+	if len(workdirEnvList) > 0 {
+		syncEnvs = append(syncEnvs, envmanModels.EnvironmentItemModel{scriptWorkingDirInputKey: "$" + workDirInputEnvKey})
+	}
+	syncEnvs = append(syncEnvs, envmanModels.EnvironmentItemModel{contentInputKey: capacitorSyncContent})
+	stepList = append(stepList, steps.ScriptSteplistItem(capacitorSyncTitle, syncEnvs...))
+
+	for _, nativePlatform := range strings.Split(platform, ",") {
+		switch nativePlatform {
+		case "android":
+			stepList = append(stepList,
+				steps.InstallMissingAndroidToolsStepListItem(),
+				steps.GradleRunnerStepListItem(
+					envmanModels.EnvironmentItemModel{gradleFileInputKey: nativePth(androidDirName + "/build.gradle")},
+					envmanModels.EnvironmentItemModel{gradleTaskInputKey: capacitorGradleTask},
+					envmanModels.EnvironmentItemModel{gradlewPathInputKey: nativePth(androidDirName + "/gradlew")},
+				),
+			)
+		case "ios":
+			stepList = append(stepList,
+				steps.CertificateAndProfileInstallerStepListItem(),
+				steps.XcodeArchiveStepListItem(
+					envmanModels.EnvironmentItemModel{xcode.ProjectPathInputKey: nativePth(capacitorWorkspacePth)},
+					envmanModels.EnvironmentItemModel{xcode.SchemeInputKey: capacitorScheme},
+				),
+			)
+		}
+	}
+
+	return stepList
+}
+
+func generateConfig(integration utility.IonicIntegration, projectType, platform string, testRunner cordova.TestRunner, useYarn bool, workdirEnvList []envmanModels.EnvironmentItemModel) (string, error) {
+	configBuilder := models.NewDefaultConfigBuilder()
+
+	// the Ionic cli (through the ionic-archive step) installs the dependencies of the Cordova based projects
+	packageManagerStepListItem := steps.NpmStepListItem
+	if useYarn {
+		packageManagerStepListItem = steps.YarnStepListItem
+	}
+
+	dependencyStepList := []bitriseModels.StepListItemModel{}
+	buildStepList := cordovaBuildStepList(workdirEnvList)
+	if integration == utility.IonicCapacitorIntegration {
+		installEnvs := append([]envmanModels.EnvironmentItemModel{}, workdirEnvList...)
+		installEnvs = append(installEnvs, envmanModels.EnvironmentItemModel{commandInputKey: "install"})
+
+		dependencyStepList = append(dependencyStepList, packageManagerStepListItem(installEnvs...))
+		buildStepList = capacitorBuildStepList(packageManagerStepListItem, workdirEnvList, hasWebBuild(projectType), platform)
+	}
+
+	configBuilder.AppendDependencyStepList(dependencyStepList...)
+
+	if testStep, ok := cordova.TestRunnerStepListItem(testRunner, workdirEnvList...); ok {
+		// CI
+		configBuilder.AppendMainStepList(testStep)
+
+		// CD
+		configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
+
+		configBuilder.AppendDependencyStepListTo(models.DeployWorkflowID, dependencyStepList...)
+		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, testStep)
+		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, buildStepList...)
+	} else {
+		configBuilder.AppendMainStepList(buildStepList...)
+	}
+
+	config, err := configBuilder.Generate(ScannerName)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Configs ...
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
+	workdirEnvList := []envmanModels.EnvironmentItemModel{}
+	if scanner.relProjectDir != "" {
+		workdirEnvList = append(workdirEnvList, envmanModels.EnvironmentItemModel{workDirInputKey: "$" + workDirInputEnvKey})
+	}
+
+	configs := models.BitriseConfigMap{}
+	for _, platform := range platforms {
+		name := configName(scanner.integration, platform)
+		if _, ok := configs[name]; ok {
+			continue
+		}
+
+		config, err := generateConfig(scanner.integration, scanner.ionicConfig.Type, platform, scanner.testRunner, scanner.hasYarnLock, workdirEnvList)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+		configs[name] = config
+	}
+
+	return configs, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	workdirEnvList := []envmanModels.EnvironmentItemModel{
+		envmanModels.EnvironmentItemModel{workDirInputKey: "$" + workDirInputEnvKey},
+	}
+
+	config, err := generateConfig(utility.IonicCordovaIntegration, "", "", cordova.NoTestRunner, false, workdirEnvList)
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+
+	return models.BitriseConfigMap{
+		defaultConfigName: config,
+	}, nil
+}
@@ -1,6 +1,8 @@
 package ios
 
 import (
+	"context"
+	"github.com/bitrise-core/bitrise-init/logger"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners/xcode"
 	"github.com/bitrise-core/bitrise-init/utility"
@@ -12,13 +14,19 @@ import (
 
 // Scanner ...
 type Scanner struct {
-	searchDir         string
+	fileIndex         *utility.FileIndex
+	projectFiles      []string
 	configDescriptors []xcode.ConfigDescriptor
+
+	explanation models.Explanation
+	logger      logger.Logger
 }
 
 // NewScanner ...
 func NewScanner() *Scanner {
-	return &Scanner{}
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
 }
 
 // Name ...
@@ -26,26 +34,43 @@ func (scanner *Scanner) Name() string {
 	return string(utility.XcodeProjectTypeIOS)
 }
 
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
 // DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
-	scanner.searchDir = searchDir
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	scanner.fileIndex = fileIndex
 
-	detected, err := xcode.Detect(utility.XcodeProjectTypeIOS, searchDir)
+	projectFiles, explanation, err := xcode.DetectProjectFiles(utility.XcodeProjectTypeIOS, fileIndex, scanner.logger)
+	scanner.explanation = explanation
 	if err != nil {
 		return false, err
 	}
+	scanner.projectFiles = projectFiles
+
+	return len(projectFiles) > 0, nil
+}
+
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
 
-	return detected, nil
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	return xcode.Confidence(scanner.projectFiles, scanner.fileIndex)
 }
 
-// ExcludedScannerNames ...
-func (scanner *Scanner) ExcludedScannerNames() []string {
-	return []string{}
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	return models.ScannerRelationships{}
 }
 
 // Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
-	options, configDescriptors, warnings, err := xcode.GenerateOptions(utility.XcodeProjectTypeIOS, scanner.searchDir)
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	options, configDescriptors, warnings, err := xcode.GenerateOptions(ctx, utility.XcodeProjectTypeIOS, scanner.fileIndex, scanner.logger)
 	if err != nil {
 		return models.OptionModel{}, warnings, err
 	}
@@ -61,7 +86,7 @@ func (scanner *Scanner) DefaultOptions() models.OptionModel {
 }
 
 // Configs ...
-func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
 	return xcode.GenerateConfig(utility.XcodeProjectTypeIOS, scanner.configDescriptors)
 }
 
@@ -0,0 +1,145 @@
+package scanners
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Plan is the resolved order of the scanners, based on their relationships.
+// The preceding and the augmented scanners come first, otherwise the scanners keep their original order.
+type Plan struct {
+	Scanners []ScannerInterface
+
+	overriddenByMap map[string][]string
+}
+
+// NewPlan resolves the relationships of the given scanners into a plan.
+// Relationships with scanners, which are not part of the plan, are ignored.
+// Returns error if the relationships contain a cycle or an unresolved conflict.
+func NewPlan(projectScanners []ScannerInterface) (*Plan, error) {
+	indexMap := map[string]int{}
+	for i, scanner := range projectScanners {
+		indexMap[scanner.Name()] = i
+	}
+
+	// successors[i] lists the scanners, which should be resolved after the i-th scanner
+	successors := make([]map[int]bool, len(projectScanners))
+	for i := range projectScanners {
+		successors[i] = map[int]bool{}
+	}
+	conflicts := map[[2]int]bool{}
+	augments := map[[2]int]bool{}
+
+	pair := func(i, j int) [2]int {
+		if i > j {
+			i, j = j, i
+		}
+		return [2]int{i, j}
+	}
+
+	for i, scanner := range projectScanners {
+		relationships := scanner.Relationships()
+
+		for _, name := range relationships.Precedes {
+			if j, ok := indexMap[name]; ok && j != i {
+				successors[i][j] = true
+			}
+		}
+		for _, name := range relationships.Augments {
+			if j, ok := indexMap[name]; ok && j != i {
+				successors[j][i] = true
+				augments[pair(i, j)] = true
+			}
+		}
+		for _, name := range relationships.ConflictsWith {
+			if j, ok := indexMap[name]; ok && j != i {
+				conflicts[pair(i, j)] = true
+			}
+		}
+	}
+
+	// topological order, the ready scanner with the lowest original index comes first
+	inDegrees := make([]int, len(projectScanners))
+	for i := range projectScanners {
+		for j := range successors[i] {
+			inDegrees[j]++
+		}
+	}
+
+	order := []int{}
+	resolved := make([]bool, len(projectScanners))
+	for len(order) < len(projectScanners) {
+		next := -1
+		for i := range projectScanners {
+			if !resolved[i] && inDegrees[i] == 0 {
+				next = i
+				break
+			}
+		}
+
+		if next == -1 {
+			names := []string{}
+			for i, scanner := range projectScanners {
+				if !resolved[i] {
+					names = append(names, scanner.Name())
+				}
+			}
+			return nil, fmt.Errorf("scanner relationships contain a cycle between: %s", strings.Join(names, ", "))
+		}
+
+		resolved[next] = true
+		order = append(order, next)
+		for j := range successors[next] {
+			inDegrees[j]--
+		}
+	}
+
+	// reachable[i][j] reports whether the i-th scanner is resolved before the j-th scanner
+	reachable := make([]map[int]bool, len(projectScanners))
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		reachable[i] = map[int]bool{}
+		for j := range successors[i] {
+			reachable[i][j] = true
+			for reachableFromJ := range reachable[j] {
+				reachable[i][reachableFromJ] = true
+			}
+		}
+	}
+
+	overriddenByMap := map[string][]string{}
+	for k := range order {
+		for l := k + 1; l < len(order); l++ {
+			i, j := order[k], order[l]
+			if !conflicts[pair(i, j)] {
+				continue
+			}
+
+			nameI, nameJ := projectScanners[i].Name(), projectScanners[j].Name()
+			if augments[pair(i, j)] {
+				return nil, fmt.Errorf("scanner (%s) can not both augment and conflict with scanner (%s)", nameI, nameJ)
+			}
+			if !reachable[i][j] {
+				return nil, fmt.Errorf("conflicting scanners (%s, %s) have no precedence", nameI, nameJ)
+			}
+
+			overriddenByMap[nameJ] = append(overriddenByMap[nameJ], nameI)
+		}
+	}
+
+	plan := &Plan{
+		Scanners:        make([]ScannerInterface, len(order)),
+		overriddenByMap: overriddenByMap,
+	}
+	for k, i := range order {
+		plan.Scanners[k] = projectScanners[i]
+	}
+
+	return plan, nil
+}
+
+// OverriddenBy returns the preceding, conflicting scanners of the given scanner.
+// If any of them detects the project (and its output is kept), the given scanner's output is dropped.
+func (plan *Plan) OverriddenBy(scannerName string) []string {
+	return plan.overriddenByMap[scannerName]
+}
@@ -0,0 +1,136 @@
+package scanners
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/utility"
+	"github.com/stretchr/testify/require"
+)
+
+type relationshipsScanner struct {
+	name          string
+	relationships models.ScannerRelationships
+}
+
+func (scanner relationshipsScanner) Name() string {
+	return scanner.name
+}
+
+func (scanner relationshipsScanner) SetLogger(logger logger.Logger) {}
+
+func (scanner relationshipsScanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	return false, nil
+}
+
+func (scanner relationshipsScanner) Explanation() models.Explanation {
+	return models.Explanation{}
+}
+
+func (scanner relationshipsScanner) Confidence() models.Confidence {
+	return models.Confidence{}
+}
+
+func (scanner relationshipsScanner) Relationships() models.ScannerRelationships {
+	return scanner.relationships
+}
+
+func (scanner relationshipsScanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	return models.OptionModel{}, nil, nil
+}
+
+func (scanner relationshipsScanner) DefaultOptions() models.OptionModel {
+	return models.OptionModel{}
+}
+
+func (scanner relationshipsScanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
+	return models.BitriseConfigMap{}, nil
+}
+
+func (scanner relationshipsScanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	return models.BitriseConfigMap{}, nil
+}
+
+func TestNewPlan(t *testing.T) {
+	t.Log("built-in scanners")
+	{
+		plan, err := NewPlan(NewScanners())
+		require.NoError(t, err)
+		require.Equal(t, []string{"ionic", "cordova", "react-native", "flutter", "unity", "ios", "macos", "android", "xamarin", "fastlane", "spm"}, scannerNames(plan.Scanners))
+		require.Equal(t, []string{"ionic", "cordova", "react-native", "flutter", "unity"}, plan.OverriddenBy("ios"))
+		require.Equal(t, []string{"ionic", "cordova", "react-native", "flutter", "unity"}, plan.OverriddenBy("android"))
+		require.Equal(t, 0, len(plan.OverriddenBy("fastlane")))
+		require.Equal(t, 0, len(plan.OverriddenBy("spm")))
+		require.Equal(t, []string{"ionic"}, plan.OverriddenBy("cordova"))
+		require.Equal(t, 0, len(plan.OverriddenBy("ionic")))
+		require.Equal(t, 0, len(plan.OverriddenBy("react-native")))
+	}
+
+	t.Log("the plan does not depend on the scanners order")
+	{
+		projectScanners := NewScanners()
+		reversed := []ScannerInterface{}
+		for i := len(projectScanners) - 1; i >= 0; i-- {
+			reversed = append(reversed, projectScanners[i])
+		}
+
+		plan, err := NewPlan(reversed)
+		require.NoError(t, err)
+		require.Equal(t, []string{"fastlane", "xamarin", "unity", "flutter", "react-native", "ionic", "cordova", "android", "macos", "ios", "spm"}, scannerNames(plan.Scanners))
+		require.Equal(t, []string{"unity", "flutter", "react-native", "ionic", "cordova"}, plan.OverriddenBy("ios"))
+		require.Equal(t, []string{"ionic", "cordova"}, plan.OverriddenBy("macos"))
+		require.Equal(t, []string{"unity", "flutter", "react-native", "ionic", "cordova"}, plan.OverriddenBy("android"))
+		require.Equal(t, []string{"ionic"}, plan.OverriddenBy("cordova"))
+	}
+
+	t.Log("transitive precedence resolves the conflict")
+	{
+		plan, err := NewPlan([]ScannerInterface{
+			relationshipsScanner{name: "c", relationships: models.ScannerRelationships{ConflictsWith: []string{"a"}}},
+			relationshipsScanner{name: "b", relationships: models.ScannerRelationships{Precedes: []string{"c"}}},
+			relationshipsScanner{name: "a", relationships: models.ScannerRelationships{Precedes: []string{"b"}}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, scannerNames(plan.Scanners))
+		require.Equal(t, []string{"a"}, plan.OverriddenBy("c"))
+	}
+
+	t.Log("relationships with not planned scanners are ignored")
+	{
+		plan, err := NewPlan([]ScannerInterface{
+			relationshipsScanner{name: "a", relationships: models.ScannerRelationships{ConflictsWith: []string{"x"}, Augments: []string{"y"}}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a"}, scannerNames(plan.Scanners))
+	}
+
+	t.Log("cycle")
+	{
+		_, err := NewPlan([]ScannerInterface{
+			relationshipsScanner{name: "a", relationships: models.ScannerRelationships{Precedes: []string{"b"}}},
+			relationshipsScanner{name: "b"},
+			relationshipsScanner{name: "c", relationships: models.ScannerRelationships{Augments: []string{"b"}, Precedes: []string{"a"}}},
+		})
+		require.EqualError(t, err, "scanner relationships contain a cycle between: a, b, c")
+	}
+
+	t.Log("conflict without precedence")
+	{
+		_, err := NewPlan([]ScannerInterface{
+			relationshipsScanner{name: "a", relationships: models.ScannerRelationships{ConflictsWith: []string{"b"}}},
+			relationshipsScanner{name: "b"},
+		})
+		require.EqualError(t, err, "conflicting scanners (a, b) have no precedence")
+	}
+
+	t.Log("augmenting and conflicting scanner")
+	{
+		_, err := NewPlan([]ScannerInterface{
+			relationshipsScanner{name: "a", relationships: models.ScannerRelationships{ConflictsWith: []string{"b"}}},
+			relationshipsScanner{name: "b", relationships: models.ScannerRelationships{Augments: []string{"a"}}},
+		})
+		require.EqualError(t, err, "scanner (a) can not both augment and conflict with scanner (b)")
+	}
+}
@@ -0,0 +1,355 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/utility"
+)
+
+// ProtocolVersion is the version of the JSON protocol, used to communicate with the plugin executables.
+// The plugin has to respond with the same version, it received in the request.
+const ProtocolVersion = "1"
+
+// ExecutablePrefix is the required name prefix of the plugin executables, discovered in the plugin dirs.
+const ExecutablePrefix = "bitrise-init-scanner-"
+
+// Commands of the protocol, every command corresponds to a ScannerInterface method.
+const (
+	NameCommand           = "name"
+	DetectPlatformCommand = "detect_platform"
+	RelationshipsCommand  = "relationships"
+	OptionsCommand        = "options"
+	DefaultOptionsCommand = "default_options"
+	ConfigsCommand        = "configs"
+	DefaultConfigsCommand = "default_configs"
+)
+
+// Request is written to the plugin executable's standard input, as a single JSON object.
+type Request struct {
+	ProtocolVersion string `json:"protocol_version"`
+	Command         string `json:"command"`
+
+	// SearchDir is the absolute path of the scanned directory, it is empty for the name and default_* commands.
+	SearchDir string `json:"search_dir,omitempty"`
+	// FileList is the list of not ignored paths (relative to the SearchDir), sent along with the detect_platform command.
+	FileList []string `json:"file_list,omitempty"`
+}
+
+// Response is read from the plugin executable's standard output, as a single JSON object.
+// The plugin's standard error output is forwarded to the scanner log.
+type Response struct {
+	ProtocolVersion string `json:"protocol_version"`
+
+	Name          string                       `json:"name,omitempty"`
+	Relationships *models.ScannerRelationships `json:"relationships,omitempty"`
+	Detected      bool                         `json:"detected,omitempty"`
+	// Confidence is the optional detection confidence, sent along with the detect_platform response.
+	Confidence *models.Confidence `json:"confidence,omitempty"`
+	// Explanation is the optional explanation of the verdict, sent along with the detect_platform response.
+	Explanation *models.Explanation     `json:"explanation,omitempty"`
+	Options     *models.OptionModel     `json:"options,omitempty"`
+	Diagnostics models.Diagnostics      `json:"diagnostics,omitempty"`
+	Configs     models.BitriseConfigMap `json:"configs,omitempty"`
+
+	// Warnings is the legacy list of free-form warnings, prefer Diagnostics.
+	Warnings models.Warnings `json:"warnings,omitempty"`
+
+	// Error is the error message of the failed command.
+	Error string `json:"error,omitempty"`
+}
+
+// PluginWarningCode is the code of the free-form warnings, reported by a plugin.
+const PluginWarningCode = "plugin-warning"
+
+// AllDiagnostics returns the response's diagnostics, including the legacy free-form warnings.
+func (response Response) AllDiagnostics() models.Diagnostics {
+	return append(append(models.Diagnostics{}, response.Diagnostics...), models.DiagnosticsFromWarnings(PluginWarningCode, response.Warnings)...)
+}
+
+//--------------------------------------------------
+// Scanner
+//--------------------------------------------------
+
+// Scanner runs an external plugin executable for every ScannerInterface method.
+type Scanner struct {
+	executablePth string
+	name          string
+	relationships models.ScannerRelationships
+	searchDir     string
+	confidence    *models.Confidence
+	explanation   models.Explanation
+
+	logger logger.Logger
+}
+
+// handshakeTimeout is the time budget of the plugin commands, which are not part of a scan (like the name query),
+// a plugin hanging in these commands would block the caller otherwise.
+var handshakeTimeout = utility.DefaultSubprocessTimeout
+
+// handshakeContext returns the context of the plugin commands, which are not part of a scan.
+func handshakeContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), handshakeTimeout)
+}
+
+// NewScanner creates a scanner for the given plugin executable and queries its name and relationships.
+// The queries are limited by the handshakeTimeout.
+func NewScanner(executablePth string) (*Scanner, error) {
+	absExecutablePth, err := filepath.Abs(executablePth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand plugin path (%s), error: %s", executablePth, err)
+	}
+
+	scanner := &Scanner{
+		executablePth: absExecutablePth,
+		logger:        logger.NewDefaultLogger(),
+	}
+
+	ctx, cancel := handshakeContext()
+	defer cancel()
+
+	response, err := scanner.run(ctx, Request{Command: NameCommand})
+	if err != nil {
+		return nil, err
+	}
+	if response.Name == "" {
+		return nil, fmt.Errorf("plugin (%s) returned empty name", executablePth)
+	}
+
+	scanner.name = response.Name
+
+	response, err = scanner.run(ctx, Request{Command: RelationshipsCommand})
+	if err != nil {
+		return nil, err
+	}
+	if response.Relationships != nil {
+		scanner.relationships = *response.Relationships
+	}
+
+	return scanner, nil
+}
+
+// Discover returns the plugin executables, found in the given dirs.
+// The executables are sorted by name, within each dir.
+func Discover(pluginDirs []string) ([]string, error) {
+	executablePths := []string{}
+	for _, dir := range pluginDirs {
+		if dir == "" {
+			continue
+		}
+
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list plugin dir (%s), error: %s", dir, err)
+		}
+
+		sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+
+		for _, info := range infos {
+			if info.IsDir() || !strings.HasPrefix(info.Name(), ExecutablePrefix) {
+				continue
+			}
+			if info.Mode()&0111 == 0 {
+				continue
+			}
+
+			executablePths = append(executablePths, filepath.Join(dir, info.Name()))
+		}
+	}
+	return executablePths, nil
+}
+
+func (scanner *Scanner) run(ctx context.Context, request Request) (Response, error) {
+	request.ProtocolVersion = ProtocolVersion
+
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd, cmdCtx, cancel := utility.NewCommandContext(ctx, scanner.executablePth)
+	defer cancel()
+
+	cmd.SetStdin(bytes.NewReader(requestBytes)).
+		SetStdout(&stdout).
+		SetStderr(&stderr)
+	runErr := cmd.Run()
+	if runErr != nil && cmdCtx.Err() != nil {
+		runErr = utility.CommandContextError(cmdCtx, cmd, runErr)
+	}
+
+	lineScanner := bufio.NewScanner(&stderr)
+	for lineScanner.Scan() {
+		scanner.logger.Printft("%s", lineScanner.Text())
+	}
+
+	if runErr != nil {
+		return Response{}, fmt.Errorf("plugin (%s) failed to run command (%s), error: %s", scanner.executablePth, request.Command, runErr)
+	}
+
+	var response Response
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return Response{}, fmt.Errorf("failed to parse plugin (%s) response to command (%s), error: %s", scanner.executablePth, request.Command, err)
+	}
+
+	if response.ProtocolVersion != ProtocolVersion {
+		return Response{}, fmt.Errorf("plugin (%s) protocol version (%s) does not match the supported version (%s)", scanner.executablePth, response.ProtocolVersion, ProtocolVersion)
+	}
+
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+
+	return response, nil
+}
+
+// Name ...
+func (scanner Scanner) Name() string {
+	return scanner.name
+}
+
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	// the plugins read the project files directly, they can not scan in-memory or archived trees
+	scanner.searchDir = fileIndex.Root()
+	if scanner.searchDir == "" {
+		return false, fmt.Errorf("plugin scanner (%s) can only scan a directory on the disk", scanner.name)
+	}
+
+	scanner.logger.Infoft("Running plugin: %s", scanner.executablePth)
+
+	fileList := []string{}
+	for _, pth := range fileIndex.Paths() {
+		if pth != "." {
+			fileList = append(fileList, pth)
+		}
+	}
+
+	response, err := scanner.run(ctx, Request{
+		Command:   DetectPlatformCommand,
+		SearchDir: scanner.searchDir,
+		FileList:  fileList,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	scanner.explanation = models.Explanation{LookedFor: "the project files known by the plugin"}
+	if response.Explanation != nil {
+		scanner.explanation = *response.Explanation
+	}
+	if scanner.explanation.Reason == "" {
+		scanner.explanation.Reason = "the plugin did not detect the platform"
+		if response.Detected {
+			scanner.explanation.Reason = "the plugin detected the platform"
+		}
+	}
+
+	if !response.Detected {
+		scanner.logger.Printft("platform not detected")
+		return false, nil
+	}
+
+	scanner.logger.Doneft("Platform detected")
+
+	scanner.confidence = response.Confidence
+
+	return true, nil
+}
+
+// Confidence returns the confidence reported by the plugin,
+// if the plugin does not report it, the detection weighs as a found project file.
+func (scanner *Scanner) Confidence() models.Confidence {
+	if scanner.confidence == nil {
+		return models.NewConfidence(models.NewEvidence(models.ProjectFileWeight, "detected by the plugin"))
+	}
+	return scanner.confidence.Normalized()
+}
+
+// Explanation returns the explanation reported by the plugin, or a generic one if the plugin does not report it.
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
+
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	return scanner.relationships
+}
+
+// Options ...
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	response, err := scanner.run(ctx, Request{
+		Command:   OptionsCommand,
+		SearchDir: scanner.searchDir,
+	})
+	if err != nil {
+		return models.OptionModel{}, response.AllDiagnostics(), err
+	}
+
+	if response.Options == nil {
+		return models.OptionModel{}, response.AllDiagnostics(), errors.New("plugin returned no options")
+	}
+
+	return *response.Options, response.AllDiagnostics(), nil
+}
+
+// DefaultOptions ...
+func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	ctx, cancel := handshakeContext()
+	defer cancel()
+
+	response, err := scanner.run(ctx, Request{Command: DefaultOptionsCommand})
+	if err != nil {
+		scanner.logger.Warnft("Failed to get default options, error: %s", err)
+		return models.OptionModel{}
+	}
+
+	if response.Options == nil {
+		return models.OptionModel{}
+	}
+	return *response.Options
+}
+
+// Configs ...
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
+	response, err := scanner.run(ctx, Request{
+		Command:   ConfigsCommand,
+		SearchDir: scanner.searchDir,
+	})
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+	return response.Configs, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	ctx, cancel := handshakeContext()
+	defer cancel()
+
+	response, err := scanner.run(ctx, Request{Command: DefaultConfigsCommand})
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+	return response.Configs, nil
+}
@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/utility"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testPluginContent = `#!/bin/sh
+request=$(cat)
+case "$request" in
+  *'"command":"name"'*)
+    echo '{"protocol_version":"1","name":"custom"}' ;;
+  *'"command":"detect_platform"'*)
+    echo "Searching for build.custom" >&2
+    case "$request" in
+      *'build.custom'*) echo '{"protocol_version":"1","detected":true,"confidence":{"score":90,"evidence":[{"description":"build.custom found","weight":60,"paths":["build.custom"]}]},"explanation":{"looked_for":"build.custom files","candidates":["build.custom"],"reason":"build.custom found"}}' ;;
+      *) echo '{"protocol_version":"1"}' ;;
+    esac ;;
+  *'"command":"options"'*)
+    echo '{"protocol_version":"1","options":{"title":"Project","env_key":"PROJECT","value_map":{"build.custom":{"config":"custom-config"}}},"diagnostics":[{"code":"custom-no-lockfile","severity":"warning","paths":["build.custom"],"message":"no lockfile"}],"warnings":["custom warning"]}' ;;
+  *'"command":"configs"'*)
+    echo '{"protocol_version":"1","configs":{"custom-config":"format_version: 1.4.0"}}' ;;
+  *'"command":"relationships"'*)
+    echo '{"protocol_version":"1","relationships":{"precedes":["android"],"conflicts_with":["android"]}}' ;;
+  *)
+    echo '{"protocol_version":"1","error":"unknown command"}' ;;
+esac
+`
+
+func TestPluginScanner(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__plugin_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	pluginDir := filepath.Join(tmpDir, "plugins")
+	require.NoError(t, os.MkdirAll(pluginDir, 0700))
+
+	pluginPth := filepath.Join(pluginDir, ExecutablePrefix+"custom")
+	require.NoError(t, fileutil.WriteStringToFile(pluginPth, testPluginContent))
+	require.NoError(t, os.Chmod(pluginPth, 0700))
+
+	// not executable
+	require.NoError(t, fileutil.WriteStringToFile(filepath.Join(pluginDir, ExecutablePrefix+"readme"), ""))
+
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(projectDir, 0700))
+	require.NoError(t, fileutil.WriteStringToFile(filepath.Join(projectDir, "build.custom"), ""))
+
+	t.Log("discover")
+	{
+		pths, err := Discover([]string{pluginDir, filepath.Join(tmpDir, "not_exist")})
+		require.NoError(t, err)
+		require.Equal(t, []string{pluginPth}, pths)
+	}
+
+	scanner, err := NewScanner(pluginPth)
+	require.NoError(t, err)
+	require.Equal(t, "custom", scanner.Name())
+	require.Equal(t, models.ScannerRelationships{
+		Precedes:      []string{"android"},
+		ConflictsWith: []string{"android"},
+	}, scanner.Relationships())
+
+	log := logger.NewBufferedLogger()
+	scanner.SetLogger(log)
+
+	t.Log("detect platform")
+	{
+		fileIndex, err := utility.NewFileIndex(projectDir)
+		require.NoError(t, err)
+
+		detected, err := scanner.DetectPlatform(context.Background(), fileIndex)
+		require.NoError(t, err)
+		require.Equal(t, true, detected)
+		require.Contains(t, log.String(), "Searching for build.custom")
+
+		confidence := scanner.Confidence()
+		require.Equal(t, 60, confidence.Score)
+		require.Equal(t, []models.Evidence{models.NewEvidence(60, "build.custom found", "build.custom")}, confidence.Evidence)
+
+		require.Equal(t, models.Explanation{LookedFor: "build.custom files", Candidates: []string{"build.custom"}, Reason: "build.custom found"}, scanner.Explanation())
+	}
+
+	t.Log("options")
+	{
+		options, warnings, err := scanner.Options(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, models.Diagnostics{
+			models.NewWarning("custom-no-lockfile", "no lockfile", "build.custom"),
+			models.NewWarning(PluginWarningCode, "custom warning"),
+		}, warnings)
+		require.Equal(t, "PROJECT", options.EnvKey)
+		require.Equal(t, "custom-config", options.ChildOptionMap["build.custom"].Config)
+	}
+
+	t.Log("configs")
+	{
+		configs, err := scanner.Configs(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, models.BitriseConfigMap{"custom-config": "format_version: 1.4.0"}, configs)
+	}
+
+	t.Log("plugin error")
+	{
+		_, err := scanner.DefaultConfigs()
+		require.EqualError(t, err, "unknown command")
+	}
+}
+
+func TestNewScannerTimeout(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__plugin_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	pluginPth := filepath.Join(tmpDir, ExecutablePrefix+"hanging")
+	require.NoError(t, fileutil.WriteStringToFile(pluginPth, "#!/bin/sh\nexec sleep 60\n"))
+	require.NoError(t, os.Chmod(pluginPth, 0700))
+
+	originalTimeout := handshakeTimeout
+	handshakeTimeout = 100 * time.Millisecond
+	defer func() {
+		handshakeTimeout = originalTimeout
+	}()
+
+	t.Log("hanging plugin")
+	{
+		started := time.Now()
+		_, err := NewScanner(pluginPth)
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), "timed out"), err.Error())
+		require.True(t, time.Since(started) < 10*time.Second)
+	}
+}
@@ -0,0 +1,425 @@
+package reactnative
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners/android"
+	"github.com/bitrise-core/bitrise-init/scanners/xcode"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-tools/go-xcode/xcodeproj"
+)
+
+// ScannerName ...
+const ScannerName = "react-native"
+
+// Diagnostic codes of the scanner.
+const (
+	NoNativeProjectCode = "react-native-no-native-project"
+)
+
+const (
+	configNameFormat  = "react-native%s-config"
+	defaultConfigName = "default-react-native-config"
+)
+
+const (
+	reactNativeDependency = "react-native"
+	nodeModulesDirName    = "node_modules"
+	androidDirName        = "android"
+	iosDirName            = "ios"
+)
+
+// Step Inputs
+const (
+	commandInputKey = "command"
+	workDirInputKey = "workdir"
+)
+
+// configDescriptor describes a config of the scanner: the native projects it builds.
+type configDescriptor struct {
+	hasAndroid bool
+	// ios is the descriptor of the Xcode project's config, nil if the project has no ios app.
+	ios *xcode.ConfigDescriptor
+}
+
+func (descriptor configDescriptor) configName() string {
+	qualifiers := ""
+	if descriptor.hasAndroid {
+		qualifiers += "-android"
+	}
+	if descriptor.ios != nil {
+		qualifiers += "-ios"
+		if descriptor.ios.HasPodfile {
+			qualifiers += "-pod"
+		}
+		if descriptor.ios.CarthageCommand != "" {
+			qualifiers += "-carthage"
+		}
+		if descriptor.ios.MissingSharedSchemes {
+			qualifiers += "-missing-shared-schemes"
+		}
+	}
+	return fmt.Sprintf(configNameFormat, qualifiers)
+}
+
+//------------------
+// ScannerInterface
+//------------------
+
+// Scanner ...
+type Scanner struct {
+	fileIndex *utility.FileIndex
+
+	packageJSONPth   string
+	packages         utility.PackagesModel
+	buildGradleFiles []string
+	xcodeProjects    []string
+
+	hasYarnLock       bool
+	configDescriptors []configDescriptor
+
+	explanation models.Explanation
+	logger      logger.Logger
+}
+
+// NewScanner ...
+func NewScanner() *Scanner {
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
+}
+
+// Name ...
+func (scanner Scanner) Name() string {
+	return ScannerName
+}
+
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
+func (scanner *Scanner) projectDir() string {
+	return filepath.Dir(scanner.packageJSONPth)
+}
+
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	scanner.fileIndex = fileIndex
+	fs := fileIndex.FS()
+
+	// Search for package.json file
+	scanner.logger.Infoft("Searching for package.json files")
+
+	candidates := fileIndex.ByBase("package.json")
+	scanner.explanation = models.NewExplanation("package.json with a react-native dependency", candidates...)
+
+	forbidNodeModulesFilter := utility.ComponentFilter(nodeModulesDirName, false)
+
+	// the indexed paths are sorted by components, the shallowest project comes first
+	for _, pth := range candidates {
+		if allowed, err := forbidNodeModulesFilter(pth); err != nil {
+			return false, err
+		} else if !allowed {
+			scanner.explanation.Reject(pth, "inside a node_modules directory")
+			continue
+		}
+
+		if scanner.packageJSONPth != "" {
+			scanner.explanation.Reject(pth, fmt.Sprintf("a React Native project is already found: %s", scanner.packageJSONPth))
+			continue
+		}
+
+		packages, err := utility.ParsePackagesJSON(fs, pth)
+		if err != nil {
+			scanner.explanation.Reject(pth, fmt.Sprintf("can not parse: %s", err))
+			continue
+		}
+
+		if !packages.HasDependency(reactNativeDependency) {
+			scanner.explanation.Reject(pth, "no react-native dependency")
+			continue
+		}
+
+		scanner.packageJSONPth = pth
+		scanner.packages = packages
+	}
+
+	scanner.logger.Printft("package.json: %s", scanner.packageJSONPth)
+
+	if scanner.packageJSONPth == "" {
+		scanner.explanation.Reason = "no package.json with a react-native dependency found"
+		scanner.logger.Printft("platform not detected")
+		return false, nil
+	}
+
+	// Search for the native projects
+	buildGradleFiles, err := utility.FilterRootBuildGradleFiles(utility.PathsUnder(fileIndex.ByBase("build.gradle"), filepath.Join(scanner.projectDir(), androidDirName)))
+	if err != nil {
+		return false, fmt.Errorf("failed to search for build.gradle files, error: %s", err)
+	}
+	scanner.buildGradleFiles = buildGradleFiles
+
+	xcodeProjects, err := utility.FilterRelevantProjectFiles(fs, utility.PathsUnder(fileIndex.ByExtension(xcodeproj.XCodeProjExt), filepath.Join(scanner.projectDir(), iosDirName)), utility.XcodeProjectTypeIOS)
+	if err != nil {
+		return false, fmt.Errorf("failed to search for Xcode projects, error: %s", err)
+	}
+	scanner.xcodeProjects = xcodeProjects
+
+	scanner.logger.Printft("%d android build.gradle files, %d ios Xcode projects detected", len(buildGradleFiles), len(xcodeProjects))
+
+	scanner.hasYarnLock, err = filesystem.IsPathExists(fs, filepath.Join(scanner.projectDir(), "yarn.lock"))
+	if err != nil {
+		return false, err
+	}
+
+	scanner.explanation.Reason = fmt.Sprintf("React Native project found: %s", scanner.packageJSONPth)
+	scanner.logger.Doneft("Platform detected")
+
+	return true, nil
+}
+
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
+
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "package.json with a react-native dependency found", scanner.packageJSONPth),
+		models.DepthEvidence(scanner.packageJSONPth),
+	}
+
+	if len(scanner.buildGradleFiles) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "android project found", scanner.buildGradleFiles...))
+	}
+	if len(scanner.xcodeProjects) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "ios project found", scanner.xcodeProjects...))
+	}
+	if scanner.hasYarnLock {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "yarn lock file found", filepath.Join(scanner.projectDir(), "yarn.lock")))
+	}
+
+	return models.NewConfidence(evidence...)
+}
+
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	// the android and ios dirs hold the native projects of the react native app
+	nativeScannerNames := []string{
+		string(utility.XcodeProjectTypeIOS),
+		android.ScannerName,
+	}
+
+	return models.ScannerRelationships{
+		Precedes:      nativeScannerNames,
+		ConflictsWith: nativeScannerNames,
+	}
+}
+
+// ClaimedPaths returns the android and ios directories of the detected project,
+// the native projects in them are hidden from the conflicting scanners.
+func (scanner *Scanner) ClaimedPaths() []string {
+	return []string{
+		filepath.Join(scanner.projectDir(), androidDirName),
+		filepath.Join(scanner.projectDir(), iosDirName),
+	}
+}
+
+// Options ...
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
+	hasAndroid := len(scanner.buildGradleFiles) > 0
+	hasIOS := len(scanner.xcodeProjects) > 0
+
+	if !hasAndroid && !hasIOS {
+		scanner.logger.Errorft("No android or ios project found")
+		return models.OptionModel{}, warnings, models.NewErrorf(NoNativeProjectCode, "No native project found in the android or ios directory of the React Native project: %s", scanner.projectDir())
+	}
+
+	// Xcode project options, the config of every scheme is replaced with the react native config
+	var iosOptions *models.OptionModel
+	if hasIOS {
+		options, iosDescriptors, iosWarnings, err := xcode.GenerateOptionsUnder(ctx, utility.XcodeProjectTypeIOS, scanner.fileIndex, filepath.Join(scanner.projectDir(), iosDirName), scanner.logger)
+		warnings = append(warnings, iosWarnings...)
+		if err != nil {
+			return models.OptionModel{}, warnings, err
+		}
+
+		iosDescriptorMap := map[string]xcode.ConfigDescriptor{}
+		for _, descriptor := range iosDescriptors {
+			iosDescriptorMap[descriptor.ConfigName(utility.XcodeProjectTypeIOS)] = descriptor
+		}
+
+		descriptorMap := map[string]bool{}
+		for _, configOption := range options.LastChilds() {
+			iosDescriptor, ok := iosDescriptorMap[configOption.Config]
+			if !ok {
+				continue
+			}
+
+			descriptor := configDescriptor{hasAndroid: hasAndroid, ios: &iosDescriptor}
+			configOption.Config = descriptor.configName()
+
+			if !descriptorMap[descriptor.configName()] {
+				descriptorMap[descriptor.configName()] = true
+				scanner.configDescriptors = append(scanner.configDescriptors, descriptor)
+			}
+		}
+
+		iosOptions = &options
+	} else {
+		scanner.configDescriptors = append(scanner.configDescriptors, configDescriptor{hasAndroid: true})
+	}
+
+	if !hasAndroid {
+		return *iosOptions, warnings, nil
+	}
+
+	// Gradle project options, every gradle task is followed by the Xcode project options
+	options, androidWarnings, err := android.GradleOptions(scanner.fileIndex, filepath.Join(scanner.projectDir(), androidDirName), scanner.buildGradleFiles, scanner.logger, func() *models.OptionModel {
+		if iosOptions != nil {
+			return iosOptions.Copy()
+		}
+		return models.NewConfigOption(configDescriptor{hasAndroid: true}.configName())
+	})
+	warnings = append(warnings, androidWarnings...)
+	if err != nil {
+		return models.OptionModel{}, warnings, err
+	}
+
+	return options, warnings, nil
+}
+
+// DefaultOptions ...
+func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	iosOptions := xcode.GenerateDefaultOptions(utility.XcodeProjectTypeIOS)
+	for _, configOption := range iosOptions.LastChilds() {
+		configOption.Config = defaultConfigName
+	}
+
+	return android.DefaultGradleOptions(&iosOptions)
+}
+
+// installAndTestSteps returns the steps installing the javascript dependencies and running the tests (if the package has a test script),
+// with yarn, if the project has a yarn lock file, otherwise with npm.
+func installAndTestSteps(useYarn bool, hasTest bool, workDir string) []bitriseModels.StepListItemModel {
+	stepListItem := steps.NpmStepListItem
+	if useYarn {
+		stepListItem = steps.YarnStepListItem
+	}
+
+	inputs := func(command string) []envmanModels.EnvironmentItemModel {
+		inputs := []envmanModels.EnvironmentItemModel{}
+		if workDir != "." {
+			inputs = append(inputs, envmanModels.EnvironmentItemModel{workDirInputKey: workDir})
+		}
+		return append(inputs, envmanModels.EnvironmentItemModel{commandInputKey: command})
+	}
+
+	stepList := []bitriseModels.StepListItemModel{stepListItem(inputs("install")...)}
+	if hasTest {
+		stepList = append(stepList, stepListItem(inputs("test")...))
+	}
+	return stepList
+}
+
+func generateConfig(descriptor configDescriptor, useYarn, hasTest bool, workDir string) (bitriseModels.BitriseDataModel, error) {
+	configBuilder := models.NewDefaultConfigBuilder()
+	installAndTest := installAndTestSteps(useYarn, hasTest, workDir)
+
+	// CI
+	configBuilder.AppendDependencyStepList(installAndTest[0])
+	configBuilder.AppendMainStepList(installAndTest[1:]...)
+
+	// CD
+	configBuilder.AddDefaultWorkflowBuilder(models.DeployWorkflowID)
+
+	configBuilder.AppendDependencyStepListTo(models.DeployWorkflowID, installAndTest[0])
+	configBuilder.AppendMainStepListTo(models.DeployWorkflowID, installAndTest[1:]...)
+
+	if descriptor.hasAndroid {
+		configBuilder.AppendPreparStepListTo(models.DeployWorkflowID, steps.InstallMissingAndroidToolsStepListItem())
+		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, android.GradleRunnerStepListItem())
+	}
+
+	if descriptor.ios != nil {
+		projectPathInput := envmanModels.EnvironmentItemModel{xcode.ProjectPathInputKey: "$" + xcode.ProjectPathInputEnvKey}
+
+		configBuilder.AppendPreparStepListTo(models.DeployWorkflowID, steps.CertificateAndProfileInstallerStepListItem())
+		if descriptor.ios.MissingSharedSchemes {
+			configBuilder.AppendPreparStepListTo(models.DeployWorkflowID, steps.RecreateUserSchemesStepListItem(projectPathInput))
+		}
+
+		if descriptor.ios.HasPodfile {
+			configBuilder.AppendDependencyStepListTo(models.DeployWorkflowID, steps.CocoapodsInstallStepListItem())
+		}
+		if descriptor.ios.CarthageCommand != "" {
+			configBuilder.AppendDependencyStepListTo(models.DeployWorkflowID, steps.CarthageStepListItem(
+				envmanModels.EnvironmentItemModel{xcode.CarthageCommandInputKey: descriptor.ios.CarthageCommand},
+			))
+		}
+
+		configBuilder.AppendMainStepListTo(models.DeployWorkflowID, steps.XcodeArchiveStepListItem(
+			projectPathInput,
+			envmanModels.EnvironmentItemModel{xcode.SchemeInputKey: "$" + xcode.SchemeInputEnvKey},
+		))
+	}
+
+	return configBuilder.Generate(ScannerName)
+}
+
+// Configs ...
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
+	hasTest := scanner.packages.Scripts["test"] != ""
+
+	configMap := models.BitriseConfigMap{}
+	for _, descriptor := range scanner.configDescriptors {
+		config, err := generateConfig(descriptor, scanner.hasYarnLock, hasTest, scanner.projectDir())
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+
+		data, err := yaml.Marshal(config)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+
+		configMap[descriptor.configName()] = string(data)
+	}
+
+	return configMap, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	descriptor := configDescriptor{
+		hasAndroid: true,
+		ios:        &xcode.ConfigDescriptor{HasPodfile: true, MissingSharedSchemes: true},
+	}
+
+	config, err := generateConfig(descriptor, false, true, ".")
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+
+	return models.BitriseConfigMap{
+		defaultConfigName: string(data),
+	}, nil
+}
@@ -1,13 +1,25 @@
 package scanners
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/logger"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/scanners/android"
 	"github.com/bitrise-core/bitrise-init/scanners/cordova"
 	"github.com/bitrise-core/bitrise-init/scanners/fastlane"
+	"github.com/bitrise-core/bitrise-init/scanners/flutter"
+	"github.com/bitrise-core/bitrise-init/scanners/ionic"
 	"github.com/bitrise-core/bitrise-init/scanners/ios"
 	"github.com/bitrise-core/bitrise-init/scanners/macos"
+	"github.com/bitrise-core/bitrise-init/scanners/plugin"
+	"github.com/bitrise-core/bitrise-init/scanners/reactnative"
+	"github.com/bitrise-core/bitrise-init/scanners/spm"
+	"github.com/bitrise-core/bitrise-init/scanners/unity"
 	"github.com/bitrise-core/bitrise-init/scanners/xamarin"
+	"github.com/bitrise-core/bitrise-init/utility"
 	"gopkg.in/yaml.v2"
 )
 
@@ -21,16 +33,34 @@ type ScannerInterface interface {
 	// - the name of the scanner
 	Name() string
 
-	// Should implement as minimal logic as possible to determin if searchDir contains the - in question - platform or not.
+	// SetLogger sets the logger, which the scanner should use to print its outputs.
+	// The scanners run concurrently, their outputs are collected by the given logger and printed in groups,
+	// this means, that the scanner SHOULD NOT PRINT DIRECTLY to the standard output.
+	SetLogger(logger logger.Logger)
+
+	// Should implement as minimal logic as possible to determin if the search dir contains the - in question - platform or not.
 	// Inouts:
-	// - searchDir: the directory where the project to scann exists.
+	// - ctx: limits the scanner's run time, the external tool calls should be made with it (see: utility.NewCommandContext).
+	// - fileIndex: the index of the directory where the project to scann exists, it is shared between the scanners.
 	// Returns:
 	// - platform detected
 	// - error if (if any)
-	DetectPlatform(searchDir string) (bool, error)
+	DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error)
+
+	// Explanation describes what the scanner looked for, the candidate files it found,
+	// the rejected candidates with the reason (like the rejecting filter) and the decisive reason of the verdict.
+	// It is called after DetectPlatform, whether the platform is detected or not (see: models.NewExplanation).
+	Explanation() models.Explanation
+
+	// Confidence returns how likely it is, that the project belongs to the scanner's platform, with the evidence behind it,
+	// like a root level project, shared schemes or lock files (see: models.NewConfidence).
+	// It is called after the platform is detected, the evidence should be based on the DetectPlatform findings.
+	// The scan result ranks the detected platforms by their confidence.
+	Confidence() models.Confidence
 
-	// ExcludedScannerNames is used to mark, which scanners should be excluded, if the current scanner detects platform.
-	ExcludedScannerNames() []string
+	// Relationships declares the scanner's precedence, conflict and augment relationships with the other scanners.
+	// The relationships are resolved into a scan plan (see: NewPlan), so the scanners order does not matter.
+	Relationships() models.ScannerRelationships
 
 	// OptionModel is the model, used to store the available configuration combintaions.
 	// It defines option branches which leads different bitrise configurations.
@@ -40,7 +70,7 @@ type ScannerInterface interface {
 	// - OptionModel
 	// - Warnings (if any)
 	// - error if (if any)
-	Options() (models.OptionModel, models.Warnings, error)
+	Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error)
 
 	// Returns:
 	// - default options for the platform.
@@ -50,21 +80,121 @@ type ScannerInterface interface {
 	// Every config's key should be the last option one of the OptionModel branches.
 	// Returns:
 	// - platform BitriseConfigMap
-	Configs() (models.BitriseConfigMap, error)
+	Configs(ctx context.Context) (models.BitriseConfigMap, error)
 
 	// Returns:
 	// - platform default BitriseConfigMap
 	DefaultConfigs() (models.BitriseConfigMap, error)
 }
 
-// ActiveScanners ...
-var ActiveScanners = []ScannerInterface{
-	cordova.NewScanner(),
-	ios.NewScanner(),
-	macos.NewScanner(),
-	android.NewScanner(),
-	xamarin.NewScanner(),
-	fastlane.NewScanner(),
+// ConfigAugmenter is implemented by the scanners, which complement the configs of the scanners they augment
+// (see: models.ScannerRelationships.Augments), like adding the dependency caching steps to the Xcode project configs.
+// AugmentConfigs is called after the scanner and the augmented scanners finished, with the configs of every augmented scanner, which detected the project.
+// It is called even if the scanner did not detect its own platform, as the augmented projects may use the scanner's platform
+// without being its projects (like an Xcode app depending on Swift packages, without a Package.swift), the scanner decides whether to change the configs.
+// The augment errors are reported under the augmented scanner, as the augmenting scanner may not be part of the result.
+type ConfigAugmenter interface {
+	// AugmentConfigs returns the complemented configs of the given augmented scanner.
+	AugmentConfigs(scannerName string, configs models.BitriseConfigMap) (models.BitriseConfigMap, error)
+}
+
+// PathClaimer is implemented by the scanners, whose conflicts (see: models.ScannerRelationships.ConflictsWith)
+// are limited to the files of their detected projects, like the native projects generated into a cordova project's platforms directory.
+// The claimed paths (and every path under them) are hidden from the conflicting scanners, which run once the claiming scanner finished,
+// so the conflicting scanners still detect the unrelated projects of the repository.
+// A detecting, conflicting scanner, which does not claim paths (like a plugin scanner) overrides the other scanner's whole output.
+type PathClaimer interface {
+	// ClaimedPaths returns the files and directories of the detected projects, relative to the scanned root.
+	// It is called only if the scanner detected its platform.
+	ClaimedPaths() []string
+}
+
+// NewScanners creates new instances of the built-in scanners.
+// The scanners store the state of a scan, so every scan has to use its own scanner instances.
+func NewScanners() []ScannerInterface {
+	return []ScannerInterface{
+		ionic.NewScanner(),
+		cordova.NewScanner(),
+		reactnative.NewScanner(),
+		flutter.NewScanner(),
+		unity.NewScanner(),
+		ios.NewScanner(),
+		macos.NewScanner(),
+		android.NewScanner(),
+		xamarin.NewScanner(),
+		fastlane.NewScanner(),
+		spm.NewScanner(),
+	}
+}
+
+// NewPluginScanners creates scanners for the given plugin executables and for the ones discovered in the plugin dirs.
+// The plugin names have to be unique, and can not collide with the built-in scanners' names.
+func NewPluginScanners(executablePths, pluginDirs []string) ([]ScannerInterface, error) {
+	discoveredPths, err := plugin.Discover(pluginDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	scannerNames := map[string]bool{CustomProjectType: true}
+	for _, scanner := range NewScanners() {
+		scannerNames[scanner.Name()] = true
+	}
+
+	pluginScanners := []ScannerInterface{}
+	for _, pth := range append(append([]string{}, executablePths...), discoveredPths...) {
+		scanner, err := plugin.NewScanner(pth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plugin (%s), error: %s", pth, err)
+		}
+
+		if scannerNames[scanner.Name()] {
+			return nil, fmt.Errorf("plugin (%s) name (%s) is not unique", pth, scanner.Name())
+		}
+		scannerNames[scanner.Name()] = true
+
+		pluginScanners = append(pluginScanners, scanner)
+	}
+
+	return pluginScanners, nil
+}
+
+// SelectScanners returns the projectScanners, filtered by the given scanner names.
+// If no scannerNames given, every scanner is selected, except the skipped ones.
+func SelectScanners(projectScanners []ScannerInterface, scannerNames, skippedScannerNames []string) ([]ScannerInterface, error) {
+	availableScannerNames := []string{}
+	availableScannerMap := map[string]bool{}
+	for _, scanner := range projectScanners {
+		availableScannerNames = append(availableScannerNames, scanner.Name())
+		availableScannerMap[scanner.Name()] = true
+	}
+
+	for _, name := range append(append([]string{}, scannerNames...), skippedScannerNames...) {
+		if !availableScannerMap[name] {
+			return nil, fmt.Errorf("unknown scanner (%s), available scanners: %s", name, strings.Join(availableScannerNames, ", "))
+		}
+	}
+
+	selectedScannerMap := map[string]bool{}
+	for _, name := range scannerNames {
+		selectedScannerMap[name] = true
+	}
+	skippedScannerMap := map[string]bool{}
+	for _, name := range skippedScannerNames {
+		skippedScannerMap[name] = true
+	}
+
+	selectedScanners := []ScannerInterface{}
+	for _, scanner := range projectScanners {
+		if len(selectedScannerMap) > 0 && !selectedScannerMap[scanner.Name()] {
+			continue
+		}
+		if skippedScannerMap[scanner.Name()] {
+			continue
+		}
+		selectedScanners = append(selectedScanners, scanner)
+	}
+
+	return selectedScanners, nil
 }
 
 // CustomProjectType ...
@@ -0,0 +1,61 @@
+package scanners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scannerNames(scanners []ScannerInterface) []string {
+	names := []string{}
+	for _, scanner := range scanners {
+		names = append(names, scanner.Name())
+	}
+	return names
+}
+
+func TestSelectScanners(t *testing.T) {
+	t.Log("every scanner by default")
+	{
+		selected, err := SelectScanners(NewScanners(), nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, scannerNames(NewScanners()), scannerNames(selected))
+	}
+
+	t.Log("selected scanners keep the scanners order")
+	{
+		selected, err := SelectScanners(NewScanners(), []string{"fastlane", "android"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"android", "fastlane"}, scannerNames(selected))
+	}
+
+	t.Log("skipped scanners")
+	{
+		selected, err := SelectScanners(NewScanners(), nil, []string{"xamarin", "ios", "macos"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"ionic", "cordova", "react-native", "flutter", "unity", "android", "fastlane", "spm"}, scannerNames(selected))
+
+		selected, err = SelectScanners(NewScanners(), []string{"android", "fastlane"}, []string{"fastlane"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"android"}, scannerNames(selected))
+	}
+
+	t.Log("unknown scanner")
+	{
+		_, err := SelectScanners(NewScanners(), []string{"android", "gradle"}, nil)
+		require.EqualError(t, err, "unknown scanner (gradle), available scanners: ionic, cordova, react-native, flutter, unity, ios, macos, android, xamarin, fastlane, spm")
+
+		_, err = SelectScanners(NewScanners(), nil, []string{"other"})
+		require.Error(t, err)
+	}
+}
+
+func TestNewScanners(t *testing.T) {
+	scanners := NewScanners()
+	otherScanners := NewScanners()
+	require.Equal(t, scannerNames(scanners), scannerNames(otherScanners))
+
+	for i := range scanners {
+		require.False(t, scanners[i] == otherScanners[i], "scanner (%s) instance is shared between scans", scanners[i].Name())
+	}
+}
@@ -0,0 +1,524 @@
+package spm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners/xcode"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+	"github.com/bitrise-io/go-utils/sliceutil"
+	"github.com/bitrise-tools/go-xcode/xcodeproj"
+)
+
+// ScannerName ...
+const ScannerName = "spm"
+
+const (
+	configName        = "spm-config"
+	defaultConfigName = "default-spm-config"
+)
+
+const (
+	packageManifestBasePath = "Package.swift"
+	packageResolvedBasePath = "Package.resolved"
+)
+
+// the dependency checkouts of the package managers, their manifests are not the project's packages
+var dependencyDirNames = []string{".build", "SourcePackages", "Carthage", "Pods"}
+
+// Step Inputs
+const (
+	packagePathInputTitle  = "Swift package path"
+	packagePathInputEnvKey = "SPM_PACKAGE_PATH"
+)
+
+const (
+	schemeInputTitle  = "Scheme name"
+	schemeInputEnvKey = "SPM_SCHEME"
+)
+
+const (
+	contentInputKey    = "content"
+	cachePathsInputKey = "cache_paths"
+)
+
+// packageResolvedEnvKey is exported by the dependency resolution of the Xcode projects, it is the cache indicator of the resolved packages
+const packageResolvedEnvKey = "SPM_PACKAGE_RESOLVED_PATH"
+
+// platformDestination holds the xcodebuild destinations of a platform, the packages declaring only these platforms can not be built by swift build.
+type platformDestination struct {
+	platform string
+	build    string
+	// test is empty, if the platform's tests can not be run by xcodebuild
+	test string
+}
+
+var platformDestinations = []platformDestination{
+	{platform: "iOS", build: "generic/platform=iOS", test: "platform=iOS Simulator,name=iPhone 8,OS=latest"},
+	{platform: "tvOS", build: "generic/platform=tvOS", test: "platform=tvOS Simulator,name=Apple TV,OS=latest"},
+	{platform: "watchOS", build: "generic/platform=watchOS"},
+}
+
+// xcodeBuildStepIDs are the build steps of the ios and macos configs, which use the Swift package dependencies of the Xcode project,
+// the dependencies are resolved right before them: once the schemes are recreated and the CocoaPods and Carthage dependencies are installed.
+var xcodeBuildStepIDs = []string{
+	steps.XcodeArchiveID,
+	steps.XcodeTestID,
+	steps.XcodeArchiveMacID,
+	steps.XcodeTestMacID,
+}
+
+// swiftPackage is a detected Swift package.
+type swiftPackage struct {
+	manifestPth string
+	manifest    utility.SwiftPackageModel
+}
+
+func (pkg swiftPackage) dir() string {
+	return filepath.Dir(pkg.manifestPth)
+}
+
+// schemes returns the schemes, which Xcode generates for the package:
+// a scheme for every product and a <Name>-Package scheme, building every product of a multi product package.
+func (pkg swiftPackage) schemes() []string {
+	if len(pkg.manifest.Products) == 1 {
+		return []string{pkg.manifest.Products[0].Name}
+	}
+
+	schemes := []string{pkg.manifest.Name + "-Package"}
+	for _, product := range pkg.manifest.Products {
+		schemes = append(schemes, product.Name)
+	}
+	return schemes
+}
+
+// destination returns the xcodebuild destination of the package, if the package can not be built by swift build,
+// as it only declares platforms not supported by the macOS host.
+func (pkg swiftPackage) destination() (platformDestination, bool) {
+	if len(pkg.manifest.Platforms) == 0 || pkg.manifest.HasPlatform("macOS") {
+		return platformDestination{}, false
+	}
+
+	for _, destination := range platformDestinations {
+		if pkg.manifest.HasPlatform(destination.platform) {
+			return destination, true
+		}
+	}
+	return platformDestination{}, false
+}
+
+// configDescriptor describes a config of the scanner.
+type configDescriptor struct {
+	hasTest bool
+	// destination is the xcodebuild destination, the swift cli builds the package if it is empty.
+	destination platformDestination
+}
+
+func (descriptor configDescriptor) configName() string {
+	name := configName
+	if descriptor.hasTest {
+		name += "-test"
+	}
+	if descriptor.destination.platform != "" {
+		name += "-" + strings.ToLower(descriptor.destination.platform)
+	}
+	return name
+}
+
+//------------------
+// ScannerInterface
+//------------------
+
+// Scanner ...
+type Scanner struct {
+	fs       filesystem.FileSystem
+	packages []swiftPackage
+	// xcodePackageResolvedPths are the Package.resolved files of the Xcode projects, using Swift package dependencies
+	xcodePackageResolvedPths []string
+
+	configDescriptors []configDescriptor
+
+	explanation models.Explanation
+	logger      logger.Logger
+}
+
+// NewScanner ...
+func NewScanner() *Scanner {
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
+}
+
+// Name ...
+func (scanner Scanner) Name() string {
+	return ScannerName
+}
+
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	fs := fileIndex.FS()
+	scanner.fs = fs
+
+	// Search for the Package.resolved files of the Xcode projects
+	allowXcodeProjectComponentFilter := utility.ComponentWithExtensionFilter(xcodeproj.XCodeProjExt, true)
+	allowXcodeWorkspaceComponentFilter := utility.ComponentWithExtensionFilter(xcodeproj.XCWorkspaceExt, true)
+	for _, pth := range fileIndex.ByBase(packageResolvedBasePath) {
+		inProject, err := allowXcodeProjectComponentFilter(pth)
+		if err != nil {
+			return false, err
+		}
+		inWorkspace, err := allowXcodeWorkspaceComponentFilter(pth)
+		if err != nil {
+			return false, err
+		}
+		if inProject || inWorkspace {
+			scanner.xcodePackageResolvedPths = append(scanner.xcodePackageResolvedPths, pth)
+		}
+	}
+
+	if len(scanner.xcodePackageResolvedPths) > 0 {
+		scanner.logger.Printft("%d Xcode projects with Swift package dependencies found", len(scanner.xcodePackageResolvedPths))
+	}
+	// ---
+
+	// Search for Package.swift files
+	scanner.logger.Infoft("Searching for Package.swift files")
+
+	candidates := fileIndex.ByBase(packageManifestBasePath)
+	scanner.explanation = models.NewExplanation("Package.swift manifests of Swift packages", candidates...)
+
+	for _, pth := range candidates {
+		rejected := false
+		for _, dirName := range dependencyDirNames {
+			if allowed, err := utility.ComponentFilter(dirName, false)(pth); err != nil {
+				return false, err
+			} else if !allowed {
+				scanner.explanation.Reject(pth, fmt.Sprintf("inside the %s dependency directory", dirName))
+				rejected = true
+				break
+			}
+		}
+		if rejected {
+			continue
+		}
+
+		manifest, err := utility.ParseSwiftPackage(fs, pth)
+		if err != nil {
+			scanner.explanation.Reject(pth, fmt.Sprintf("can not read: %s", err))
+			continue
+		}
+
+		if manifest.Name == "" {
+			scanner.explanation.Reject(pth, "no package name found, the manifest is parsed statically, the computed names are not supported")
+			continue
+		}
+
+		pkg := swiftPackage{manifestPth: pth, manifest: manifest}
+		scanner.packages = append(scanner.packages, pkg)
+
+		platforms := []string{}
+		for _, platform := range manifest.Platforms {
+			platforms = append(platforms, platform.Name+" "+platform.Version)
+		}
+		scanner.logger.Printft("- %s (%s), tools version: %s", pth, manifest.Name, manifest.ToolsVersion)
+		scanner.logger.Printft("  %d products, %d targets, %d test targets, platforms: %s", len(manifest.Products), len(manifest.Targets), len(manifest.TestTargets()), strings.Join(platforms, ", "))
+	}
+
+	if len(scanner.packages) == 0 {
+		scanner.explanation.Reason = "no Package.swift found"
+		scanner.logger.Printft("platform not detected")
+		return false, nil
+	}
+
+	scanner.explanation.Reason = fmt.Sprintf("%d Swift package(s) found", len(scanner.packages))
+	scanner.logger.Doneft("Platform detected")
+
+	return true, nil
+}
+
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
+
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	manifestPths := []string{}
+	resolvedPths := []string{}
+	testManifestPths := []string{}
+	for _, pkg := range scanner.packages {
+		manifestPths = append(manifestPths, pkg.manifestPth)
+		if len(pkg.manifest.TestTargets()) > 0 {
+			testManifestPths = append(testManifestPths, pkg.manifestPth)
+		}
+
+		resolvedPth := filepath.Join(pkg.dir(), packageResolvedBasePath)
+		if exist, err := filesystem.IsPathExists(scanner.fs, resolvedPth); err == nil && exist {
+			resolvedPths = append(resolvedPths, resolvedPth)
+		}
+	}
+
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "Package.swift found", manifestPths...),
+		models.DepthEvidence(manifestPths[0]),
+	}
+	if len(testManifestPths) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "test targets declared", testManifestPths...))
+	}
+	if len(resolvedPths) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "Package.resolved found", resolvedPths...))
+	}
+
+	return models.NewConfidence(evidence...)
+}
+
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	return models.ScannerRelationships{
+		Augments: []string{
+			string(utility.XcodeProjectTypeIOS),
+			string(utility.XcodeProjectTypeMacOS),
+		},
+	}
+}
+
+func (scanner *Scanner) addConfigDescriptor(descriptor configDescriptor) {
+	for _, configDescriptor := range scanner.configDescriptors {
+		if configDescriptor == descriptor {
+			return
+		}
+	}
+	scanner.configDescriptors = append(scanner.configDescriptors, descriptor)
+}
+
+// Options ...
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
+
+	packagePathOption := models.NewOption(packagePathInputTitle, packagePathInputEnvKey)
+
+	for _, pkg := range scanner.packages {
+		destination, _ := pkg.destination()
+		descriptor := configDescriptor{
+			hasTest:     len(pkg.manifest.TestTargets()) > 0 && (destination.platform == "" || destination.test != ""),
+			destination: destination,
+		}
+		scanner.addConfigDescriptor(descriptor)
+
+		schemeOption := models.NewOption(schemeInputTitle, schemeInputEnvKey)
+		packagePathOption.AddOption(pkg.dir(), schemeOption)
+
+		for _, scheme := range pkg.schemes() {
+			schemeOption.AddConfig(scheme, models.NewConfigOption(descriptor.configName()))
+		}
+	}
+
+	return *packagePathOption, warnings, nil
+}
+
+// DefaultOptions ...
+func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	packagePathOption := models.NewOption(packagePathInputTitle, packagePathInputEnvKey)
+
+	schemeOption := models.NewOption(schemeInputTitle, schemeInputEnvKey)
+	packagePathOption.AddOption("_", schemeOption)
+
+	schemeOption.AddConfig("_", models.NewConfigOption(defaultConfigName))
+
+	return *packagePathOption
+}
+
+func scriptContent(commands ...string) string {
+	return "#!/usr/bin/env bash\nset -ex\n\ncd \"$" + packagePathInputEnvKey + "\"\n\n" + strings.Join(commands, "\n") + "\n"
+}
+
+// buildStepList returns the steps building (and testing) the package, by the swift cli or by xcodebuild,
+// the dependencies are resolved into the .build dir in both cases, so the dir can be cached.
+func buildStepList(descriptor configDescriptor) []bitriseModels.StepListItemModel {
+	scheme := `"$` + schemeInputEnvKey + `"`
+
+	var buildContent, testContent string
+	if descriptor.destination.platform == "" {
+		buildContent = scriptContent(
+			`if [[ `+scheme+` == *-Package ]] ; then`,
+			`  swift build`,
+			`else`,
+			`  swift build --product `+scheme,
+			`fi`,
+		)
+		testContent = scriptContent("swift test")
+	} else {
+		buildContent = scriptContent(fmt.Sprintf(`xcodebuild build -scheme %s -destination "%s" -clonedSourcePackagesDirPath .build/SourcePackages`, scheme, descriptor.destination.build))
+		testContent = scriptContent(fmt.Sprintf(`xcodebuild test -scheme %s -destination "%s" -clonedSourcePackagesDirPath .build/SourcePackages`, scheme, descriptor.destination.test))
+	}
+
+	stepList := []bitriseModels.StepListItemModel{
+		steps.ScriptSteplistItem("Build the Swift package", envmanModels.EnvironmentItemModel{contentInputKey: buildContent}),
+	}
+	if descriptor.hasTest {
+		stepList = append(stepList, steps.ScriptSteplistItem("Test the Swift package", envmanModels.EnvironmentItemModel{contentInputKey: testContent}))
+	}
+
+	packagePath := "$" + packagePathInputEnvKey
+	return append(stepList, steps.CachePushStepListItem(
+		envmanModels.EnvironmentItemModel{cachePathsInputKey: fmt.Sprintf("%s/.build -> %s/%s", packagePath, packagePath, packageResolvedBasePath)},
+	))
+}
+
+func generateConfig(descriptor configDescriptor) (string, error) {
+	configBuilder := models.NewDefaultConfigBuilder()
+
+	configBuilder.AppendDependencyStepList(steps.CachePullStepListItem())
+	configBuilder.AppendMainStepList(buildStepList(descriptor)...)
+
+	config, err := configBuilder.Generate(ScannerName)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Configs ...
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
+	configMap := models.BitriseConfigMap{}
+	for _, descriptor := range scanner.configDescriptors {
+		config, err := generateConfig(descriptor)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+		configMap[descriptor.configName()] = config
+	}
+
+	return configMap, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	config, err := generateConfig(configDescriptor{hasTest: true})
+	if err != nil {
+		return models.BitriseConfigMap{}, err
+	}
+
+	return models.BitriseConfigMap{
+		defaultConfigName: config,
+	}, nil
+}
+
+//------------------
+// ConfigAugmenter
+//------------------
+
+// resolveDependenciesContent resolves the Swift package dependencies of the selected Xcode project (or workspace)
+// and exports the path of its Package.resolved file, which is the cache indicator of the resolved packages.
+var resolveDependenciesContent = `#!/usr/bin/env bash
+set -ex
+
+if [[ "$` + xcode.ProjectPathInputEnvKey + `" == *` + xcodeproj.XCWorkspaceExt + ` ]] ; then
+  xcodebuild -resolvePackageDependencies -workspace "$` + xcode.ProjectPathInputEnvKey + `" -scheme "$` + xcode.SchemeInputEnvKey + `"
+  resolved="$` + xcode.ProjectPathInputEnvKey + `/xcshareddata/swiftpm/` + packageResolvedBasePath + `"
+else
+  xcodebuild -resolvePackageDependencies -project "$` + xcode.ProjectPathInputEnvKey + `" -scheme "$` + xcode.SchemeInputEnvKey + `"
+  resolved="$` + xcode.ProjectPathInputEnvKey + `/project.xcworkspace/xcshareddata/swiftpm/` + packageResolvedBasePath + `"
+fi
+
+envman add --key ` + packageResolvedEnvKey + ` --value "$resolved"
+`
+
+func stepID(stepListItem bitriseModels.StepListItemModel) (string, error) {
+	stepIDComposite, _, err := bitriseModels.GetStepIDStepDataPair(stepListItem)
+	if err != nil {
+		return "", err
+	}
+	return strings.Split(stepIDComposite, "@")[0], nil
+}
+
+// augmentStepList adds the Swift package dependency resolution (with the cache pull) before the first Xcode build step,
+// and the cache push of the resolved packages before the deploy step. The step lists without Xcode build steps are kept as they are.
+func augmentStepList(stepList []bitriseModels.StepListItemModel) ([]bitriseModels.StepListItemModel, error) {
+	firstBuildStepIdx, deployStepIdx := -1, len(stepList)
+	for i, stepListItem := range stepList {
+		id, err := stepID(stepListItem)
+		if err != nil {
+			return nil, err
+		}
+
+		if firstBuildStepIdx == -1 && sliceutil.IsStringInSlice(id, xcodeBuildStepIDs) {
+			firstBuildStepIdx = i
+		}
+		if id == steps.DeployToBitriseIoID {
+			deployStepIdx = i
+		}
+	}
+	if firstBuildStepIdx == -1 || deployStepIdx < firstBuildStepIdx {
+		return stepList, nil
+	}
+
+	augmented := []bitriseModels.StepListItemModel{}
+	augmented = append(augmented, stepList[:firstBuildStepIdx]...)
+	augmented = append(augmented,
+		steps.CachePullStepListItem(),
+		steps.ScriptSteplistItem("Resolve Swift package dependencies", envmanModels.EnvironmentItemModel{contentInputKey: resolveDependenciesContent}),
+	)
+	augmented = append(augmented, stepList[firstBuildStepIdx:deployStepIdx]...)
+	augmented = append(augmented, steps.CachePushStepListItem(
+		envmanModels.EnvironmentItemModel{cachePathsInputKey: "$HOME/Library/Caches/org.swift.swiftpm -> $" + packageResolvedEnvKey},
+	))
+	return append(augmented, stepList[deployStepIdx:]...), nil
+}
+
+// AugmentConfigs adds the Swift package dependency resolution and the caching of the resolved packages to the Xcode project configs,
+// if any of the Xcode projects uses Swift package dependencies, the Xcode apps usually do not have a Package.swift,
+// so the configs are augmented even if no Swift package is detected.
+func (scanner *Scanner) AugmentConfigs(scannerName string, configs models.BitriseConfigMap) (models.BitriseConfigMap, error) {
+	if len(scanner.xcodePackageResolvedPths) == 0 {
+		return configs, nil
+	}
+
+	augmentedConfigs := models.BitriseConfigMap{}
+	for name, content := range configs {
+		var config bitriseModels.BitriseDataModel
+		if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+			return models.BitriseConfigMap{}, fmt.Errorf("failed to parse config (%s), error: %s", name, err)
+		}
+
+		for workflowID, workflow := range config.Workflows {
+			stepList, err := augmentStepList(workflow.Steps)
+			if err != nil {
+				return models.BitriseConfigMap{}, fmt.Errorf("failed to augment workflow (%s) of config (%s), error: %s", workflowID, name, err)
+			}
+			workflow.Steps = stepList
+			config.Workflows[workflowID] = workflow
+		}
+
+		data, err := yaml.Marshal(config)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+		augmentedConfigs[name] = string(data)
+	}
+
+	scanner.logger.Printft("%s configs augmented with the Swift package dependency resolution", scannerName)
+
+	return augmentedConfigs, nil
+}
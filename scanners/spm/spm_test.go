@@ -0,0 +1,60 @@
+package spm
+
+import (
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/scanners/xcode"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	"github.com/stretchr/testify/require"
+)
+
+func workflowStepIDs(t *testing.T, stepList []bitriseModels.StepListItemModel) []string {
+	ids := []string{}
+	for _, stepListItem := range stepList {
+		id, err := stepID(stepListItem)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestAugmentStepList(t *testing.T) {
+	t.Log("the dependencies are resolved after the CocoaPods dependencies and the recreated schemes")
+	{
+		configBuilder := xcode.GenerateConfigBuilder(utility.XcodeProjectTypeIOS, true, true, true, "bootstrap")
+		config, err := configBuilder.Generate(string(utility.XcodeProjectTypeIOS))
+		require.NoError(t, err)
+
+		stepList, err := augmentStepList(config.Workflows["deploy"].Steps)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			steps.ActivateSSHKeyID,
+			steps.GitCloneID,
+			steps.ScriptID,
+			steps.CertificateAndProfileInstallerID,
+			steps.RecreateUserSchemesID,
+			steps.CocoapodsInstallID,
+			steps.CarthageID,
+			steps.CachePullID,
+			steps.ScriptID,
+			steps.XcodeTestID,
+			steps.XcodeArchiveID,
+			steps.CachePushID,
+			steps.DeployToBitriseIoID,
+		}, workflowStepIDs(t, stepList))
+	}
+
+	t.Log("step list without Xcode build steps")
+	{
+		stepList := []bitriseModels.StepListItemModel{
+			steps.CocoapodsInstallStepListItem(),
+			steps.DeployToBitriseIoStepListItem(),
+		}
+
+		augmented, err := augmentStepList(stepList)
+		require.NoError(t, err)
+		require.Equal(t, stepList, augmented)
+	}
+}
@@ -0,0 +1,451 @@
+package unity
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-core/bitrise-init/scanners/android"
+	"github.com/bitrise-core/bitrise-init/scanners/xcode"
+	"github.com/bitrise-core/bitrise-init/steps"
+	"github.com/bitrise-core/bitrise-init/utility"
+	bitriseModels "github.com/bitrise-io/bitrise/models"
+	envmanModels "github.com/bitrise-io/envman/models"
+)
+
+// ScannerName ...
+const ScannerName = "unity"
+
+const (
+	configName        = "unity-config"
+	defaultConfigName = "default-unity-config"
+)
+
+const (
+	projectVersionBasePath  = "ProjectVersion.txt"
+	projectSettingsBasePath = "ProjectSettings.asset"
+	projectSettingsDirName  = "ProjectSettings"
+	assetsDirName           = "Assets"
+)
+
+// Step Inputs
+const (
+	projectPathInputTitle  = "Unity project path"
+	projectPathInputEnvKey = "UNITY_PROJECT_PATH"
+)
+
+const (
+	buildTargetInputTitle  = "Build target"
+	buildTargetInputEnvKey = "UNITY_BUILD_TARGET"
+)
+
+const (
+	contentInputKey     = "content"
+	gradleFileInputKey  = "gradle_file"
+	gradleTaskInputKey  = "gradle_task"
+	gradlewPathInputKey = "gradlew_path"
+)
+
+// App Envs
+const (
+	editorVersionEnvKey = "UNITY_EDITOR_VERSION"
+	// buildMethodEnvKey is the static method of the project's editor scripts, which exports the native project of the build target
+	buildMethodEnvKey     = "UNITY_BUILD_METHOD"
+	defaultBuildMethod    = "BuildScript.Export"
+	xcodeExportPathEnvKey = "UNITY_XCODE_EXPORT_PATH"
+	// xcodeExportPath is relative to the Unity project path
+	xcodeExportPath        = "Build/iOS"
+	gradleExportPathEnvKey = "UNITY_GRADLE_EXPORT_PATH"
+	// gradleExportPath is relative to the Unity project path
+	gradleExportPath             = "Build/Android"
+	standaloneBuildPathEnvKey    = "UNITY_STANDALONE_BUILD_PATH"
+	standaloneBuildPath          = "Build/StandaloneOSX.app"
+	exportedXcodeProjectBasePath = "Unity-iPhone.xcodeproj"
+	exportedXcodeScheme          = "Unity-iPhone"
+)
+
+// buildTarget is a supported build target of the Unity editor.
+type buildTarget struct {
+	// name is the value of the editor's -buildTarget command line argument
+	name string
+	// group is the build target group of the player settings
+	group string
+	// configSuffix identifies the build target in the config names
+	configSuffix string
+}
+
+var (
+	iOSBuildTarget        = buildTarget{name: "iOS", group: "iPhone", configSuffix: "ios"}
+	androidBuildTarget    = buildTarget{name: "Android", group: "Android", configSuffix: "android"}
+	standaloneBuildTarget = buildTarget{name: "StandaloneOSX", group: "Standalone", configSuffix: "standalone"}
+)
+
+var buildTargets = []buildTarget{iOSBuildTarget, androidBuildTarget, standaloneBuildTarget}
+
+// project is a detected Unity project.
+type project struct {
+	dir               string
+	projectVersionPth string
+	projectVersion    utility.UnityProjectVersionModel
+	playerSettingsPth string
+	hasPlayerSettings bool
+	playerSettings    utility.UnityPlayerSettingsModel
+	configuredTargets []buildTarget
+}
+
+// buildTargets returns the build targets with configured player settings,
+// or every supported build target, if none of them are configured.
+func (proj project) buildTargets() []buildTarget {
+	if len(proj.configuredTargets) > 0 {
+		return proj.configuredTargets
+	}
+	return buildTargets
+}
+
+// configDescriptor describes a config of the scanner.
+type configDescriptor struct {
+	target buildTarget
+	// editorVersion is the version of the Unity editor building the project, the default editor is used if it is empty.
+	editorVersion string
+}
+
+func (descriptor configDescriptor) configName() string {
+	name := configName + "-" + descriptor.target.configSuffix
+	if descriptor.editorVersion != "" {
+		name += "-editor-" + descriptor.editorVersion
+	}
+	return name
+}
+
+//------------------
+// ScannerInterface
+//------------------
+
+// Scanner ...
+type Scanner struct {
+	projects []project
+
+	configDescriptors []configDescriptor
+
+	explanation models.Explanation
+	logger      logger.Logger
+}
+
+// NewScanner ...
+func NewScanner() *Scanner {
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
+}
+
+// Name ...
+func (scanner Scanner) Name() string {
+	return ScannerName
+}
+
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
+// DetectPlatform ...
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	fs := fileIndex.FS()
+
+	// Search for ProjectVersion.txt files
+	scanner.logger.Infoft("Searching for ProjectSettings/ProjectVersion.txt files")
+
+	candidates := fileIndex.ByBase(projectVersionBasePath)
+	scanner.explanation = models.NewExplanation("ProjectSettings/ProjectVersion.txt next to the Assets directory of a Unity project", candidates...)
+
+	for _, pth := range candidates {
+		settingsDir := filepath.Dir(pth)
+		if filepath.Base(settingsDir) != projectSettingsDirName {
+			scanner.explanation.Reject(pth, "not in a ProjectSettings directory")
+			continue
+		}
+
+		dir := filepath.Dir(settingsDir)
+		if !fileIndex.IsDir(filepath.Join(dir, assetsDirName)) {
+			scanner.explanation.Reject(pth, "no Assets directory found next to the ProjectSettings directory")
+			continue
+		}
+
+		projectVersion, err := utility.ParseUnityProjectVersion(fs, pth)
+		if err != nil {
+			scanner.explanation.Reject(pth, fmt.Sprintf("can not parse: %s", err))
+			continue
+		}
+
+		if projectVersion.EditorVersion == "" {
+			scanner.explanation.Reject(pth, "no editor version found")
+			continue
+		}
+
+		proj := project{
+			dir:               dir,
+			projectVersionPth: pth,
+			projectVersion:    projectVersion,
+			playerSettingsPth: filepath.Join(settingsDir, projectSettingsBasePath),
+		}
+
+		if exist, err := filesystem.IsPathExists(fs, proj.playerSettingsPth); err != nil {
+			return false, err
+		} else if exist {
+			playerSettings, err := utility.ParseUnityPlayerSettings(fs, proj.playerSettingsPth)
+			if err != nil {
+				scanner.explanation.Reject(pth, fmt.Sprintf("can not parse %s: %s", projectSettingsBasePath, err))
+				continue
+			}
+
+			proj.hasPlayerSettings = true
+			proj.playerSettings = playerSettings
+			for _, target := range buildTargets {
+				if playerSettings.HasBuildTargetGroup(target.group) {
+					proj.configuredTargets = append(proj.configuredTargets, target)
+				}
+			}
+		}
+
+		scanner.projects = append(scanner.projects, proj)
+
+		targetNames := []string{}
+		for _, target := range proj.buildTargets() {
+			targetNames = append(targetNames, target.name)
+		}
+		scanner.logger.Printft("- %s", pth)
+		scanner.logger.Printft("  product name: %s, editor version: %s, build targets: %s", proj.playerSettings.ProductName, projectVersion.EditorVersion, strings.Join(targetNames, ", "))
+		if len(proj.configuredTargets) == 0 {
+			scanner.logger.Warnft("  no player settings configured for the supported build targets, offering every build target")
+		}
+	}
+
+	if len(scanner.projects) == 0 {
+		scanner.explanation.Reason = "no Unity project found"
+		scanner.logger.Printft("platform not detected")
+		return false, nil
+	}
+
+	scanner.explanation.Reason = fmt.Sprintf("%d Unity project(s) found", len(scanner.projects))
+	scanner.logger.Doneft("Platform detected")
+
+	return true, nil
+}
+
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
+}
+
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	projectVersionPths := []string{}
+	assetsDirs := []string{}
+	playerSettingsPths := []string{}
+	for _, proj := range scanner.projects {
+		projectVersionPths = append(projectVersionPths, proj.projectVersionPth)
+		assetsDirs = append(assetsDirs, filepath.Join(proj.dir, assetsDirName))
+		if proj.hasPlayerSettings {
+			playerSettingsPths = append(playerSettingsPths, proj.playerSettingsPth)
+		}
+	}
+
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "Unity project version file found", projectVersionPths...),
+		// the depth of the project is the depth of its Assets directory
+		models.DepthEvidence(assetsDirs...),
+		models.NewEvidence(models.SupportingFileWeight, "Assets directory found", assetsDirs...),
+	}
+	if len(playerSettingsPths) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "player settings found", playerSettingsPths...))
+	}
+
+	return models.NewConfidence(evidence...)
+}
+
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	// the native projects exported into the Build directory of a Unity project are built by the Unity configs
+	nativeScannerNames := []string{
+		string(utility.XcodeProjectTypeIOS),
+		android.ScannerName,
+	}
+
+	return models.ScannerRelationships{
+		Precedes:      nativeScannerNames,
+		ConflictsWith: nativeScannerNames,
+	}
+}
+
+// ClaimedPaths returns the Xcode and gradle export directories of the detected projects,
+// the exported native projects in them are hidden from the conflicting scanners.
+func (scanner *Scanner) ClaimedPaths() []string {
+	claimedPaths := []string{}
+	for _, proj := range scanner.projects {
+		claimedPaths = append(claimedPaths, filepath.Join(proj.dir, xcodeExportPath), filepath.Join(proj.dir, gradleExportPath))
+	}
+	return claimedPaths
+}
+
+func (scanner *Scanner) addConfigDescriptor(descriptor configDescriptor) {
+	for _, configDescriptor := range scanner.configDescriptors {
+		if configDescriptor == descriptor {
+			return
+		}
+	}
+	scanner.configDescriptors = append(scanner.configDescriptors, descriptor)
+}
+
+// Options ...
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
+
+	projectPathOption := models.NewOption(projectPathInputTitle, projectPathInputEnvKey)
+
+	for _, proj := range scanner.projects {
+		buildTargetOption := models.NewOption(buildTargetInputTitle, buildTargetInputEnvKey)
+		projectPathOption.AddOption(proj.dir, buildTargetOption)
+
+		for _, target := range proj.buildTargets() {
+			descriptor := configDescriptor{target: target, editorVersion: proj.projectVersion.EditorVersion}
+			scanner.addConfigDescriptor(descriptor)
+
+			buildTargetOption.AddConfig(target.name, models.NewConfigOption(descriptor.configName()))
+		}
+	}
+
+	return *projectPathOption, warnings, nil
+}
+
+// DefaultOptions ...
+func (scanner *Scanner) DefaultOptions() models.OptionModel {
+	projectPathOption := models.NewOption(projectPathInputTitle, projectPathInputEnvKey)
+
+	buildTargetOption := models.NewOption(buildTargetInputTitle, buildTargetInputEnvKey)
+	projectPathOption.AddOption("_", buildTargetOption)
+
+	for _, target := range buildTargets {
+		buildTargetOption.AddConfig(target.name, models.NewConfigOption(defaultConfigName+"-"+target.configSuffix))
+	}
+
+	return *projectPathOption
+}
+
+// unityScriptContent returns the script running the Unity editor in batch mode, with the given command line arguments.
+// The editor is picked by the UNITY_EDITOR_VERSION env from the Unity Hub's install location, the default editor is used without it.
+func unityScriptContent(args ...string) string {
+	return `#!/usr/bin/env bash
+set -ex
+
+unity="/Applications/Unity/Unity.app/Contents/MacOS/Unity"
+if [[ -n "$` + editorVersionEnvKey + `" ]] ; then
+  unity="/Applications/Unity/Hub/Editor/$` + editorVersionEnvKey + `/Unity.app/Contents/MacOS/Unity"
+fi
+
+"$unity" -batchmode -quit -nographics -logFile - \
+  -projectPath "$` + projectPathInputEnvKey + `" \
+  -buildTarget "$` + buildTargetInputEnvKey + `" \
+  ` + strings.Join(args, " \\\n  ") + "\n"
+}
+
+// exportStepListItem returns the script step exporting the native project of the build target, by the project's build method.
+func exportStepListItem(exportPathEnvKey string) bitriseModels.StepListItemModel {
+	content := unityScriptContent(
+		`-executeMethod "$`+buildMethodEnvKey+`"`,
+		`-exportPath "$`+projectPathInputEnvKey+`/$`+exportPathEnvKey+`"`,
+	)
+	return steps.ScriptSteplistItem("Export the Unity project", envmanModels.EnvironmentItemModel{contentInputKey: content})
+}
+
+func generateConfig(descriptor configDescriptor) (string, error) {
+	configBuilder := models.NewDefaultConfigBuilder()
+
+	appEnvs := []envmanModels.EnvironmentItemModel{}
+	if descriptor.editorVersion != "" {
+		appEnvs = append(appEnvs, envmanModels.EnvironmentItemModel{editorVersionEnvKey: descriptor.editorVersion})
+	}
+
+	switch descriptor.target {
+	case iOSBuildTarget:
+		appEnvs = append(appEnvs,
+			envmanModels.EnvironmentItemModel{buildMethodEnvKey: defaultBuildMethod},
+			envmanModels.EnvironmentItemModel{xcodeExportPathEnvKey: xcodeExportPath},
+		)
+
+		configBuilder.AppendPreparStepList(steps.CertificateAndProfileInstallerStepListItem())
+		configBuilder.AppendMainStepList(
+			exportStepListItem(xcodeExportPathEnvKey),
+			steps.XcodeArchiveStepListItem(
+				envmanModels.EnvironmentItemModel{xcode.ProjectPathInputKey: "$" + projectPathInputEnvKey + "/$" + xcodeExportPathEnvKey + "/" + exportedXcodeProjectBasePath},
+				envmanModels.EnvironmentItemModel{xcode.SchemeInputKey: exportedXcodeScheme},
+			),
+		)
+	case androidBuildTarget:
+		appEnvs = append(appEnvs,
+			envmanModels.EnvironmentItemModel{buildMethodEnvKey: defaultBuildMethod},
+			envmanModels.EnvironmentItemModel{gradleExportPathEnvKey: gradleExportPath},
+		)
+
+		gradleProjectPath := "$" + projectPathInputEnvKey + "/$" + gradleExportPathEnvKey
+		configBuilder.AppendPreparStepList(steps.InstallMissingAndroidToolsStepListItem())
+		configBuilder.AppendMainStepList(
+			exportStepListItem(gradleExportPathEnvKey),
+			steps.GradleRunnerStepListItem(
+				envmanModels.EnvironmentItemModel{gradleFileInputKey: gradleProjectPath + "/build.gradle"},
+				envmanModels.EnvironmentItemModel{gradleTaskInputKey: "assembleRelease"},
+				envmanModels.EnvironmentItemModel{gradlewPathInputKey: gradleProjectPath + "/gradlew"},
+			),
+		)
+	default:
+		appEnvs = append(appEnvs, envmanModels.EnvironmentItemModel{standaloneBuildPathEnvKey: standaloneBuildPath})
+
+		content := unityScriptContent(`-buildOSXUniversalPlayer "$` + projectPathInputEnvKey + `/$` + standaloneBuildPathEnvKey + `"`)
+		configBuilder.AppendMainStepList(steps.ScriptSteplistItem("Build the Unity project", envmanModels.EnvironmentItemModel{contentInputKey: content}))
+	}
+
+	config, err := configBuilder.Generate(ScannerName, appEnvs...)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Configs ...
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
+	configMap := models.BitriseConfigMap{}
+	for _, descriptor := range scanner.configDescriptors {
+		config, err := generateConfig(descriptor)
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+		configMap[descriptor.configName()] = config
+	}
+
+	return configMap, nil
+}
+
+// DefaultConfigs ...
+func (scanner *Scanner) DefaultConfigs() (models.BitriseConfigMap, error) {
+	configMap := models.BitriseConfigMap{}
+	for _, target := range buildTargets {
+		config, err := generateConfig(configDescriptor{target: target})
+		if err != nil {
+			return models.BitriseConfigMap{}, err
+		}
+		configMap[defaultConfigName+"-"+target.configSuffix] = config
+	}
+
+	return configMap, nil
+}
@@ -1,22 +1,30 @@
 package xamarin
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"path/filepath"
-	"regexp"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/bitrise-core/bitrise-init/logger"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/steps"
 	"github.com/bitrise-core/bitrise-init/utility"
 	envmanModels "github.com/bitrise-io/envman/models"
-	"github.com/bitrise-io/go-utils/log"
 )
 
 const scannerName = "xamarin"
 
+// Diagnostic codes of the scanner.
+const (
+	SolutionConfigsFailedCode = "xamarin-solution-configs-failed"
+	NoSolutionConfigsCode     = "xamarin-no-solution-configs"
+	NoValidSolutionCode       = "xamarin-no-valid-solution"
+)
+
 const (
 	defaultConfigName = "default-xamarin-config"
 )
@@ -56,6 +64,16 @@ func configName(hasNugetPackages, hasXamarinComponents bool) string {
 	return name + "config"
 }
 
+// sortedConfigs returns the configs of the solution config map (config -> platforms) in alphabetical order.
+func sortedConfigs(configMap map[string][]string) []string {
+	configs := []string{}
+	for config := range configMap {
+		configs = append(configs, config)
+	}
+	sort.Strings(configs)
+	return configs
+}
+
 //--------------------------------------------------
 // Scanner
 //--------------------------------------------------
@@ -63,7 +81,7 @@ func configName(hasNugetPackages, hasXamarinComponents bool) string {
 // Scanner ...
 type Scanner struct {
 	SearchDir     string
-	FileList      []string
+	FileIndex     *utility.FileIndex
 	SolutionFiles []string
 
 	HasNugetPackages     bool
@@ -73,11 +91,16 @@ type Scanner struct {
 	HasAndroidProject bool
 	HasMacProject     bool
 	HasTVOSProject    bool
+
+	explanation models.Explanation
+	logger      logger.Logger
 }
 
 // NewScanner ...
 func NewScanner() *Scanner {
-	return &Scanner{}
+	return &Scanner{
+		logger: logger.NewDefaultLogger(),
+	}
 }
 
 // Name ...
@@ -85,125 +108,151 @@ func (scanner Scanner) Name() string {
 	return scannerName
 }
 
+// SetLogger ...
+func (scanner *Scanner) SetLogger(logger logger.Logger) {
+	scanner.logger = logger
+}
+
 // DetectPlatform ...
-func (scanner *Scanner) DetectPlatform(searchDir string) (bool, error) {
-	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
-	if err != nil {
-		return false, fmt.Errorf("failed to search for files in (%s), error: %s", searchDir, err)
-	}
-	scanner.FileList = fileList
+func (scanner *Scanner) DetectPlatform(ctx context.Context, fileIndex *utility.FileIndex) (bool, error) {
+	scanner.SearchDir = fileIndex.Root()
+	scanner.FileIndex = fileIndex
 
 	// Search for solution file
-	log.Infoft("Searching for solution files")
+	scanner.logger.Infoft("Searching for solution files")
 
-	solutionFiles, err := utility.FilterSolutionFiles(fileList)
+	candidates := fileIndex.ByExtension(".sln")
+	scanner.explanation = models.NewExplanation("solution files", candidates...)
+
+	solutionFiles, rejections, err := utility.ExplainSolutionFiles(candidates)
 	if err != nil {
 		return false, fmt.Errorf("failed to search for solution files, error: %s", err)
 	}
+	scanner.explanation.Rejected(rejections...)
 
 	scanner.SolutionFiles = solutionFiles
 
-	log.Printft("%d solution files detected", len(solutionFiles))
+	scanner.logger.Printft("%d solution files detected", len(solutionFiles))
 	for _, file := range solutionFiles {
-		log.Printft("- %s", file)
+		scanner.logger.Printft("- %s", file)
 	}
 
 	if len(solutionFiles) == 0 {
-		log.Printft("platform not detected")
+		scanner.explanation.Reason = "no solution file found"
+		scanner.logger.Printft("platform not detected")
 		return false, nil
 	}
 
-	log.Doneft("Platform detected")
+	scanner.explanation.Reason = fmt.Sprintf("solution file found: %s", strings.Join(solutionFiles, ", "))
+	scanner.logger.Doneft("Platform detected")
 
 	return true, nil
 }
 
-// ExcludedScannerNames ...
-func (scanner *Scanner) ExcludedScannerNames() []string {
-	return []string{}
+// Explanation ...
+func (scanner *Scanner) Explanation() models.Explanation {
+	return scanner.explanation
 }
 
-// Options ...
-func (scanner *Scanner) Options() (models.OptionModel, models.Warnings, error) {
-	log.Infoft("Searching for NuGet packages & Xamarin Components")
+// Confidence ...
+func (scanner *Scanner) Confidence() models.Confidence {
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "solution file found", scanner.SolutionFiles...),
+		models.DepthEvidence(scanner.SolutionFiles...),
+	}
 
-	warnings := models.Warnings{}
+	if packagesConfigs := scanner.FileIndex.ByBase("packages.config"); len(packagesConfigs) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "NuGet packages found", packagesConfigs...))
+	}
+	if projectFiles := scanner.FileIndex.ByExtension(".csproj"); len(projectFiles) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "C# project files found", projectFiles...))
+	}
 
-	for _, file := range scanner.FileList {
-		// Search for nuget packages
-		if !scanner.HasNugetPackages {
-			baseName := filepath.Base(file)
-			if baseName == "packages.config" {
-				scanner.HasNugetPackages = true
-			}
-		}
+	return models.NewConfidence(evidence...)
+}
 
-		// If adding a component:
-		// /Components/[COMPONENT_NAME]/ dir added
-		// ItemGroup/XamarinComponentReference added to the project
-		// packages.config added to the project's folder
-		if !scanner.HasXamarinComponents {
-			componentsExp := regexp.MustCompile(".*Components/.+")
-			if result := componentsExp.FindString(file); result != "" {
-				scanner.HasXamarinComponents = true
-			}
-		}
+// Relationships ...
+func (scanner *Scanner) Relationships() models.ScannerRelationships {
+	return models.ScannerRelationships{}
+}
 
-		if scanner.HasNugetPackages && scanner.HasXamarinComponents {
+// Options ...
+func (scanner *Scanner) Options(ctx context.Context) (models.OptionModel, models.Diagnostics, error) {
+	scanner.logger.Infoft("Searching for NuGet packages & Xamarin Components")
+
+	warnings := models.Diagnostics{}
+
+	// Search for nuget packages
+	scanner.HasNugetPackages = len(scanner.FileIndex.ByBase("packages.config")) > 0
+
+	// If adding a component:
+	// /Components/[COMPONENT_NAME]/ dir added
+	// ItemGroup/XamarinComponentReference added to the project
+	// packages.config added to the project's folder
+	for _, dir := range scanner.FileIndex.Directories() {
+		if strings.HasSuffix(filepath.Base(dir), "Components") && len(scanner.FileIndex.InDirectory(dir)) > 0 {
+			scanner.HasXamarinComponents = true
 			break
 		}
 	}
 
 	if scanner.HasNugetPackages {
-		log.Printft("Nuget packages found")
+		scanner.logger.Printft("Nuget packages found")
 	} else {
-		log.Printft("NO Nuget packages found")
+		scanner.logger.Printft("NO Nuget packages found")
 	}
 
 	if scanner.HasXamarinComponents {
-		log.Printft("Xamarin Components found")
+		scanner.logger.Printft("Xamarin Components found")
 	} else {
-		log.Printft("NO Xamarin Components found")
+		scanner.logger.Printft("NO Xamarin Components found")
 	}
 
 	// Check for solution configs
 	validSolutionMap := map[string]map[string][]string{}
 	for _, solutionFile := range scanner.SolutionFiles {
-		log.Infoft("Inspecting solution file: %s", solutionFile)
+		scanner.logger.Infoft("Inspecting solution file: %s", solutionFile)
 
-		configs, err := utility.GetSolutionConfigs(solutionFile)
+		configs, err := utility.GetSolutionConfigs(scanner.FileIndex.FS(), solutionFile)
 		if err != nil {
-			log.Warnft("Failed to get solution configs, error: %s", err)
-			warnings = append(warnings, fmt.Sprintf("Failed to get solution (%s) configs, error: %s", solutionFile, err))
+			scanner.logger.Warnft("Failed to get solution configs, error: %s", err)
+			warnings = append(warnings, models.NewWarning(SolutionConfigsFailedCode, fmt.Sprintf("Failed to get solution (%s) configs, error: %s", solutionFile, err), solutionFile))
 			continue
 		}
 
 		if len(configs) > 0 {
-			log.Printft("%d configurations found", len(configs))
-			for config, platforms := range configs {
-				log.Printft("- %s with platforms: %v", config, platforms)
+			scanner.logger.Printft("%d configurations found", len(configs))
+			for _, config := range sortedConfigs(configs) {
+				scanner.logger.Printft("- %s with platforms: %v", config, configs[config])
 			}
 
 			validSolutionMap[solutionFile] = configs
 		} else {
-			log.Warnft("No config found for %s", solutionFile)
-			warnings = append(warnings, fmt.Sprintf("No configs found for solution: %s", solutionFile))
+			scanner.logger.Warnft("No config found for %s", solutionFile)
+			warnings = append(warnings, models.NewWarning(NoSolutionConfigsCode, fmt.Sprintf("No configs found for solution: %s", solutionFile), solutionFile))
 		}
 	}
 
 	if len(validSolutionMap) == 0 {
-		log.Errorft("No valid solution file found")
-		return models.OptionModel{}, warnings, errors.New("No valid solution file found")
+		scanner.logger.Errorft("No valid solution file found")
+		return models.OptionModel{}, warnings, models.NewError(NoValidSolutionCode, "No valid solution file found")
 	}
 
 	// Check for solution projects
 	xamarinSolutionOption := models.NewOption(xamarinSolutionInputTitle, xamarinSolutionInputEnvKey)
 
-	for solutionFile, configMap := range validSolutionMap {
+	// the solutions keep the discovery order, the configs are ordered by name
+	for _, solutionFile := range scanner.SolutionFiles {
+		configMap, ok := validSolutionMap[solutionFile]
+		if !ok {
+			continue
+		}
+
 		xamarinConfigurationOption := models.NewOption(xamarinConfigurationInputTitle, xamarinConfigurationInputEnvKey)
 		xamarinSolutionOption.AddOption(solutionFile, xamarinConfigurationOption)
 
-		for config, platforms := range configMap {
+		for _, config := range sortedConfigs(configMap) {
+			platforms := configMap[config]
 			xamarinPlatformOption := models.NewOption(xamarinPlatformInputTitle, xamarinPlatformInputEnvKey)
 			xamarinConfigurationOption.AddOption(config, xamarinPlatformOption)
 
@@ -234,7 +283,7 @@ func (scanner *Scanner) DefaultOptions() models.OptionModel {
 }
 
 // Configs ...
-func (scanner *Scanner) Configs() (models.BitriseConfigMap, error) {
+func (scanner *Scanner) Configs(ctx context.Context) (models.BitriseConfigMap, error) {
 	configBuilder := models.NewDefaultConfigBuilder()
 
 	configBuilder.AppendPreparStepList(steps.CertificateAndProfileInstallerStepListItem())
@@ -1,18 +1,20 @@
 package xcode
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 
 	"path/filepath"
 
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/logger"
 	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-core/bitrise-init/steps"
 	"github.com/bitrise-core/bitrise-init/utility"
 	envmanModels "github.com/bitrise-io/envman/models"
-	"github.com/bitrise-io/go-utils/log"
-	"github.com/bitrise-io/go-utils/pathutil"
 	"github.com/bitrise-tools/go-xcode/xcodeproj"
 )
 
@@ -83,90 +85,175 @@ func (descriptor ConfigDescriptor) ConfigName(projectType utility.XcodeProjectTy
 }
 
 // Detect ...
-func Detect(projectType utility.XcodeProjectType, searchDir string) (bool, error) {
-	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
+func Detect(projectType utility.XcodeProjectType, fileIndex *utility.FileIndex, logger logger.Logger) (bool, error) {
+	projectFiles, _, err := DetectProjectFiles(projectType, fileIndex, logger)
 	if err != nil {
 		return false, err
 	}
+	return len(projectFiles) > 0, nil
+}
+
+// DetectProjectFiles returns the relevant Xcode project files of the given project type, the platform is detected if any found.
+// The explanation lists the rejected project files with the filters, which rejected them.
+func DetectProjectFiles(projectType utility.XcodeProjectType, fileIndex *utility.FileIndex, logger logger.Logger) ([]string, models.Explanation, error) {
+	logger.Infoft("Filter relevant Xcode project files")
 
-	log.Infoft("Filter relevant Xcode project files")
+	candidates := fileIndex.ByExtension(xcodeproj.XCodeProjExt)
+	explanation := models.NewExplanation(fmt.Sprintf("Xcode projects with %s SDK", sdkName(projectType)), candidates...)
 
-	relevantXcodeprojectFiles, err := utility.FilterRelevantProjectFiles(fileList, projectType)
+	relevantXcodeprojectFiles, rejections, err := utility.ExplainRelevantProjectFiles(fileIndex.FS(), candidates, projectType)
 	if err != nil {
-		return false, err
+		return nil, explanation, err
 	}
+	explanation.Rejected(rejections...)
 
-	log.Printft("%d Xcode %s project files found", len(relevantXcodeprojectFiles), string(projectType))
+	logger.Printft("%d Xcode %s project files found", len(relevantXcodeprojectFiles), string(projectType))
 	for _, xcodeprojectFile := range relevantXcodeprojectFiles {
-		log.Printft("- %s", xcodeprojectFile)
+		logger.Printft("- %s", xcodeprojectFile)
 	}
 
 	if len(relevantXcodeprojectFiles) == 0 {
-		log.Printft("platform not detected")
-		return false, nil
+		explanation.Reason = fmt.Sprintf("no relevant Xcode %s project found", string(projectType))
+		logger.Printft("platform not detected")
+		return nil, explanation, nil
 	}
 
-	log.Doneft("Platform detected")
+	explanation.Reason = fmt.Sprintf("Xcode %s project found: %s", string(projectType), strings.Join(relevantXcodeprojectFiles, ", "))
+	logger.Doneft("Platform detected")
+
+	return relevantXcodeprojectFiles, explanation, nil
+}
+
+func sdkName(projectType utility.XcodeProjectType) string {
+	if projectType == utility.XcodeProjectTypeMacOS {
+		return "macosx"
+	}
+	return "iphoneos"
+}
+
+// Confidence returns the detection confidence of the given (detected) Xcode project files:
+// the shared schemes and the dependency manager lock files in the project directories support the detection.
+func Confidence(projectFiles []string, fileIndex *utility.FileIndex) models.Confidence {
+	evidence := []models.Evidence{
+		models.NewEvidence(models.ProjectFileWeight, "Xcode project found", projectFiles...),
+		models.DepthEvidence(projectFiles...),
+	}
+
+	schemes := []string{}
+	for _, scheme := range fileIndex.ByExtension(".xcscheme") {
+		if !strings.Contains(filepath.ToSlash(scheme), "/xcshareddata/xcschemes/") {
+			continue
+		}
+		for _, projectFile := range projectFiles {
+			if strings.HasPrefix(scheme, projectFile+string(filepath.Separator)) {
+				schemes = append(schemes, scheme)
+				break
+			}
+		}
+	}
+	if len(schemes) > 0 {
+		evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, "shared schemes found", schemes...))
+	}
+
+	projectDirs := map[string]bool{}
+	for _, projectFile := range projectFiles {
+		projectDirs[filepath.Dir(projectFile)] = true
+	}
+	for _, lockFile := range []struct {
+		name        string
+		description string
+	}{
+		{"Podfile.lock", "CocoaPods lock file found"},
+		{"Cartfile.resolved", "Carthage lock file found"},
+	} {
+		lockFiles := []string{}
+		for _, pth := range fileIndex.ByBase(lockFile.name) {
+			if projectDirs[filepath.Dir(pth)] {
+				lockFiles = append(lockFiles, pth)
+			}
+		}
+		if len(lockFiles) > 0 {
+			evidence = append(evidence, models.NewEvidence(models.SupportingFileWeight, lockFile.description, lockFiles...))
+		}
+	}
 
-	return true, nil
+	return models.NewConfidence(evidence...)
 }
 
-func printMissingSharedSchemesAndGenerateWarning(projectPth, defaultGitignorePth string, targets []xcodeproj.TargetModel) string {
+// Diagnostic codes of the xcode based scanners.
+const (
+	NoSharedSchemesCode          = "xcode-no-shared-schemes"
+	CartfileResolvedNotFoundCode = "xcode-cartfile-resolved-not-found"
+	NoValidConfigCode            = "xcode-no-valid-config"
+)
+
+const (
+	sharedSchemesDocURL    = "http://devcenter.bitrise.io/ios/frequent-ios-issues/#xcode-scheme-not-found"
+	cartfileResolvedDocURL = "https://github.com/Carthage/Carthage/blob/master/Documentation/Artifacts.md#cartfileresolved"
+)
+
+func printMissingSharedSchemesAndGenerateWarning(fs filesystem.FileSystem, projectPth, defaultGitignorePth string, targets []xcodeproj.TargetModel, logger logger.Logger) models.Diagnostic {
 	isXcshareddataGitignored := false
-	if exist, err := pathutil.IsPathExists(defaultGitignorePth); err != nil {
-		log.Warnft("Failed to check if .gitignore file exists at: %s, error: %s", defaultGitignorePth, err)
+	if exist, err := filesystem.IsPathExists(fs, defaultGitignorePth); err != nil {
+		logger.Warnft("Failed to check if .gitignore file exists at: %s, error: %s", defaultGitignorePth, err)
 	} else if exist {
-		isGitignored, err := utility.FileContains(defaultGitignorePth, "xcshareddata")
+		isGitignored, err := utility.FileContains(fs, defaultGitignorePth, "xcshareddata")
 		if err != nil {
-			log.Warnft("Failed to check if xcshareddata gitignored, error: %s", err)
+			logger.Warnft("Failed to check if xcshareddata gitignored, error: %s", err)
 		} else {
 			isXcshareddataGitignored = isGitignored
 		}
 	}
 
-	log.Printft("")
-	log.Errorft("No shared schemes found, adding recreate-user-schemes step...")
-	log.Errorft("The newly generated schemes may differ from the ones in your project.")
+	logger.Printft("")
+	logger.Errorft("No shared schemes found, adding recreate-user-schemes step...")
+	logger.Errorft("The newly generated schemes may differ from the ones in your project.")
 
 	message := `No shared schemes found for project: ` + projectPth + `.` + "\n"
+	paths := []string{projectPth}
 
 	if isXcshareddataGitignored {
-		log.Errorft("Your gitignore file (%s) contains 'xcshareddata', maybe shared schemes are gitignored?", defaultGitignorePth)
-		log.Errorft("If not, make sure to share your schemes, to have the expected behaviour.")
+		logger.Errorft("Your gitignore file (%s) contains 'xcshareddata', maybe shared schemes are gitignored?", defaultGitignorePth)
+		logger.Errorft("If not, make sure to share your schemes, to have the expected behaviour.")
 
 		message += `Your gitignore file (` + defaultGitignorePth + `) contains 'xcshareddata', maybe shared schemes are gitignored?` + "\n"
+		paths = append(paths, defaultGitignorePth)
 	} else {
-		log.Errorft("Make sure to share your schemes, to have the expected behaviour.")
+		logger.Errorft("Make sure to share your schemes, to have the expected behaviour.")
 	}
 
+	legacyMessage := message + `Automatically generated schemes may differ from the ones in your project.
+Make sure to <a href="` + sharedSchemesDocURL + `">share your schemes</a> for the expected behaviour.`
 	message += `Automatically generated schemes may differ from the ones in your project.
-Make sure to <a href="http://devcenter.bitrise.io/ios/frequent-ios-issues/#xcode-scheme-not-found">share your schemes</a> for the expected behaviour.`
+Make sure to share your schemes for the expected behaviour.`
 
-	log.Printft("")
+	logger.Printft("")
 
-	log.Warnft("%d user schemes will be generated", len(targets))
+	logger.Warnft("%d user schemes will be generated", len(targets))
 	for _, target := range targets {
-		log.Warnft("- %s", target.Name)
+		logger.Warnft("- %s", target.Name)
 	}
 
-	log.Printft("")
+	logger.Printft("")
 
-	return message
+	return models.NewWarning(NoSharedSchemesCode, message, paths...).WithDocURL(sharedSchemesDocURL).WithLegacyMessage(legacyMessage)
 }
 
-func detectCarthageCommand(projectPth string) (string, string) {
+func detectCarthageCommand(fs filesystem.FileSystem, projectPth string) (string, *models.Diagnostic) {
 	carthageCommand := ""
-	warning := ""
+	var warning *models.Diagnostic
 
-	if utility.HasCartfileInDirectoryOf(projectPth) {
-		if utility.HasCartfileResolvedInDirectoryOf(projectPth) {
+	if utility.HasCartfileInDirectoryOf(fs, projectPth) {
+		if utility.HasCartfileResolvedInDirectoryOf(fs, projectPth) {
 			carthageCommand = "bootstrap"
 		} else {
 			dir := filepath.Dir(projectPth)
 			cartfilePth := filepath.Join(dir, "Cartfile")
 
-			warning = fmt.Sprintf(`Cartfile found at (%s), but no Cartfile.resolved exists in the same directory.
-It is <a href="https://github.com/Carthage/Carthage/blob/master/Documentation/Artifacts.md#cartfileresolved">strongly recommended to commit this file to your repository</a>`, cartfilePth)
+			diagnostic := models.NewWarning(CartfileResolvedNotFoundCode, fmt.Sprintf(`Cartfile found at (%s), but no Cartfile.resolved exists in the same directory.
+It is strongly recommended to commit this file to your repository.`, cartfilePth), cartfilePth).WithDocURL(cartfileResolvedDocURL).WithLegacyMessage(fmt.Sprintf(`Cartfile found at (%s), but no Cartfile.resolved exists in the same directory.
+It is <a href="%s">strongly recommended to commit this file to your repository</a>`, cartfilePth, cartfileResolvedDocURL))
+			warning = &diagnostic
 
 			carthageCommand = "update"
 		}
@@ -176,93 +263,95 @@ It is <a href="https://github.com/Carthage/Carthage/blob/master/Documentation/Ar
 }
 
 // GenerateOptions ...
-func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (models.OptionModel, []ConfigDescriptor, models.Warnings, error) {
-	warnings := models.Warnings{}
+// The projects are read through the fileIndex's file system, the options and diagnostics contain paths relative to the search dir.
+func GenerateOptions(ctx context.Context, projectType utility.XcodeProjectType, fileIndex *utility.FileIndex, logger logger.Logger) (models.OptionModel, []ConfigDescriptor, models.Diagnostics, error) {
+	return GenerateOptionsUnder(ctx, projectType, fileIndex, "", logger)
+}
 
-	fileList, err := utility.ListPathInDirSortedByComponents(searchDir, true)
-	if err != nil {
-		return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
-	}
+// GenerateOptionsUnder generates the options of the projects inside the given (search dir relative) directory, like GenerateOptions does,
+// an empty dir stands for the search dir. It is used by the scanners, which embed an Xcode project, like the ios dir of a React Native project.
+func GenerateOptionsUnder(ctx context.Context, projectType utility.XcodeProjectType, fileIndex *utility.FileIndex, dir string, logger logger.Logger) (models.OptionModel, []ConfigDescriptor, models.Diagnostics, error) {
+	warnings := models.Diagnostics{}
+	fs := fileIndex.FS()
 
 	// Separate workspaces and standalon projects
-	projectFiles, err := utility.FilterRelevantProjectFiles(fileList, projectType)
+	projectFiles, err := utility.FilterRelevantProjectFiles(fs, utility.PathsUnder(fileIndex.ByExtension(xcodeproj.XCodeProjExt), dir), projectType)
 	if err != nil {
-		return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
+		return models.OptionModel{}, []ConfigDescriptor{}, models.Diagnostics{}, err
 	}
 
-	workspaceFiles, err := utility.FilterRelevantWorkspaceFiles(fileList, projectType)
+	workspaceFiles, err := utility.FilterRelevantWorkspaceFiles(fs, utility.PathsUnder(fileIndex.ByExtension(xcodeproj.XCWorkspaceExt), dir), projectType)
 	if err != nil {
-		return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
+		return models.OptionModel{}, []ConfigDescriptor{}, models.Diagnostics{}, err
 	}
 
-	standaloneProjects, workspaces, err := utility.CreateStandaloneProjectsAndWorkspaces(projectFiles, workspaceFiles)
+	standaloneProjects, workspaces, err := utility.CreateStandaloneProjectsAndWorkspaces(fs, projectFiles, workspaceFiles)
 	if err != nil {
-		return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
+		return models.OptionModel{}, []ConfigDescriptor{}, models.Diagnostics{}, err
 	}
 
 	// Create cocoapods workspace-project mapping
-	log.Infoft("Searching for Podfile")
+	logger.Infoft("Searching for Podfile")
 
-	podfiles, err := utility.FilterRelevantPodfiles(fileList)
+	podfiles, err := utility.FilterRelevantPodfiles(utility.PathsUnder(fileIndex.ByBase("Podfile"), dir))
 	if err != nil {
-		return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
+		return models.OptionModel{}, []ConfigDescriptor{}, models.Diagnostics{}, err
 	}
 
-	log.Printft("%d Podfiles detected", len(podfiles))
+	logger.Printft("%d Podfiles detected", len(podfiles))
 
 	for _, podfile := range podfiles {
-		log.Printft("- %s", podfile)
+		logger.Printft("- %s", podfile)
 
-		workspaceProjectMap, err := utility.GetWorkspaceProjectMap(podfile, projectFiles)
+		workspaceProjectMap, err := utility.GetWorkspaceProjectMap(ctx, fs, podfile, projectFiles)
 		if err != nil {
-			return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
+			return models.OptionModel{}, []ConfigDescriptor{}, models.Diagnostics{}, err
 		}
 
 		standaloneProjects, workspaces, err = utility.MergePodWorkspaceProjectMap(workspaceProjectMap, standaloneProjects, workspaces)
 		if err != nil {
-			return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
+			return models.OptionModel{}, []ConfigDescriptor{}, models.Diagnostics{}, err
 		}
 	}
 
 	// Carthage
-	log.Infoft("Searching for Cartfile")
+	logger.Infoft("Searching for Cartfile")
 
-	cartfiles, err := utility.FilterRelevantCartFile(fileList)
+	cartfiles, err := utility.FilterRelevantCartFile(utility.PathsUnder(fileIndex.ByBase("Cartfile"), dir))
 	if err != nil {
-		return models.OptionModel{}, []ConfigDescriptor{}, models.Warnings{}, err
+		return models.OptionModel{}, []ConfigDescriptor{}, models.Diagnostics{}, err
 	}
 
-	log.Printft("%d Cartfiles detected", len(cartfiles))
+	logger.Printft("%d Cartfiles detected", len(cartfiles))
 	for _, file := range cartfiles {
-		log.Printft("- %s", file)
+		logger.Printft("- %s", file)
 	}
 
 	// Create config descriptors & options
 	configDescriptors := []ConfigDescriptor{}
 
-	defaultGitignorePth := filepath.Join(searchDir, ".gitignore")
+	defaultGitignorePth := utility.GitignoreFileName
 
 	projectPathOption := models.NewOption(ProjectPathInputTitle, ProjectPathInputEnvKey)
 
 	// Standalon Projects
 	for _, project := range standaloneProjects {
-		log.Infoft("Inspecting standalone project file: %s", project.Pth)
+		projectPth := project.Pth
+		logger.Infoft("Inspecting standalone project file: %s", projectPth)
 
 		schemeOption := models.NewOption(SchemeInputTitle, SchemeInputEnvKey)
-		projectPathOption.AddOption(project.Pth, schemeOption)
+		projectPathOption.AddOption(projectPth, schemeOption)
 
-		carthageCommand, warning := detectCarthageCommand(project.Pth)
-		if warning != "" {
-			warnings = append(warnings, warning)
+		carthageCommand, warning := detectCarthageCommand(fs, project.Pth)
+		if warning != nil {
+			warnings = append(warnings, *warning)
 		}
 
-		log.Printft("%d shared schemes detected", len(project.SharedSchemes))
+		logger.Printft("%d shared schemes detected", len(project.SharedSchemes))
 
 		if len(project.SharedSchemes) == 0 {
-			message := printMissingSharedSchemesAndGenerateWarning(project.Pth, defaultGitignorePth, project.Targets)
-			if message != "" {
-				warnings = append(warnings, message)
-			}
+			warning := printMissingSharedSchemesAndGenerateWarning(fs, projectPth, defaultGitignorePth, project.Targets, logger)
+			warnings = append(warnings, warning)
 
 			for _, target := range project.Targets {
 				configDescriptor := NewConfigDescriptor(false, carthageCommand, target.HasXCTest, true)
@@ -273,7 +362,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 			}
 		} else {
 			for _, scheme := range project.SharedSchemes {
-				log.Printft("- %s", scheme.Name)
+				logger.Printft("- %s", scheme.Name)
 
 				configDescriptor := NewConfigDescriptor(false, carthageCommand, scheme.HasXCTest, false)
 				configDescriptors = append(configDescriptors, configDescriptor)
@@ -286,26 +375,25 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 
 	// Workspaces
 	for _, workspace := range workspaces {
-		log.Infoft("Inspecting workspace file: %s", workspace.Pth)
+		workspacePth := workspace.Pth
+		logger.Infoft("Inspecting workspace file: %s", workspacePth)
 
 		schemeOption := models.NewOption(SchemeInputTitle, SchemeInputEnvKey)
-		projectPathOption.AddOption(workspace.Pth, schemeOption)
+		projectPathOption.AddOption(workspacePth, schemeOption)
 
-		carthageCommand, warning := detectCarthageCommand(workspace.Pth)
-		if warning != "" {
-			warnings = append(warnings, warning)
+		carthageCommand, warning := detectCarthageCommand(fs, workspace.Pth)
+		if warning != nil {
+			warnings = append(warnings, *warning)
 		}
 
 		sharedSchemes := workspace.GetSharedSchemes()
-		log.Printft("%d shared schemes detected", len(sharedSchemes))
+		logger.Printft("%d shared schemes detected", len(sharedSchemes))
 
 		if len(sharedSchemes) == 0 {
 			targets := workspace.GetTargets()
 
-			message := printMissingSharedSchemesAndGenerateWarning(workspace.Pth, defaultGitignorePth, targets)
-			if message != "" {
-				warnings = append(warnings, message)
-			}
+			warning := printMissingSharedSchemesAndGenerateWarning(fs, workspacePth, defaultGitignorePth, targets, logger)
+			warnings = append(warnings, warning)
 
 			for _, target := range targets {
 				configDescriptor := NewConfigDescriptor(workspace.IsPodWorkspace, carthageCommand, target.HasXCTest, true)
@@ -316,7 +404,7 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 			}
 		} else {
 			for _, scheme := range sharedSchemes {
-				log.Printft("- %s", scheme.Name)
+				logger.Printft("- %s", scheme.Name)
 
 				configDescriptor := NewConfigDescriptor(workspace.IsPodWorkspace, carthageCommand, scheme.HasXCTest, false)
 				configDescriptors = append(configDescriptors, configDescriptor)
@@ -330,8 +418,8 @@ func GenerateOptions(projectType utility.XcodeProjectType, searchDir string) (mo
 	configDescriptors = plain(configDescriptors, projectType)
 
 	if len(configDescriptors) == 0 {
-		log.Errorft("No valid %s config found", string(projectType))
-		return models.OptionModel{}, []ConfigDescriptor{}, warnings, fmt.Errorf("No valid %s config found", string(projectType))
+		logger.Errorft("No valid %s config found", string(projectType))
+		return models.OptionModel{}, []ConfigDescriptor{}, warnings, models.NewErrorf(NoValidConfigCode, "No valid %s config found", string(projectType))
 	}
 
 	return *projectPathOption, configDescriptors, warnings, nil
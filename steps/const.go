@@ -159,6 +159,13 @@ const (
 	CordovaArchiveVersion = "0.9.1"
 )
 
+const (
+	// IonicArchiveID ...
+	IonicArchiveID = "ionic-archive"
+	// IonicArchiveVersion ...
+	IonicArchiveVersion = "1.1.0"
+)
+
 const (
 	// GenerateCordovaBuildConfigID ...
 	GenerateCordovaBuildConfigID = "generate-cordova-build-configuration"
@@ -179,3 +186,59 @@ const (
 	// KarmaJasmineTestRunnerVersion ...
 	KarmaJasmineTestRunnerVersion = "0.9.1"
 )
+
+const (
+	// NpmID ...
+	NpmID = "npm"
+	// NpmVersion ...
+	NpmVersion = "0.9.0"
+)
+
+const (
+	// YarnID ...
+	YarnID = "yarn"
+	// YarnVersion ...
+	YarnVersion = "0.0.7"
+)
+
+const (
+	// FlutterInstallerID ...
+	FlutterInstallerID = "flutter-installer"
+	// FlutterInstallerVersion ...
+	FlutterInstallerVersion = "0.11.0"
+)
+
+const (
+	// FlutterAnalyzeID ...
+	FlutterAnalyzeID = "flutter-analyze"
+	// FlutterAnalyzeVersion ...
+	FlutterAnalyzeVersion = "0.1.0"
+)
+
+const (
+	// FlutterTestID ...
+	FlutterTestID = "flutter-test"
+	// FlutterTestVersion ...
+	FlutterTestVersion = "0.9.1"
+)
+
+const (
+	// FlutterBuildID ...
+	FlutterBuildID = "flutter-build"
+	// FlutterBuildVersion ...
+	FlutterBuildVersion = "0.9.2"
+)
+
+const (
+	// CachePullID ...
+	CachePullID = "cache-pull"
+	// CachePullVersion ...
+	CachePullVersion = "2.1.1"
+)
+
+const (
+	// CachePushID ...
+	CachePushID = "cache-push"
+	// CachePushVersion ...
+	CachePushVersion = "2.2.0"
+)
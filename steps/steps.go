@@ -182,6 +182,12 @@ func CordovaArchiveStepListItem(inputs ...envmanModels.EnvironmentItemModel) bit
 	return stepListItem(stepIDComposite, "", "", inputs...)
 }
 
+// IonicArchiveStepListItem ...
+func IonicArchiveStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(IonicArchiveID, IonicArchiveVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
 // GenerateCordovaBuildConfigStepListItem ...
 func GenerateCordovaBuildConfigStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
 	stepIDComposite := stepIDComposite(GenerateCordovaBuildConfigID, GenerateCordovaBuildConfigVersion)
@@ -199,3 +205,51 @@ func KarmaJasmineTestRunnerStepListItem(inputs ...envmanModels.EnvironmentItemMo
 	stepIDComposite := stepIDComposite(KarmaJasmineTestRunnerID, KarmaJasmineTestRunnerVersion)
 	return stepListItem(stepIDComposite, "", "", inputs...)
 }
+
+// NpmStepListItem ...
+func NpmStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(NpmID, NpmVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// YarnStepListItem ...
+func YarnStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(YarnID, YarnVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// FlutterInstallerStepListItem ...
+func FlutterInstallerStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(FlutterInstallerID, FlutterInstallerVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// FlutterAnalyzeStepListItem ...
+func FlutterAnalyzeStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(FlutterAnalyzeID, FlutterAnalyzeVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// FlutterTestStepListItem ...
+func FlutterTestStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(FlutterTestID, FlutterTestVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// FlutterBuildStepListItem ...
+func FlutterBuildStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(FlutterBuildID, FlutterBuildVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
+
+// CachePullStepListItem ...
+func CachePullStepListItem() bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(CachePullID, CachePullVersion)
+	return stepListItem(stepIDComposite, "", "")
+}
+
+// CachePushStepListItem ...
+func CachePushStepListItem(inputs ...envmanModels.EnvironmentItemModel) bitriseModels.StepListItemModel {
+	stepIDComposite := stepIDComposite(CachePushID, CachePushVersion)
+	return stepListItem(stepIDComposite, "", "", inputs...)
+}
@@ -0,0 +1,237 @@
+package utility
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
+var (
+	zipMagic       = []byte("PK\x03\x04")
+	emptyZipMagic  = []byte("PK\x05\x06")
+	gzipMagic      = []byte{0x1f, 0x8b}
+	gitBundleMagic = [][]byte{[]byte("# v2 git bundle\n"), []byte("# v3 git bundle\n")}
+)
+
+const (
+	tarMagicOffset = 257
+	tarMagic       = "ustar"
+)
+
+// ArchiveKind ...
+type ArchiveKind string
+
+// ArchiveKinds ...
+const (
+	ZipArchive       ArchiveKind = "zip"
+	TarArchive       ArchiveKind = "tar"
+	GzipTarArchive   ArchiveKind = "tar.gz"
+	GitBundleArchive ArchiveKind = "git bundle"
+)
+
+// DetectArchiveKind returns the kind of the archive at the given path, based on its content.
+// It returns false if the file is not a supported archive.
+func DetectArchiveKind(pth string) (ArchiveKind, bool, error) {
+	file, err := os.Open(pth)
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, tarMagicOffset+len(tarMagic))
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", false, err
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, zipMagic), bytes.HasPrefix(header, emptyZipMagic):
+		return ZipArchive, true, nil
+	case bytes.HasPrefix(header, gzipMagic):
+		return GzipTarArchive, true, nil
+	case len(header) == tarMagicOffset+len(tarMagic) && string(header[tarMagicOffset:]) == tarMagic:
+		return TarArchive, true, nil
+	}
+	for _, magic := range gitBundleMagic {
+		if bytes.HasPrefix(header, magic) {
+			return GitBundleArchive, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// OpenArchive reads the zip, tar, tar.gz archive or git bundle at the given path into an in-memory file system,
+// nothing is extracted into the scanned directory, the paths are relative to the archive root.
+func OpenArchive(ctx context.Context, pth string) (*filesystem.Archive, error) {
+	kind, ok, err := DetectArchiveKind(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive (%s), error: %s", pth, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("not a supported archive (%s), supported: zip, tar, tar.gz, git bundle", pth)
+	}
+
+	var archive *filesystem.Archive
+	switch kind {
+	case ZipArchive:
+		archive, err = filesystem.OpenZip(pth)
+	case TarArchive, GzipTarArchive:
+		archive, err = filesystem.OpenTarball(pth)
+	case GitBundleArchive:
+		archive, err = OpenGitBundle(ctx, pth)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s (%s), error: %s", kind, pth, err)
+	}
+	return archive, nil
+}
+
+// OpenGitBundle reads the tree of the git bundle's HEAD (or its first ref, if HEAD is not bundled) into an in-memory file system.
+// The bundle is cloned into a temporary bare repository, no work tree is checked out,
+// the blobs are streamed from git into the archive, within the limits of the archives.
+func OpenGitBundle(ctx context.Context, pth string) (*filesystem.Archive, error) {
+	absPth, err := pathutil.AbsPath(pth)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__git_bundle__")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tmp dir, error: %s", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.Errorft("Failed to remove tmp dir (%s), error: %s", tmpDir, err)
+		}
+	}()
+
+	heads, err := runGit(ctx, tmpDir, nil, "bundle", "list-heads", absPth)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := bundleHead(string(heads))
+	if err != nil {
+		return nil, err
+	}
+
+	repoDir := filepath.Join(tmpDir, "repo.git")
+	if _, err := runGit(ctx, tmpDir, nil, "clone", "--bare", "--quiet", absPth, repoDir); err != nil {
+		return nil, err
+	}
+
+	tree, err := runGit(ctx, repoDir, nil, "ls-tree", "-r", "-z", "--full-tree", commit)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := filesystem.NewArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := archive.ReadGitTree(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := catGitObjects(ctx, repoDir, entries, func(reader io.Reader) error {
+		return archive.ReadGitObjects(reader, entries)
+	}); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// bundleHead returns the commit of the HEAD ref in the git bundle list-heads output, or its first ref.
+func bundleHead(heads string) (string, error) {
+	commit := ""
+	for _, line := range strings.Split(heads, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if fields[1] == "HEAD" {
+			return fields[0], nil
+		}
+		if commit == "" {
+			commit = fields[0]
+		}
+	}
+	if commit == "" {
+		return "", fmt.Errorf("no refs found in the git bundle")
+	}
+	return commit, nil
+}
+
+// catGitObjects runs git cat-file --batch on the objects of the given entries,
+// its output is streamed to the read function, instead of reading it into the memory.
+func catGitObjects(ctx context.Context, repoDir string, entries []filesystem.GitTreeEntry, read func(io.Reader) error) error {
+	var objects bytes.Buffer
+	for _, entry := range entries {
+		objects.WriteString(entry.Object + "\n")
+	}
+
+	cmd, cmdCtx, cancel := NewCommandContext(ctx, "git", "cat-file", "--batch")
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd.SetDir(repoDir)
+	cmd.SetStdin(&objects)
+	cmd.SetStderr(&stderr)
+
+	stdout, err := cmd.GetCmd().StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.GetCmd().Start(); err != nil {
+		return fmt.Errorf("command (%s) failed, error: %s", cmd.PrintableCommandArgs(), err)
+	}
+
+	if err := read(stdout); err != nil {
+		// the rest of the output is not read, git is killed instead of blocking on writing it
+		cancel()
+		// the wait error is the result of the kill
+		_ = cmd.GetCmd().Wait()
+		return err
+	}
+
+	if err := cmd.GetCmd().Wait(); err != nil {
+		if cmdCtx.Err() != nil {
+			return CommandContextError(cmdCtx, cmd, err)
+		}
+		return fmt.Errorf("command (%s) failed, output: %s, error: %s", cmd.PrintableCommandArgs(), strings.TrimSpace(stderr.String()), err)
+	}
+	return nil
+}
+
+// runGit runs the git command in the given dir and returns its output.
+func runGit(ctx context.Context, dir string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd, cmdCtx, cancel := NewCommandContext(ctx, "git", args...)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetDir(dir)
+	cmd.SetStdout(&stdout)
+	cmd.SetStderr(&stderr)
+	if stdin != nil {
+		cmd.SetStdin(stdin)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if cmdCtx.Err() != nil {
+			return nil, CommandContextError(cmdCtx, cmd, err)
+		}
+		return nil, fmt.Errorf("command (%s) failed, output: %s, error: %s", cmd.PrintableCommandArgs(), strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.Bytes(), nil
+}
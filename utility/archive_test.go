@@ -0,0 +1,101 @@
+package utility
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-io/go-utils/command"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenArchive(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__archive_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	repoDir := filepath.Join(tmpDir, "repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "app"), 0700))
+	require.NoError(t, fileutil.WriteStringToFile(filepath.Join(repoDir, "app", "build.gradle"), "app"))
+	require.NoError(t, fileutil.WriteStringToFile(filepath.Join(repoDir, ".gitattributes"), "app export-ignore"))
+	require.NoError(t, os.Symlink(filepath.Join("app", "build.gradle"), filepath.Join(repoDir, "build.gradle")))
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "--all"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "initial"},
+		{"bundle", "create", "--quiet", filepath.Join(tmpDir, "repo.bundle"), "--all"},
+	} {
+		cmd := command.New("git", args...).SetDir(repoDir)
+		out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+		require.NoError(t, err, out)
+	}
+
+	t.Log("git bundle")
+	{
+		kind, ok, err := DetectArchiveKind(filepath.Join(tmpDir, "repo.bundle"))
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, GitBundleArchive, kind)
+
+		archive, err := OpenArchive(context.Background(), filepath.Join(tmpDir, "repo.bundle"))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(archive.SkippedEntries))
+
+		content, err := filesystem.ReadStringFromFile(archive, "app/build.gradle")
+		require.NoError(t, err)
+		require.Equal(t, "app", content)
+
+		content, err = filesystem.ReadStringFromFile(archive, "build.gradle")
+		require.NoError(t, err)
+		require.Equal(t, "app", content)
+
+		info, err := archive.Lstat("build.gradle")
+		require.NoError(t, err)
+		require.True(t, info.Mode()&os.ModeSymlink != 0)
+	}
+
+	t.Log("git bundle with an entry over the size limit")
+	{
+		bigRepoDir := filepath.Join(tmpDir, "big")
+		require.NoError(t, os.MkdirAll(bigRepoDir, 0700))
+		require.NoError(t, fileutil.WriteStringToFile(filepath.Join(bigRepoDir, "build.gradle"), "app"))
+		// the entry size limit of the archives is 64 MB
+		require.NoError(t, fileutil.WriteBytesToFile(filepath.Join(bigRepoDir, "big.bin"), make([]byte, 64<<20+1)))
+
+		for _, args := range [][]string{
+			{"init", "--quiet"},
+			{"add", "--all"},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "initial"},
+			{"bundle", "create", "--quiet", filepath.Join(tmpDir, "big.bundle"), "--all"},
+		} {
+			cmd := command.New("git", args...).SetDir(bigRepoDir)
+			out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+			require.NoError(t, err, out)
+		}
+
+		archive, err := OpenArchive(context.Background(), filepath.Join(tmpDir, "big.bundle"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"big.bin"}, archive.SkippedEntries)
+
+		content, err := filesystem.ReadStringFromFile(archive, "build.gradle")
+		require.NoError(t, err)
+		require.Equal(t, "app", content)
+	}
+
+	t.Log("not an archive")
+	{
+		_, ok, err := DetectArchiveKind(filepath.Join(repoDir, "app", "build.gradle"))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		_, err = OpenArchive(context.Background(), filepath.Join(repoDir, "app", "build.gradle"))
+		require.Error(t, err)
+	}
+}
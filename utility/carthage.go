@@ -3,7 +3,7 @@ package utility
 import (
 	"path/filepath"
 
-	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
 )
 
 const cartfileBase = "Cartfile"
@@ -13,10 +13,10 @@ const cartfileResolvedBase = "Cartfile.resolved"
 var AllowCartfileBaseFilter = BaseFilter(cartfileBase, true)
 
 // HasCartfileInDirectoryOf ...
-func HasCartfileInDirectoryOf(pth string) bool {
+func HasCartfileInDirectoryOf(fs filesystem.FileSystem, pth string) bool {
 	dir := filepath.Dir(pth)
 	cartfilePth := filepath.Join(dir, cartfileBase)
-	exist, err := pathutil.IsPathExists(cartfilePth)
+	exist, err := filesystem.IsPathExists(fs, cartfilePth)
 	if err != nil {
 		return false
 	}
@@ -24,10 +24,10 @@ func HasCartfileInDirectoryOf(pth string) bool {
 }
 
 // HasCartfileResolvedInDirectoryOf ...
-func HasCartfileResolvedInDirectoryOf(pth string) bool {
+func HasCartfileResolvedInDirectoryOf(fs filesystem.FileSystem, pth string) bool {
 	dir := filepath.Dir(pth)
 	cartfileResolvedPth := filepath.Join(dir, cartfileResolvedBase)
-	exist, err := pathutil.IsPathExists(cartfileResolvedPth)
+	exist, err := filesystem.IsPathExists(fs, cartfileResolvedPth)
 	if err != nil {
 		return false
 	}
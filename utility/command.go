@@ -0,0 +1,67 @@
+package utility
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/events"
+	"github.com/bitrise-io/go-utils/command"
+)
+
+// DefaultSubprocessTimeout is the default time budget of a single external tool call, like a ruby script run.
+const DefaultSubprocessTimeout = 5 * time.Minute
+
+type subprocessTimeoutKey struct{}
+
+// WithSubprocessTimeout returns a copy of the ctx, which carries the time budget of the external tool calls.
+// A zero or negative timeout disables the per subprocess time budget.
+func WithSubprocessTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, subprocessTimeoutKey{}, timeout)
+}
+
+// SubprocessTimeout returns the time budget of the external tool calls, carried by the ctx.
+func SubprocessTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(subprocessTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return DefaultSubprocessTimeout
+}
+
+// NewCommandContext creates a command, which gets killed, if the ctx is done or the subprocess time budget is exceeded.
+// The returned cancel function has to be called, once the command finished,
+// it reports the command run to the event emitter of the ctx (see: events.WithEmitter).
+func NewCommandContext(ctx context.Context, name string, args ...string) (*command.Model, context.Context, context.CancelFunc) {
+	cmdCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout := SubprocessTimeout(ctx); timeout > 0 {
+		cmdCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	cmd := command.NewWithCmd(exec.CommandContext(cmdCtx, name, args...))
+
+	started := time.Now()
+	var once sync.Once
+	return cmd, cmdCtx, func() {
+		cancel()
+		once.Do(func() {
+			events.Emit(ctx, events.Event{
+				Type:       events.CommandRun,
+				Command:    cmd.PrintableCommandArgs(),
+				DurationMs: events.Milliseconds(time.Since(started)),
+			})
+		})
+	}
+}
+
+// CommandContextError returns a descriptive error, if the command was killed because of its context.
+func CommandContextError(cmdCtx context.Context, cmd *command.Model, err error) error {
+	switch cmdCtx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("command (%s) timed out", cmd.PrintableCommandArgs())
+	case context.Canceled:
+		return fmt.Errorf("command (%s) canceled", cmd.PrintableCommandArgs())
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package utility
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitrise-core/bitrise-init/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCommandContext(t *testing.T) {
+	t.Log("default subprocess timeout")
+	{
+		require.Equal(t, DefaultSubprocessTimeout, SubprocessTimeout(context.Background()))
+	}
+
+	t.Log("subprocess timeout")
+	{
+		ctx := WithSubprocessTimeout(context.Background(), 50*time.Millisecond)
+		require.Equal(t, 50*time.Millisecond, SubprocessTimeout(ctx))
+
+		cmd, cmdCtx, cancel := NewCommandContext(ctx, "sleep", "5")
+		defer cancel()
+
+		err := cmd.Run()
+		require.Error(t, err)
+		require.EqualError(t, CommandContextError(cmdCtx, cmd, err), `command (sleep "5") timed out`)
+	}
+
+	t.Log("canceled context")
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		cmd, cmdCtx, cancelCmd := NewCommandContext(WithSubprocessTimeout(ctx, 0), "sleep", "5")
+		defer cancelCmd()
+
+		err := cmd.Run()
+		require.Error(t, err)
+		require.EqualError(t, CommandContextError(cmdCtx, cmd, err), `command (sleep "5") canceled`)
+	}
+
+	t.Log("finished command")
+	{
+		cmd, cmdCtx, cancel := NewCommandContext(context.Background(), "true")
+		defer cancel()
+
+		require.NoError(t, cmd.Run())
+		require.NoError(t, CommandContextError(cmdCtx, cmd, nil))
+	}
+
+	t.Log("command run event")
+	{
+		recorder := events.NewRecorder()
+		ctx := events.WithScanner(events.WithEmitter(context.Background(), recorder), "ios")
+
+		cmd, _, cancel := NewCommandContext(ctx, "true", "arg")
+		require.NoError(t, cmd.Run())
+		cancel()
+		cancel()
+
+		recorded := recorder.Events()
+		require.Equal(t, 1, len(recorded))
+		require.Equal(t, events.CommandRun, recorded[0].Type)
+		require.Equal(t, "ios", recorded[0].Scanner)
+		require.Equal(t, `true "arg"`, recorded[0].Command)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 
-	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
 )
 
 const configXMLBasePath = "config.xml"
@@ -23,8 +23,8 @@ func parseConfigXMLContent(content string) (WidgetModel, error) {
 }
 
 // ParseConfigXML ...
-func ParseConfigXML(pth string) (WidgetModel, error) {
-	content, err := fileutil.ReadStringFromFile(pth)
+func ParseConfigXML(fs filesystem.FileSystem, pth string) (WidgetModel, error) {
+	content, err := filesystem.ReadStringFromFile(fs, pth)
 	if err != nil {
 		return WidgetModel{}, err
 	}
@@ -50,6 +50,16 @@ func FilterRootConfigXMLFile(fileList []string) (string, error) {
 type PackagesModel struct {
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
+	Scripts         map[string]string `json:"scripts"`
+}
+
+// HasDependency reports whether the package depends on the given package, either as a dependency or as a dev dependency.
+func (packages PackagesModel) HasDependency(name string) bool {
+	if _, ok := packages.Dependencies[name]; ok {
+		return true
+	}
+	_, ok := packages.DevDependencies[name]
+	return ok
 }
 
 func parsePackagesJSONContent(content string) (PackagesModel, error) {
@@ -61,8 +71,8 @@ func parsePackagesJSONContent(content string) (PackagesModel, error) {
 }
 
 // ParsePackagesJSON ...
-func ParsePackagesJSON(packagesJSONPth string) (PackagesModel, error) {
-	content, err := fileutil.ReadStringFromFile(packagesJSONPth)
+func ParsePackagesJSON(fs filesystem.FileSystem, packagesJSONPth string) (PackagesModel, error) {
+	content, err := filesystem.ReadStringFromFile(fs, packagesJSONPth)
 	if err != nil {
 		return PackagesModel{}, err
 	}
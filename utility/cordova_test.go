@@ -12,6 +12,19 @@ func TestParseConfigXMLContent(t *testing.T) {
 	require.Equal(t, "http://cordova.apache.org/ns/1.0", widget.XMLNSCDV)
 }
 
+func TestParsePackagesJSONContent(t *testing.T) {
+	packages, err := parsePackagesJSONContent(`{
+  "scripts": {"test": "jest"},
+  "dependencies": {"react": "16.0.0"},
+  "devDependencies": {"react-native": "0.50.0"}
+}`)
+	require.NoError(t, err)
+	require.Equal(t, "jest", packages.Scripts["test"])
+	require.True(t, packages.HasDependency("react"))
+	require.True(t, packages.HasDependency("react-native"))
+	require.False(t, packages.HasDependency("cordova"))
+}
+
 const testConfigXMLContent = `<?xml version='1.0' encoding='utf-8'?>
 <widget id="com.bitrise.cordovasample" version="0.9.0" xmlns="http://www.w3.org/ns/widgets" xmlns:cdv="http://cordova.apache.org/ns/1.0">
     <name>CordovaOnBitrise</name>
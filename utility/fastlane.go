@@ -6,7 +6,7 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
 )
 
 const (
@@ -83,8 +83,8 @@ func inspectFastfileContent(content string) ([]string, error) {
 }
 
 // InspectFastfile ...
-func InspectFastfile(fastFile string) ([]string, error) {
-	content, err := fileutil.ReadStringFromFile(fastFile)
+func InspectFastfile(fs filesystem.FileSystem, fastFile string) ([]string, error) {
+	content, err := filesystem.ReadStringFromFile(fs, fastFile)
 	if err != nil {
 		return []string{}, err
 	}
@@ -0,0 +1,352 @@
+package utility
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/models"
+)
+
+// FileIndex is an in-memory index of a directory tree, built by a single walk.
+// Every scanner gets the same index, so the search dir is walked only once per scan.
+// The indexed paths are relative to the root directory and sorted by components,
+// in the same order as ListPathInDirSortedByComponents returns them.
+// The lookup maps store positions in the path list, so every path is stored only once.
+type FileIndex struct {
+	fs    filesystem.FileSystem
+	root  string
+	paths []string
+
+	baseMap map[string][]int32
+	extMap  map[string][]int32
+	dirMap  map[string][]int32
+}
+
+// NewFileIndex walks the rootDir and indexes every path in it, see: NewFileIndexFS.
+func NewFileIndex(rootDir string, ignoreFileNames ...string) (*FileIndex, error) {
+	fs, err := filesystem.NewOSFileSystem(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewFileIndexFS(fs, ignoreFileNames...)
+}
+
+// NewFileIndexFS walks the file system and indexes every path in it.
+// If ignoreFileNames are given, the paths ignored by these files (with .gitignore semantics) are left out,
+// along with the .git directory, the .git/info/exclude patterns are applied as well.
+// The ignore files are read in every directory, patterns of the latter file names take precedence.
+func NewFileIndexFS(fs filesystem.FileSystem, ignoreFileNames ...string) (*FileIndex, error) {
+	var ignoreRules *IgnoreRules
+	if len(ignoreFileNames) > 0 {
+		ignoreRules = NewIgnoreRules()
+		if err := ignoreRules.AddIgnoreFile(fs, ".", gitInfoExcludePth); err != nil {
+			return nil, err
+		}
+	}
+
+	type entry struct {
+		pth   string
+		isDir bool
+		depth int
+	}
+
+	entries := []entry{}
+	if err := filesystem.Walk(fs, ".", func(relPth string, info os.FileInfo, err error) error {
+		isDir := info != nil && info.IsDir()
+
+		if ignoreRules != nil {
+			if isDir && filepath.Base(relPth) == gitDirName {
+				return filepath.SkipDir
+			}
+
+			if ignoreRules.IsIgnored(relPth, isDir) {
+				if isDir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if isDir {
+				for _, ignoreFileName := range ignoreFileNames {
+					if err := ignoreRules.AddIgnoreFile(fs, relPth, ignoreFileName); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		depth := 0
+		if relPth != "." {
+			depth = strings.Count(relPth, string(filepath.Separator)) + 1
+		}
+
+		entries = append(entries, entry{
+			pth:   relPth,
+			isDir: isDir,
+			depth: depth,
+		})
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].depth != entries[j].depth {
+			return entries[i].depth < entries[j].depth
+		}
+		return filepath.Base(entries[i].pth) < filepath.Base(entries[j].pth)
+	})
+
+	rootDir, _ := filesystem.RootDir(fs)
+
+	index := &FileIndex{
+		fs:      fs,
+		root:    rootDir,
+		paths:   make([]string, len(entries)),
+		baseMap: map[string][]int32{},
+		extMap:  map[string][]int32{},
+		dirMap:  map[string][]int32{},
+	}
+
+	for i, e := range entries {
+		index.paths[i] = e.pth
+		position := int32(i)
+
+		if e.isDir {
+			if _, ok := index.dirMap[e.pth]; !ok {
+				index.dirMap[e.pth] = nil
+			}
+		}
+
+		if e.pth == "." {
+			continue
+		}
+
+		base := filepath.Base(e.pth)
+		baseKey := strings.ToLower(base)
+		index.baseMap[baseKey] = append(index.baseMap[baseKey], position)
+
+		if ext := strings.ToLower(filepath.Ext(base)); ext != "" {
+			index.extMap[ext] = append(index.extMap[ext], position)
+		}
+
+		dir := filepath.Dir(e.pth)
+		index.dirMap[dir] = append(index.dirMap[dir], position)
+	}
+
+	return index, nil
+}
+
+// Without returns a copy of the index, which leaves out the given paths and every path under them,
+// like the directories claimed by another scanner (see: scanners.PathClaimer). The index itself is not modified.
+func (index *FileIndex) Without(excludedPths ...string) *FileIndex {
+	excluded := func(pth string) bool {
+		for _, excludedPth := range excludedPths {
+			excludedPth = filepath.Clean(excludedPth)
+			if excludedPth == "." || pth == excludedPth || strings.HasPrefix(pth, excludedPth+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	filtered := &FileIndex{
+		fs:      index.fs,
+		root:    index.root,
+		baseMap: map[string][]int32{},
+		extMap:  map[string][]int32{},
+		dirMap:  map[string][]int32{},
+	}
+
+	for _, pth := range index.paths {
+		if pth != "." && excluded(pth) {
+			continue
+		}
+
+		filtered.paths = append(filtered.paths, pth)
+		position := int32(len(filtered.paths) - 1)
+
+		if index.IsDir(pth) {
+			if _, ok := filtered.dirMap[pth]; !ok {
+				filtered.dirMap[pth] = nil
+			}
+		}
+
+		if pth == "." {
+			continue
+		}
+
+		base := filepath.Base(pth)
+		baseKey := strings.ToLower(base)
+		filtered.baseMap[baseKey] = append(filtered.baseMap[baseKey], position)
+
+		if ext := strings.ToLower(filepath.Ext(base)); ext != "" {
+			filtered.extMap[ext] = append(filtered.extMap[ext], position)
+		}
+
+		dir := filepath.Dir(pth)
+		filtered.dirMap[dir] = append(filtered.dirMap[dir], position)
+	}
+
+	return filtered
+}
+
+func (index *FileIndex) pathsAt(positions []int32) []string {
+	paths := make([]string, len(positions))
+	for i, position := range positions {
+		paths[i] = index.paths[position]
+	}
+	return paths
+}
+
+// FS returns the indexed file system, the scanners should read the indexed files through it.
+func (index *FileIndex) FS() filesystem.FileSystem {
+	return index.fs
+}
+
+// Root returns the absolute path of the indexed directory,
+// it is empty if the indexed file system is not backed by a directory on the disk (see: filesystem.Rooted).
+func (index *FileIndex) Root() string {
+	return index.root
+}
+
+// AbsPath returns the absolute path of the given path, relative to the root directory.
+// It is intended for the tools, which need to access the disk directly (like the plugin scanners),
+// the project files should be read through the FS.
+func (index *FileIndex) AbsPath(pth string) string {
+	if filepath.IsAbs(pth) {
+		return pth
+	}
+	return filepath.Join(index.root, pth)
+}
+
+// AbsPaths returns the absolute paths of the given paths, relative to the root directory.
+func (index *FileIndex) AbsPaths(paths []string) []string {
+	absPaths := make([]string, len(paths))
+	for i, pth := range paths {
+		absPaths[i] = index.AbsPath(pth)
+	}
+	return absPaths
+}
+
+// RelPath returns the given absolute path, relative to the root directory.
+// Paths outside of the root directory are returned unchanged.
+func (index *FileIndex) RelPath(pth string) string {
+	if !filepath.IsAbs(pth) {
+		return pth
+	}
+	relPth, err := filepath.Rel(index.root, pth)
+	if err != nil || relPth == ".." || strings.HasPrefix(relPth, ".."+string(filepath.Separator)) {
+		return pth
+	}
+	return relPth
+}
+
+// Len returns the number of indexed paths.
+func (index *FileIndex) Len() int {
+	return len(index.paths)
+}
+
+// Paths returns every indexed path, sorted by components.
+// The returned list is shared, it should not be modified.
+func (index *FileIndex) Paths() []string {
+	return index.paths
+}
+
+// ByBase returns the paths with the given (case insensitive) base name.
+func (index *FileIndex) ByBase(base string) []string {
+	return index.pathsAt(index.baseMap[strings.ToLower(base)])
+}
+
+// ByExtension returns the paths with the given (case insensitive) extension, like: .xcodeproj.
+func (index *FileIndex) ByExtension(ext string) []string {
+	return index.pathsAt(index.extMap[strings.ToLower(ext)])
+}
+
+// InDirectory returns the direct children of the given directory.
+func (index *FileIndex) InDirectory(dir string) []string {
+	return index.pathsAt(index.dirMap[filepath.Clean(dir)])
+}
+
+// IsDir reports whether the given path is an indexed directory.
+func (index *FileIndex) IsDir(pth string) bool {
+	_, ok := index.dirMap[filepath.Clean(pth)]
+	return ok
+}
+
+// Directories returns every indexed directory, in no particular order.
+func (index *FileIndex) Directories() []string {
+	dirs := make([]string, 0, len(index.dirMap))
+	for dir := range index.dirMap {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// NamedFilter is a FilterFunc with a name and the description of the paths it rejects,
+// so the pipelines can explain why a path was rejected.
+type NamedFilter struct {
+	Name string
+	// Rejects describes the rejected paths, like: embedded workspace of an Xcode project
+	Rejects string
+	Filter  FilterFunc
+}
+
+// NewNamedFilter ...
+func NewNamedFilter(name, rejects string, filter FilterFunc) NamedFilter {
+	return NamedFilter{
+		Name:    name,
+		Rejects: rejects,
+		Filter:  filter,
+	}
+}
+
+// FilterPipeline is a reusable, ordered list of filters.
+type FilterPipeline []NamedFilter
+
+// NewFilterPipeline ...
+func NewFilterPipeline(filters ...NamedFilter) FilterPipeline {
+	return FilterPipeline(filters)
+}
+
+// Append returns a new pipeline, extended with the given filters.
+func (pipeline FilterPipeline) Append(filters ...NamedFilter) FilterPipeline {
+	extended := make(FilterPipeline, 0, len(pipeline)+len(filters))
+	extended = append(extended, pipeline...)
+	return append(extended, filters...)
+}
+
+// Filter returns the paths allowed by every filter of the pipeline.
+func (pipeline FilterPipeline) Filter(fileList []string) ([]string, error) {
+	filtered, _, err := pipeline.Explain(fileList)
+	return filtered, err
+}
+
+// Explain returns the paths allowed by every filter of the pipeline,
+// and the rejected paths along with the first filter, which rejected them.
+func (pipeline FilterPipeline) Explain(fileList []string) ([]string, []models.Rejection, error) {
+	filtered := []string{}
+	rejections := []models.Rejection{}
+
+	for _, pth := range fileList {
+		allowed := true
+		for _, filter := range pipeline {
+			if allows, err := filter.Filter(pth); err != nil {
+				return []string{}, []models.Rejection{}, err
+			} else if !allows {
+				rejections = append(rejections, models.Rejection{Path: pth, Filter: filter.Name, Reason: filter.Rejects})
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			filtered = append(filtered, pth)
+		}
+	}
+
+	return filtered, rejections, nil
+}
@@ -0,0 +1,144 @@
+package utility
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/models"
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileIndex(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__file_index_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	pths := []string{
+		"build.gradle",
+		"app/build.gradle",
+		"ios/Sample.xcodeproj/project.pbxproj",
+		"ios/Podfile",
+		"ios/fastlane/Fastfile",
+	}
+	for _, pth := range pths {
+		pth = filepath.Join(tmpDir, pth)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0700))
+		require.NoError(t, fileutil.WriteStringToFile(pth, "test"))
+	}
+
+	index, err := NewFileIndex(tmpDir)
+	require.NoError(t, err)
+
+	t.Log("paths are sorted by components")
+	{
+		fileList, err := ListPathInDirSortedByComponents(tmpDir, true)
+		require.NoError(t, err)
+		require.Equal(t, fileList, index.Paths())
+		require.Equal(t, len(fileList), index.Len())
+	}
+
+	t.Log("lookup by base")
+	{
+		require.Equal(t, []string{"build.gradle", "app/build.gradle"}, index.ByBase("build.gradle"))
+		require.Equal(t, []string{"ios/fastlane/Fastfile"}, index.ByBase("fastfile"))
+		require.Equal(t, []string{}, index.ByBase("Cartfile"))
+	}
+
+	t.Log("lookup by extension")
+	{
+		require.Equal(t, []string{"ios/Sample.xcodeproj"}, index.ByExtension(".xcodeproj"))
+		require.Equal(t, []string{"build.gradle", "app/build.gradle"}, index.ByExtension(".GRADLE"))
+	}
+
+	t.Log("lookup by directory")
+	{
+		require.Equal(t, []string{"ios/Podfile", "ios/Sample.xcodeproj", "ios/fastlane"}, index.InDirectory("ios"))
+		require.Equal(t, []string{}, index.InDirectory("ios/fastlane/Fastfile"))
+		require.Equal(t, true, index.IsDir("ios/Sample.xcodeproj"))
+		require.Equal(t, true, index.IsDir("."))
+		require.Equal(t, false, index.IsDir("ios/Podfile"))
+	}
+
+	t.Log("without the claimed paths")
+	{
+		filtered := index.Without("ios/Sample.xcodeproj", "app")
+		require.Equal(t, []string{".", "build.gradle", "ios", "ios/Podfile", "ios/fastlane", "ios/fastlane/Fastfile"}, filtered.Paths())
+		require.Equal(t, []string{"build.gradle"}, filtered.ByBase("build.gradle"))
+		require.Equal(t, []string{}, filtered.ByExtension(".xcodeproj"))
+		require.Equal(t, []string{"ios/Podfile", "ios/fastlane"}, filtered.InDirectory("ios"))
+		require.Equal(t, false, filtered.IsDir("app"))
+		require.Equal(t, true, filtered.IsDir("ios/fastlane"))
+
+		// the original index is kept
+		require.Equal(t, []string{"ios/Sample.xcodeproj"}, index.ByExtension(".xcodeproj"))
+	}
+
+	t.Log("absolute and relative paths")
+	{
+		absPth := filepath.Join(index.Root(), "ios", "Podfile")
+		require.Equal(t, absPth, index.AbsPath("ios/Podfile"))
+		require.Equal(t, absPth, index.AbsPath(absPth))
+		require.Equal(t, []string{absPth}, index.AbsPaths([]string{"ios/Podfile"}))
+		require.Equal(t, "ios/Podfile", index.RelPath(absPth))
+		require.Equal(t, "ios/Podfile", index.RelPath("ios/Podfile"))
+		require.Equal(t, ".", index.RelPath(index.Root()))
+		require.Equal(t, "/outside/Podfile", index.RelPath("/outside/Podfile"))
+	}
+
+	t.Log("in-memory file system")
+	{
+		files := map[string]string{}
+		for _, pth := range pths {
+			files[pth] = "test"
+		}
+		files[".gitignore"] = "fastlane/"
+
+		fs, err := filesystem.NewMemoryFileSystem(files)
+		require.NoError(t, err)
+
+		memoryIndex, err := NewFileIndexFS(fs)
+		require.NoError(t, err)
+		require.Equal(t, append([]string{".", ".gitignore"}, index.Paths()[1:]...), memoryIndex.Paths())
+		require.Equal(t, "", memoryIndex.Root())
+
+		memoryIndex, err = NewFileIndexFS(fs, DefaultIgnoreFileNames...)
+		require.NoError(t, err)
+		require.Equal(t, []string{}, memoryIndex.ByBase("Fastfile"))
+		require.Equal(t, []string{"ios/Podfile"}, memoryIndex.ByBase("Podfile"))
+	}
+}
+
+func TestFilterPipeline(t *testing.T) {
+	pipeline := NewFilterPipeline(NewNamedFilter("AllowXcodeProjExtFilter", "not an Xcode project", ExtensionFilter(".xcodeproj", true)))
+	extended := pipeline.Append(NewNamedFilter("ForbidPodsDirComponentFilter", "inside the Pods directory", ForbidPodsDirComponentFilter))
+
+	paths := []string{
+		"Sample.xcodeproj",
+		"Pods/Pods.xcodeproj",
+		"Podfile",
+	}
+
+	filtered, err := pipeline.Filter(paths)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Sample.xcodeproj", "Pods/Pods.xcodeproj"}, filtered)
+
+	filtered, err = extended.Filter(paths)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Sample.xcodeproj"}, filtered)
+
+	filtered, rejections, err := extended.Explain(paths)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Sample.xcodeproj"}, filtered)
+	require.Equal(t, []models.Rejection{
+		{Path: "Pods/Pods.xcodeproj", Filter: "ForbidPodsDirComponentFilter", Reason: "inside the Pods directory"},
+		{Path: "Podfile", Filter: "AllowXcodeProjExtFilter", Reason: "not an Xcode project"},
+	}, rejections)
+
+	require.Equal(t, 1, len(pipeline))
+}
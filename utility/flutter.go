@@ -0,0 +1,57 @@
+package utility
+
+import (
+	"regexp"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+)
+
+// flutterVersionPattern matches the exact Flutter SDK versions, like: 1.12.13+hotfix.8
+var flutterVersionPattern = regexp.MustCompile(`^v?\d+\.\d+\.\d+(\+[0-9A-Za-z.-]+)?$`)
+
+// PubspecEnvironmentModel is the environment section of the pubspec.yaml file, it holds the SDK constraints.
+type PubspecEnvironmentModel struct {
+	SDK     string `yaml:"sdk"`
+	Flutter string `yaml:"flutter"`
+}
+
+// PubspecModel is the model of the pubspec.yaml file of a Dart (or Flutter) package.
+type PubspecModel struct {
+	Name            string                  `yaml:"name"`
+	Environment     PubspecEnvironmentModel `yaml:"environment"`
+	Dependencies    map[string]interface{}  `yaml:"dependencies"`
+	DevDependencies map[string]interface{}  `yaml:"dev_dependencies"`
+}
+
+// HasFlutterDependency reports whether the package depends on the Flutter SDK.
+func (pubspec PubspecModel) HasFlutterDependency() bool {
+	_, ok := pubspec.Dependencies["flutter"]
+	return ok
+}
+
+// FlutterVersion returns the Flutter SDK version, if the package's Flutter SDK constraint pins an exact version.
+func (pubspec PubspecModel) FlutterVersion() string {
+	if flutterVersionPattern.MatchString(pubspec.Environment.Flutter) {
+		return pubspec.Environment.Flutter
+	}
+	return ""
+}
+
+func parsePubspecContent(content string) (PubspecModel, error) {
+	var pubspec PubspecModel
+	if err := yaml.Unmarshal([]byte(content), &pubspec); err != nil {
+		return PubspecModel{}, err
+	}
+	return pubspec, nil
+}
+
+// ParsePubspec ...
+func ParsePubspec(fs filesystem.FileSystem, pth string) (PubspecModel, error) {
+	content, err := filesystem.ReadStringFromFile(fs, pth)
+	if err != nil {
+		return PubspecModel{}, err
+	}
+	return parsePubspecContent(content)
+}
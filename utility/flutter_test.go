@@ -0,0 +1,68 @@
+package utility
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePubspecContent(t *testing.T) {
+	t.Log("flutter app")
+	{
+		pubspec, err := parsePubspecContent(`name: sample
+description: A new Flutter project.
+version: 1.0.0+1
+
+environment:
+  sdk: ">=2.1.0 <3.0.0"
+  flutter: ">=1.10.0"
+
+dependencies:
+  flutter:
+    sdk: flutter
+  cupertino_icons: ^0.1.2
+
+dev_dependencies:
+  flutter_test:
+    sdk: flutter
+
+flutter:
+  uses-material-design: true
+`)
+		require.NoError(t, err)
+		require.Equal(t, "sample", pubspec.Name)
+		require.Equal(t, ">=2.1.0 <3.0.0", pubspec.Environment.SDK)
+		require.Equal(t, ">=1.10.0", pubspec.Environment.Flutter)
+		require.True(t, pubspec.HasFlutterDependency())
+		require.Equal(t, "", pubspec.FlutterVersion())
+	}
+
+	t.Log("pinned flutter version")
+	{
+		pubspec, err := parsePubspecContent(`name: sample
+environment:
+  flutter: 1.12.13+hotfix.8
+dependencies:
+  flutter:
+    sdk: flutter
+`)
+		require.NoError(t, err)
+		require.Equal(t, "1.12.13+hotfix.8", pubspec.FlutterVersion())
+	}
+
+	t.Log("dart package")
+	{
+		pubspec, err := parsePubspecContent(`name: sample
+dependencies:
+  path: ^1.6.0
+`)
+		require.NoError(t, err)
+		require.False(t, pubspec.HasFlutterDependency())
+	}
+
+	t.Log("invalid content")
+	{
+		_, err := parsePubspecContent(`name: [`)
+		require.Error(t, err)
+	}
+}
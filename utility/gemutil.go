@@ -5,7 +5,7 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
 )
 
 // GemVersionFromGemfileLockContent ...
@@ -41,8 +41,8 @@ func GemVersionFromGemfileLockContent(gem, content string) string {
 }
 
 // GemVersionFromGemfileLock ...
-func GemVersionFromGemfileLock(gem, gemfileLockPth string) (string, error) {
-	content, err := fileutil.ReadStringFromFile(gemfileLockPth)
+func GemVersionFromGemfileLock(fs filesystem.FileSystem, gem, gemfileLockPth string) (string, error) {
+	content, err := filesystem.ReadStringFromFile(fs, gemfileLockPth)
 	if err != nil {
 		return "", err
 	}
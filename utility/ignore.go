@@ -0,0 +1,223 @@
+package utility
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+)
+
+const (
+	// GitignoreFileName ...
+	GitignoreFileName = ".gitignore"
+	// BitriseignoreFileName is the name of the ignore file, which lists the paths to skip during the project scan.
+	// It has the same syntax as the .gitignore file.
+	BitriseignoreFileName = ".bitriseignore"
+)
+
+// DefaultIgnoreFileNames lists the ignore files applied during the project scan,
+// patterns of the latter files take precedence.
+var DefaultIgnoreFileNames = []string{GitignoreFileName, BitriseignoreFileName}
+
+// gitInfoExcludePth is the repository specific ignore file, it has lower precedence than the .gitignore files.
+var gitInfoExcludePth = filepath.Join(gitDirName, "info", "exclude")
+
+type ignorePattern struct {
+	regexp  *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// IgnoreRules collects the ignore patterns of a directory tree, by the directory they were defined in.
+type IgnoreRules struct {
+	dirPatternsMap map[string][]ignorePattern
+}
+
+// NewIgnoreRules ...
+func NewIgnoreRules() *IgnoreRules {
+	return &IgnoreRules{
+		dirPatternsMap: map[string][]ignorePattern{},
+	}
+}
+
+// AddPatterns parses the content of an ignore file, defined in the given (root relative) directory.
+// The patterns added later take precedence over the earlier ones.
+func (rules *IgnoreRules) AddPatterns(dir, content string) {
+	dir = filepath.Clean(dir)
+	rules.dirPatternsMap[dir] = append(rules.dirPatternsMap[dir], parseIgnorePatterns(content)...)
+}
+
+// AddIgnoreFile reads and adds the patterns of the ignore file, located in the (root relative) directory, if it exists.
+func (rules *IgnoreRules) AddIgnoreFile(fs filesystem.FileSystem, dir, fileName string) error {
+	pth := filepath.Join(dir, fileName)
+	if exist, err := filesystem.IsPathExists(fs, pth); err != nil {
+		return err
+	} else if !exist {
+		return nil
+	}
+
+	content, err := filesystem.ReadStringFromFile(fs, pth)
+	if err != nil {
+		return err
+	}
+
+	rules.AddPatterns(dir, content)
+	return nil
+}
+
+// IsIgnored reports whether the given (root relative) path is ignored by the patterns,
+// defined in the path's parent directories.
+// Paths in an ignored directory are not reported as ignored, the directory walk should skip the whole directory.
+func (rules *IgnoreRules) IsIgnored(pth string, isDir bool) bool {
+	pth = filepath.ToSlash(filepath.Clean(pth))
+	if pth == "." {
+		return false
+	}
+
+	components := strings.Split(pth, "/")
+
+	ignored := false
+	dir := "."
+	for i := range components {
+		if i > 0 {
+			dir = strings.Join(components[:i], "/")
+		}
+
+		patterns := rules.dirPatternsMap[filepath.FromSlash(dir)]
+		if len(patterns) == 0 {
+			continue
+		}
+
+		relPth := strings.Join(components[i:], "/")
+		for _, pattern := range patterns {
+			if pattern.dirOnly && !isDir {
+				continue
+			}
+			if pattern.regexp.MatchString(relPth) {
+				ignored = !pattern.negate
+			}
+		}
+	}
+
+	return ignored
+}
+
+func parseIgnorePatterns(content string) []ignorePattern {
+	patterns := []ignorePattern{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if pattern, ok := parseIgnorePattern(scanner.Text()); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns
+}
+
+// parseIgnorePattern converts a gitignore pattern line to regexp, based on: https://git-scm.com/docs/gitignore
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+
+	// trailing spaces are ignored unless they are quoted with backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = strings.TrimSuffix(line, " ")
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	pattern := ignorePattern{}
+
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	// a pattern with a slash in the beginning or in the middle is relative to the ignore file's directory,
+	// otherwise it matches at any level below the ignore file's directory
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expression := globToRegexp(line)
+	if !anchored && !strings.HasPrefix(line, "**") {
+		expression = "(.*/)?" + expression
+	}
+
+	re, err := regexp.Compile("^" + expression + "$")
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	pattern.regexp = re
+
+	return pattern, true
+}
+
+func globToRegexp(glob string) string {
+	var expression bytes.Buffer
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				atStart := i == 0 || glob[i-1] == '/'
+				atEnd := i+2 == len(glob)
+				followedBySlash := i+2 < len(glob) && glob[i+2] == '/'
+
+				switch {
+				case atStart && followedBySlash:
+					// "**/" matches zero or more directories
+					expression.WriteString("(.*/)?")
+					i += 2
+					continue
+				case atStart && atEnd:
+					// "/**" matches everything inside
+					expression.WriteString(".*")
+					i++
+					continue
+				}
+			}
+			expression.WriteString("[^/]*")
+		case '?':
+			expression.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expression.WriteString(`\[`)
+				continue
+			}
+
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expression.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				expression.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return expression.String()
+}
@@ -0,0 +1,154 @@
+package utility
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	t.Log("comments and blank lines")
+	{
+		rules := NewIgnoreRules()
+		rules.AddPatterns(".", "# build.gradle\n\n   \n")
+		require.Equal(t, false, rules.IsIgnored("build.gradle", false))
+	}
+
+	t.Log("pattern without slash matches at any level")
+	{
+		rules := NewIgnoreRules()
+		rules.AddPatterns(".", "node_modules\n*.sln\n")
+		require.Equal(t, true, rules.IsIgnored("node_modules", true))
+		require.Equal(t, true, rules.IsIgnored("app/node_modules", true))
+		require.Equal(t, true, rules.IsIgnored("Sample.sln", false))
+		require.Equal(t, true, rules.IsIgnored("src/Sample.sln", false))
+		require.Equal(t, false, rules.IsIgnored("Sample.sln.bak", false))
+	}
+
+	t.Log("anchored pattern")
+	{
+		rules := NewIgnoreRules()
+		rules.AddPatterns(".", "/build\nexamples/android\n")
+		require.Equal(t, true, rules.IsIgnored("build", true))
+		require.Equal(t, false, rules.IsIgnored("app/build", true))
+		require.Equal(t, true, rules.IsIgnored("examples/android", true))
+		require.Equal(t, false, rules.IsIgnored("sdk/examples/android", true))
+	}
+
+	t.Log("directory only pattern")
+	{
+		rules := NewIgnoreRules()
+		rules.AddPatterns(".", "build/\n")
+		require.Equal(t, true, rules.IsIgnored("app/build", true))
+		require.Equal(t, false, rules.IsIgnored("app/build", false))
+	}
+
+	t.Log("double asterisk")
+	{
+		rules := NewIgnoreRules()
+		rules.AddPatterns(".", "**/DerivedData\nsdk/**\na/**/b\n")
+		require.Equal(t, true, rules.IsIgnored("DerivedData", true))
+		require.Equal(t, true, rules.IsIgnored("ios/DerivedData", true))
+		require.Equal(t, true, rules.IsIgnored("sdk/android/build.gradle", false))
+		require.Equal(t, false, rules.IsIgnored("sdk", true))
+		require.Equal(t, true, rules.IsIgnored("a/b", true))
+		require.Equal(t, true, rules.IsIgnored("a/x/y/b", true))
+	}
+
+	t.Log("wildcards and character classes")
+	{
+		rules := NewIgnoreRules()
+		rules.AddPatterns(".", "sample?\n[Tt]ests/*.xml\n")
+		require.Equal(t, true, rules.IsIgnored("sample1", true))
+		require.Equal(t, false, rules.IsIgnored("sample12", true))
+		require.Equal(t, true, rules.IsIgnored("Tests/config.xml", false))
+		require.Equal(t, true, rules.IsIgnored("tests/config.xml", false))
+		require.Equal(t, false, rules.IsIgnored("tests/www/config.xml", false))
+	}
+
+	t.Log("negation")
+	{
+		rules := NewIgnoreRules()
+		rules.AddPatterns(".", "*.gradle\n!app/build.gradle\n\\!important\n")
+		require.Equal(t, true, rules.IsIgnored("build.gradle", false))
+		require.Equal(t, false, rules.IsIgnored("app/build.gradle", false))
+		require.Equal(t, true, rules.IsIgnored("!important", false))
+	}
+
+	t.Log("nested ignore file overrides the parent's patterns")
+	{
+		rules := NewIgnoreRules()
+		rules.AddPatterns(".", "*.sln\n")
+		rules.AddPatterns("src", "!Main.sln\n/Other.sln\n")
+		require.Equal(t, true, rules.IsIgnored("Main.sln", false))
+		require.Equal(t, false, rules.IsIgnored("src/Main.sln", false))
+		require.Equal(t, false, rules.IsIgnored("src/lib/Main.sln", false))
+		require.Equal(t, true, rules.IsIgnored("src/Other.sln", false))
+	}
+}
+
+func TestFileIndexIgnoreFiles(t *testing.T) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("__file_index_ignore_test__")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}()
+
+	fileContentMap := map[string]string{
+		".gitignore":                      "node_modules/\nbuild/\n",
+		".bitriseignore":                  "examples/*\n!examples/keep/\n",
+		".git/info/exclude":               "local.properties\n",
+		".git/config":                     "",
+		"build.gradle":                    "",
+		"local.properties":                "",
+		"build/build.gradle":              "",
+		"node_modules/lib/build.gradle":   "",
+		"examples/sample/build.gradle":    "",
+		"app/build.gradle":                "",
+		"app/.gitignore":                  "*.gradle\n!build.gradle\n",
+		"app/sub/build.gradle":            "",
+		"app/sub/settings.gradle":         "",
+		"examples/keep/build.gradle":      "",
+		"examples/keep/.bitriseignore":    "build.gradle\n",
+		"examples/keep/config.xml":        "",
+		"sdk/android/build.gradle":        "",
+		"sdk/android/.bitriseignore":      "/build.gradle\n",
+		"sdk/android/nested/build.gradle": "",
+	}
+	for pth, content := range fileContentMap {
+		pth = filepath.Join(tmpDir, pth)
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0700))
+		require.NoError(t, fileutil.WriteStringToFile(pth, content))
+	}
+
+	t.Log("without ignore files every path is indexed")
+	{
+		index, err := NewFileIndex(tmpDir)
+		require.NoError(t, err)
+		require.Equal(t, 9, len(index.ByBase("build.gradle")))
+	}
+
+	t.Log("ignore files")
+	{
+		index, err := NewFileIndex(tmpDir, DefaultIgnoreFileNames...)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{
+			"build.gradle",
+			"app/build.gradle",
+			"app/sub/build.gradle",
+			"sdk/android/nested/build.gradle",
+		}, index.ByBase("build.gradle"))
+		require.Equal(t, []string{}, index.ByBase("settings.gradle"))
+		require.Equal(t, []string{}, index.ByBase("local.properties"))
+		require.Equal(t, []string{"examples/keep/config.xml"}, index.ByBase("config.xml"))
+		require.Equal(t, false, index.IsDir(".git"))
+		require.Equal(t, false, index.IsDir("node_modules"))
+		require.Equal(t, true, index.IsDir("examples"))
+		require.Equal(t, false, index.IsDir("examples/sample"))
+	}
+}
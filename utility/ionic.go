@@ -0,0 +1,47 @@
+package utility
+
+import (
+	"encoding/json"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+)
+
+// IonicIntegration is a native runtime integration of an Ionic project.
+type IonicIntegration string
+
+// Ionic integrations
+const (
+	IonicCordovaIntegration   IonicIntegration = "cordova"
+	IonicCapacitorIntegration IonicIntegration = "capacitor"
+)
+
+// IonicConfigModel is the model of the ionic.config.json (or the legacy ionic.project) file.
+type IonicConfigModel struct {
+	Name string `json:"name"`
+	// Type is the project type, like: ionic1, ionic-angular, angular, react or vue.
+	Type         string                     `json:"type"`
+	Integrations map[string]json.RawMessage `json:"integrations"`
+}
+
+// HasIntegration reports whether the given integration is enabled in the project.
+func (config IonicConfigModel) HasIntegration(integration IonicIntegration) bool {
+	_, ok := config.Integrations[string(integration)]
+	return ok
+}
+
+func parseIonicConfigContent(content string) (IonicConfigModel, error) {
+	var config IonicConfigModel
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		return IonicConfigModel{}, err
+	}
+	return config, nil
+}
+
+// ParseIonicConfig ...
+func ParseIonicConfig(fs filesystem.FileSystem, pth string) (IonicConfigModel, error) {
+	content, err := filesystem.ReadStringFromFile(fs, pth)
+	if err != nil {
+		return IonicConfigModel{}, err
+	}
+	return parseIonicConfigContent(content)
+}
@@ -0,0 +1,38 @@
+package utility
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIonicConfigContent(t *testing.T) {
+	t.Log("capacitor project")
+	{
+		config, err := parseIonicConfigContent(`{
+  "name": "app",
+  "integrations": {
+    "capacitor": {}
+  },
+  "type": "angular"
+}`)
+		require.NoError(t, err)
+		require.Equal(t, "angular", config.Type)
+		require.True(t, config.HasIntegration(IonicCapacitorIntegration))
+		require.False(t, config.HasIntegration(IonicCordovaIntegration))
+	}
+
+	t.Log("legacy project")
+	{
+		config, err := parseIonicConfigContent(`{"name":"app","app_id":""}`)
+		require.NoError(t, err)
+		require.Equal(t, "", config.Type)
+		require.False(t, config.HasIntegration(IonicCapacitorIntegration))
+	}
+
+	t.Log("invalid content")
+	{
+		_, err := parseIonicConfigContent(`name: app`)
+		require.Error(t, err)
+	}
+}
@@ -1,14 +1,17 @@
 package utility
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"encoding/json"
 
-	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-io/go-utils/log"
 	"github.com/bitrise-io/go-utils/pathutil"
 	"github.com/bitrise-tools/go-xcode/xcodeproj"
 )
@@ -18,7 +21,7 @@ const podfileBase = "Podfile"
 // AllowPodfileBaseFilter ...
 var AllowPodfileBaseFilter = BaseFilter(podfileBase, true)
 
-func getTargetDefinitionProjectMap(podfilePth, cocoapodsVersion string) (map[string]string, error) {
+func getTargetDefinitionProjectMap(ctx context.Context, podfilePth, cocoapodsVersion string) (map[string]string, error) {
 	gemfileCocoapodsVersion := ""
 	if cocoapodsVersion != "" {
 		gemfileCocoapodsVersion = fmt.Sprintf(`, '%s'`, cocoapodsVersion)
@@ -36,7 +39,9 @@ require 'json'
 
 begin
 	podfile_path = ENV['PODFILE_PATH']
-	podfile = Pod::Podfile.from_file(podfile_path)
+	# fix the podfile quotation in memory, the Podfile is evaluated in place
+	podfile_content = File.read(podfile_path, :encoding => 'utf-8').tr("\u2018\u2019\u201C\u201D", %q(''""))
+	podfile = Pod::Podfile.from_ruby(podfile_path, podfile_content)
 	targets = podfile.target_definitions
 	
 	puts "#{{}.to_json}" unless targets
@@ -61,7 +66,7 @@ end
 	envs := []string{fmt.Sprintf("PODFILE_PATH=%s", absPodfilePth)}
 	podfileDir := filepath.Dir(absPodfilePth)
 
-	out, err := runRubyScriptForOutput(rubyScriptContent, gemfileContent, podfileDir, envs)
+	out, err := runRubyScriptForOutput(ctx, rubyScriptContent, gemfileContent, podfileDir, envs)
 	if err != nil {
 		return map[string]string{}, fmt.Errorf("ruby script failed, error: %s", err)
 	}
@@ -87,8 +92,8 @@ end
 	return targetDefinitionOutput.Data, nil
 }
 
-func getUserDefinedProjectRelavtivePath(podfilePth, cocoapodsVersion string) (string, error) {
-	targetProjectMap, err := getTargetDefinitionProjectMap(podfilePth, cocoapodsVersion)
+func getUserDefinedProjectRelavtivePath(ctx context.Context, podfilePth, cocoapodsVersion string) (string, error) {
+	targetProjectMap, err := getTargetDefinitionProjectMap(ctx, podfilePth, cocoapodsVersion)
 	if err != nil {
 		return "", fmt.Errorf("failed to get target definition map, error: %s", err)
 	}
@@ -101,7 +106,7 @@ func getUserDefinedProjectRelavtivePath(podfilePth, cocoapodsVersion string) (st
 	return "", nil
 }
 
-func getUserDefinedWorkspaceRelativePath(podfilePth, cocoapodsVersion string) (string, error) {
+func getUserDefinedWorkspaceRelativePath(ctx context.Context, podfilePth, cocoapodsVersion string) (string, error) {
 	gemfileCocoapodsVersion := ""
 	if cocoapodsVersion != "" {
 		gemfileCocoapodsVersion = fmt.Sprintf(`, '%s'`, cocoapodsVersion)
@@ -119,7 +124,9 @@ require 'json'
 
 begin
 	podfile_path = ENV['PODFILE_PATH']
-	podfile = Pod::Podfile.from_file(podfile_path)
+	# fix the podfile quotation in memory, the Podfile is evaluated in place
+	podfile_content = File.read(podfile_path, :encoding => 'utf-8').tr("\u2018\u2019\u201C\u201D", %q(''""))
+	podfile = Pod::Podfile.from_ruby(podfile_path, podfile_content)
 	pth = podfile.workspace_path
 	puts "#{{ :data => pth }.to_json}"
 rescue => e
@@ -134,7 +141,7 @@ end
 	envs := []string{fmt.Sprintf("PODFILE_PATH=%s", absPodfilePth)}
 	podfileDir := filepath.Dir(absPodfilePth)
 
-	out, err := runRubyScriptForOutput(rubyScriptContent, gemfileContent, podfileDir, envs)
+	out, err := runRubyScriptForOutput(ctx, rubyScriptContent, gemfileContent, podfileDir, envs)
 	if err != nil {
 		return "", fmt.Errorf("ruby script failed, error: %s", err)
 	}
@@ -165,17 +172,18 @@ end
 // If more then one project exists in the Podfile's directory, root 'xcodeproj/project' property have to be defined in the Podfile.
 // Root 'xcodeproj/project' property will be mapped to the default cocoapods target (Pods).
 // If workspace property defined in the Podfile, it will override the workspace name.
-func GetWorkspaceProjectMap(podfilePth string, projects []string) (map[string]string, error) {
+// The Podfile is evaluated in its own directory, the project files are never modified.
+func GetWorkspaceProjectMap(ctx context.Context, fs filesystem.FileSystem, podfilePth string, projects []string) (map[string]string, error) {
 	podfileDir := filepath.Dir(podfilePth)
 
 	cocoapodsVersion := ""
 
 	podfileLockPth := filepath.Join(podfileDir, "Podfile.lock")
-	if exist, err := pathutil.IsPathExists(podfileLockPth); err != nil {
+	if exist, err := filesystem.IsPathExists(fs, podfileLockPth); err != nil {
 		return map[string]string{}, fmt.Errorf("failed to check if Podfile.lock exist, error: %s", err)
 	} else if !exist {
 		podfileLockPth = filepath.Join(podfileDir, "podfile.lock")
-		if exist, err := pathutil.IsPathExists(podfileLockPth); err != nil {
+		if exist, err := filesystem.IsPathExists(fs, podfileLockPth); err != nil {
 			return map[string]string{}, fmt.Errorf("failed to check if podfile.lock exist, error: %s", err)
 		} else if !exist {
 			podfileLockPth = ""
@@ -183,30 +191,36 @@ func GetWorkspaceProjectMap(podfilePth string, projects []string) (map[string]st
 	}
 
 	if podfileLockPth != "" {
-		version, err := GemVersionFromGemfileLock("cocoapods", podfileLockPth)
+		version, err := GemVersionFromGemfileLock(fs, "cocoapods", podfileLockPth)
 		if err != nil {
 			return map[string]string{}, fmt.Errorf("failed to read cocoapods version from %s, error: %s", podfileLockPth, err)
 		}
 		cocoapodsVersion = version
 	}
 
-	// fix podfile quotation
-	podfileContent, err := fileutil.ReadStringFromFile(podfilePth)
-	if err != nil {
-		return map[string]string{}, fmt.Errorf("failed to read podfile (%s), error: %s", podfilePth, err)
-	}
-
-	podfileContent = strings.Replace(podfileContent, `‘`, `'`, -1)
-	podfileContent = strings.Replace(podfileContent, `’`, `'`, -1)
-	podfileContent = strings.Replace(podfileContent, `“`, `"`, -1)
-	podfileContent = strings.Replace(podfileContent, `”`, `"`, -1)
+	// The Podfile is evaluated in place, so its require_relative calls and relative file reads work.
+	// The archive and in-memory file systems are evaluated from a temporary copy of the Podfile's directory.
+	evaluatedPodfilePth := ""
+	if rootDir, ok := filesystem.RootDir(fs); ok {
+		evaluatedPodfilePth = filepath.Join(rootDir, podfilePth)
+	} else {
+		tmpDir, err := pathutil.NormalizedOSTempDirPath("__podfile__")
+		if err != nil {
+			return map[string]string{}, fmt.Errorf("failed to create tmp dir, error: %s", err)
+		}
+		defer func() {
+			if err := os.RemoveAll(tmpDir); err != nil {
+				log.Errorft("Failed to remove tmp dir (%s), error: %s", tmpDir, err)
+			}
+		}()
 
-	if err := fileutil.WriteStringToFile(podfilePth, podfileContent); err != nil {
-		return map[string]string{}, fmt.Errorf("failed to apply Podfile quotation fix, error: %s", err)
+		if err := filesystem.CopyDir(fs, podfileDir, tmpDir); err != nil {
+			return map[string]string{}, fmt.Errorf("failed to copy the Podfile's directory (%s), error: %s", podfileDir, err)
+		}
+		evaluatedPodfilePth = filepath.Join(tmpDir, filepath.Base(podfilePth))
 	}
-	// ----
 
-	projectRelPth, err := getUserDefinedProjectRelavtivePath(podfilePth, cocoapodsVersion)
+	projectRelPth, err := getUserDefinedProjectRelavtivePath(ctx, evaluatedPodfilePth, cocoapodsVersion)
 	if err != nil {
 		return map[string]string{}, fmt.Errorf("failed to get user defined project path, error: %s", err)
 	}
@@ -227,13 +241,13 @@ func GetWorkspaceProjectMap(podfilePth string, projects []string) (map[string]st
 	}
 	projectPth := filepath.Join(podfileDir, projectRelPth)
 
-	if exist, err := pathutil.IsPathExists(projectPth); err != nil {
+	if exist, err := filesystem.IsPathExists(fs, projectPth); err != nil {
 		return map[string]string{}, fmt.Errorf("failed to check if path (%s) exists, error: %s", projectPth, err)
 	} else if !exist {
 		return map[string]string{}, fmt.Errorf("project not found at: %s", projectPth)
 	}
 
-	workspaceRelPth, err := getUserDefinedWorkspaceRelativePath(podfilePth, cocoapodsVersion)
+	workspaceRelPth, err := getUserDefinedWorkspaceRelativePath(ctx, evaluatedPodfilePth, cocoapodsVersion)
 	if err != nil {
 		return map[string]string{}, fmt.Errorf("failed to get user defined workspace path, error: %s", err)
 	}
@@ -1,12 +1,14 @@
 package utility
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/bitrise-core/bitrise-init/filesystem"
 	"github.com/bitrise-io/go-utils/fileutil"
 	"github.com/bitrise-io/go-utils/pathutil"
 	"github.com/bitrise-tools/go-xcode/xcodeproj"
@@ -82,7 +84,7 @@ pod 'Alamofire', '~> 3.4'
 		expectedTargetDefinition := map[string]string{
 			"Pods": "MyXcodeProject.xcodeproj",
 		}
-		actualTargetDefinition, err := getTargetDefinitionProjectMap(podfilePth, "")
+		actualTargetDefinition, err := getTargetDefinitionProjectMap(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedTargetDefinition, actualTargetDefinition)
 	}
@@ -99,7 +101,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedTargetDefinition := map[string]string{}
-		actualTargetDefinition, err := getTargetDefinitionProjectMap(podfilePth, "")
+		actualTargetDefinition, err := getTargetDefinitionProjectMap(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedTargetDefinition, actualTargetDefinition)
 	}
@@ -130,7 +132,7 @@ end
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedTargetDefinition := map[string]string{}
-		actualTargetDefinition, err := getTargetDefinitionProjectMap(podfilePth, "0.38.0")
+		actualTargetDefinition, err := getTargetDefinitionProjectMap(context.Background(), podfilePth, "0.38.0")
 		require.NoError(t, err)
 		require.Equal(t, expectedTargetDefinition, actualTargetDefinition)
 	}
@@ -156,7 +158,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedProject := "MyXcodeProject.xcodeproj"
-		actualProject, err := getUserDefinedProjectRelavtivePath(podfilePth, "")
+		actualProject, err := getUserDefinedProjectRelavtivePath(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedProject, actualProject)
 	}
@@ -173,7 +175,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedProject := ""
-		actualProject, err := getUserDefinedProjectRelavtivePath(podfilePth, "")
+		actualProject, err := getUserDefinedProjectRelavtivePath(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedProject, actualProject)
 	}
@@ -199,7 +201,7 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedWorkspace := "MyWorkspace.xcworkspace"
-		actualWorkspace, err := getUserDefinedWorkspaceRelativePath(podfilePth, "")
+		actualWorkspace, err := getUserDefinedWorkspaceRelativePath(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedWorkspace, actualWorkspace)
 	}
@@ -216,12 +218,18 @@ pod 'Alamofire', '~> 3.4'
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
 		expectedWorkspace := ""
-		actualWorkspace, err := getUserDefinedWorkspaceRelativePath(podfilePth, "")
+		actualWorkspace, err := getUserDefinedWorkspaceRelativePath(context.Background(), podfilePth, "")
 		require.NoError(t, err)
 		require.Equal(t, expectedWorkspace, actualWorkspace)
 	}
 }
 
+func newTestOSFileSystem(t *testing.T, rootDir string) filesystem.FileSystem {
+	fs, err := filesystem.NewOSFileSystem(rootDir)
+	require.NoError(t, err)
+	return fs
+}
+
 func TestGetWorkspaceProjectMap(t *testing.T) {
 	tmpDir, err := pathutil.NormalizedOSTempDirPath("__utility_test__")
 	require.NoError(t, err)
@@ -240,7 +248,7 @@ pod 'Alamofire', '~> 3.4'
 		podfilePth := filepath.Join(tmpDir, "Podfile")
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "Podfile", []string{})
 		require.Error(t, err)
 		require.Equal(t, 0, len(workspaceProjectMap))
 
@@ -262,7 +270,7 @@ pod 'Alamofire', '~> 3.4'
 		projectPth := filepath.Join(tmpDir, "project.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(projectPth, project))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{projectPth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "Podfile", []string{"project.xcodeproj"})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -299,7 +307,7 @@ pod 'Alamofire', '~> 3.4'
 		project2Pth := filepath.Join(tmpDir, "project2.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(project2Pth, project2))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{project1Pth, project2Pth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "Podfile", []string{"project1.xcodeproj", "project2.xcodeproj"})
 		require.Error(t, err)
 		require.Equal(t, 0, len(workspaceProjectMap))
 
@@ -318,7 +326,7 @@ pod 'Alamofire', '~> 3.4'
 		podfilePth := filepath.Join(tmpDir, "Podfile")
 		require.NoError(t, fileutil.WriteStringToFile(podfilePth, podfile))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "Podfile", []string{})
 		require.Error(t, err)
 		require.Equal(t, 0, len(workspaceProjectMap))
 
@@ -341,7 +349,7 @@ pod 'Alamofire', '~> 3.4'
 		projectPth := filepath.Join(tmpDir, "project.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(projectPth, project))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{projectPth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "Podfile", []string{"project.xcodeproj"})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -379,7 +387,7 @@ pod 'Alamofire', '~> 3.4'
 		project2Pth := filepath.Join(tmpDir, "project2.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(project2Pth, project2))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{project1Pth, project2Pth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "Podfile", []string{"project1.xcodeproj", "project2.xcodeproj"})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -413,7 +421,7 @@ pod 'Alamofire', '~> 3.4'
 		projectPth := filepath.Join(tmpDir, "project.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(projectPth, project))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{projectPth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "Podfile", []string{"project.xcodeproj"})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -452,7 +460,7 @@ pod 'Alamofire', '~> 3.4'
 		project2Pth := filepath.Join(tmpDir, "project2.xcodeproj")
 		require.NoError(t, fileutil.WriteStringToFile(project2Pth, project2))
 
-		workspaceProjectMap, err := GetWorkspaceProjectMap(podfilePth, []string{project1Pth, project2Pth})
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "Podfile", []string{"project1.xcodeproj", "project2.xcodeproj"})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(workspaceProjectMap))
 
@@ -467,6 +475,31 @@ pod 'Alamofire', '~> 3.4'
 			require.Equal(t, "project1", projectName, fmt.Sprintf("%v", workspaceProjectMap))
 		}
 
+		require.NoError(t, os.RemoveAll(tmpDir))
+	}
+	t.Log("Podfile with require_relative and relative file reads")
+	{
+		files := map[string]string{
+			"ios/Podfile": `require_relative '../scripts/pods'
+platform :ios, '9.0'
+project app_project_path
+workspace File.read('workspace_name').strip
+`,
+			"ios/workspace_name": "MyWorkspace",
+			"ios/App.xcodeproj":  "",
+			"scripts/pods.rb":    "def app_project_path\n  'App.xcodeproj'\nend\n",
+		}
+
+		tmpDir = filepath.Join(tmpDir, "require_relative")
+		for pth, content := range files {
+			require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, filepath.Dir(pth)), 0777))
+			require.NoError(t, fileutil.WriteStringToFile(filepath.Join(tmpDir, pth), content))
+		}
+
+		workspaceProjectMap, err := GetWorkspaceProjectMap(context.Background(), newTestOSFileSystem(t, tmpDir), "ios/Podfile", []string{"ios/App.xcodeproj"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"ios/MyWorkspace.xcworkspace": "ios/App.xcodeproj"}, workspaceProjectMap)
+
 		require.NoError(t, os.RemoveAll(tmpDir))
 	}
 }
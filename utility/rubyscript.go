@@ -1,6 +1,7 @@
 package utility
 
 import (
+	"context"
 	"errors"
 	"path"
 
@@ -13,7 +14,8 @@ import (
 	"github.com/bitrise-io/go-utils/pathutil"
 )
 
-func runRubyScriptForOutput(scriptContent, gemfileContent, inDir string, withEnvs []string) (string, error) {
+// runRubyScriptForOutput runs the ruby script, the bundle install and the script run are limited by the ctx.
+func runRubyScriptForOutput(ctx context.Context, scriptContent, gemfileContent, inDir string, withEnvs []string) (string, error) {
 	tmpDir, err := pathutil.NormalizedOSTempDirPath("__bitrise-init__")
 	if err != nil {
 		return "", err
@@ -31,7 +33,7 @@ func runRubyScriptForOutput(scriptContent, gemfileContent, inDir string, withEnv
 			return "", err
 		}
 
-		cmd := command.New("bundle", "install")
+		cmd, cmdCtx, cancel := NewCommandContext(ctx, "bundle", "install")
 
 		if inDir != "" {
 			cmd.SetDir(inDir)
@@ -40,7 +42,14 @@ func runRubyScriptForOutput(scriptContent, gemfileContent, inDir string, withEnv
 		withEnvs = append(withEnvs, "BUNDLE_GEMFILE="+gemfilePth)
 		cmd.AppendEnvs(withEnvs...)
 
-		if out, err := cmd.RunAndReturnTrimmedCombinedOutput(); err != nil {
+		out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+		// the install command is released before the script runs, its context error is read before the cancel sets it
+		cmdCtxErr := cmdCtx.Err()
+		cancel()
+		if err != nil {
+			if cmdCtxErr != nil {
+				return "", CommandContextError(cmdCtx, cmd, err)
+			}
 			if errorutil.IsExitStatusError(err) {
 				return "", errors.New(out)
 			}
@@ -55,12 +64,15 @@ func runRubyScriptForOutput(scriptContent, gemfileContent, inDir string, withEnv
 	}
 
 	var cmd *command.Model
+	var cmdCtx context.Context
+	var cancel context.CancelFunc
 
 	if gemfileContent != "" {
-		cmd = command.New("bundle", "exec", "ruby", rubyScriptPth)
+		cmd, cmdCtx, cancel = NewCommandContext(ctx, "bundle", "exec", "ruby", rubyScriptPth)
 	} else {
-		cmd = command.New("ruby", rubyScriptPth)
+		cmd, cmdCtx, cancel = NewCommandContext(ctx, "ruby", rubyScriptPth)
 	}
+	defer cancel()
 
 	if inDir != "" {
 		cmd.SetDir(inDir)
@@ -72,6 +84,9 @@ func runRubyScriptForOutput(scriptContent, gemfileContent, inDir string, withEnv
 
 	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
 	if err != nil {
+		if cmdCtx.Err() != nil {
+			return "", CommandContextError(cmdCtx, cmd, err)
+		}
 		if errorutil.IsExitStatusError(err) {
 			return "", errors.New(out)
 		}
@@ -1,6 +1,7 @@
 package utility
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -17,7 +18,7 @@ puts "#{{ :test_key => 'test_value' }.to_json}"
 `
 
 	expectedOut := "{\"test_key\":\"test_value\"}"
-	actualOut, err := runRubyScriptForOutput(rubyScriptContent, gemfileContent, "", []string{})
+	actualOut, err := runRubyScriptForOutput(context.Background(), rubyScriptContent, gemfileContent, "", []string{})
 	require.NoError(t, err)
 	require.Equal(t, expectedOut, actualOut)
 }
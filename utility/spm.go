@@ -0,0 +1,210 @@
+package utility
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+)
+
+// Swift package target types
+const (
+	SwiftTargetType           = "target"
+	SwiftExecutableTargetType = "executableTarget"
+	SwiftTestTargetType       = "testTarget"
+)
+
+var (
+	swiftToolsVersionPattern = regexp.MustCompile(`^//\s*swift-tools-version\s*:\s*([0-9.]+)`)
+	swiftPackagePattern      = regexp.MustCompile(`Package\s*\(`)
+	swiftPackageNamePattern  = regexp.MustCompile(`Package\s*\(\s*name\s*:\s*"([^"]+)"`)
+	swiftProductPattern      = regexp.MustCompile(`\.(library|executable|plugin)\s*\(\s*name\s*:\s*"([^"]+)"`)
+	swiftTargetPattern       = regexp.MustCompile(`\.(target|executableTarget|testTarget|systemLibrary|binaryTarget|plugin)\s*\(\s*name\s*:\s*"([^"]+)"`)
+	swiftPlatformPattern     = regexp.MustCompile(`\.(iOS|macOS|macCatalyst|tvOS|watchOS|visionOS|driverKit)\s*\(\s*(?:\.v([0-9_]+)|"([^"]+)")`)
+)
+
+// SwiftPackageProduct is a product of a Swift package, like: .library(name: "Sample", targets: ["Sample"]).
+type SwiftPackageProduct struct {
+	Name string
+	// Type is the kind of the product: library, executable or plugin.
+	Type string
+}
+
+// SwiftPackageTarget is a target of a Swift package, like: .testTarget(name: "SampleTests").
+type SwiftPackageTarget struct {
+	Name string
+	// Type is the kind of the target, like: target, executableTarget or testTarget.
+	Type string
+}
+
+// SwiftPackagePlatform is a supported platform of a Swift package, like: .iOS(.v13).
+type SwiftPackagePlatform struct {
+	// Name is the platform name, like: iOS, macOS or tvOS.
+	Name string
+	// Version is the minimum deployment target, like: 13 or 10.15.
+	Version string
+}
+
+// SwiftPackageModel is the statically parsed Package.swift manifest of a Swift package.
+type SwiftPackageModel struct {
+	ToolsVersion string
+	Name         string
+	Products     []SwiftPackageProduct
+	Targets      []SwiftPackageTarget
+	// Platforms are the declared platforms, a package without declared platforms supports every platform.
+	Platforms []SwiftPackagePlatform
+}
+
+// TestTargets returns the names of the package's test targets.
+func (pkg SwiftPackageModel) TestTargets() []string {
+	testTargets := []string{}
+	for _, target := range pkg.Targets {
+		if target.Type == SwiftTestTargetType {
+			testTargets = append(testTargets, target.Name)
+		}
+	}
+	return testTargets
+}
+
+// HasPlatform reports whether the given platform is declared in the package manifest.
+func (pkg SwiftPackageModel) HasPlatform(name string) bool {
+	for _, platform := range pkg.Platforms {
+		if platform.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// stripSwiftComments removes the line and the block comments of the Swift source, the string literals are kept as they are.
+func stripSwiftComments(content string) string {
+	var stripped bytes.Buffer
+	inString := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		if inString {
+			stripped.WriteByte(c)
+			if c == '\\' && i+1 < len(content) {
+				i++
+				stripped.WriteByte(content[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			stripped.WriteByte(c)
+		case strings.HasPrefix(content[i:], "//"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				return stripped.String()
+			}
+			i += end - 1
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end == -1 {
+				return stripped.String()
+			}
+			i += end + 3
+		default:
+			stripped.WriteByte(c)
+		}
+	}
+	return stripped.String()
+}
+
+// packageArrayArgument returns the content of the Package's array argument with the given label, like: products: [...].
+// Only the Package's own arguments are matched (not the nested ones, like the targets of a product),
+// the string literals are skipped while tracking the nesting.
+func packageArrayArgument(content, label string) string {
+	loc := swiftPackagePattern.FindStringIndex(content)
+	if loc == nil {
+		return ""
+	}
+
+	labelPattern := regexp.MustCompile(`^` + regexp.QuoteMeta(label) + `\s*:\s*\[`)
+
+	depth := 0
+	argumentStart := -1
+	inString := false
+	for i := loc[1]; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+			if depth < 0 {
+				// end of the Package arguments
+				return ""
+			}
+			if depth == 0 && argumentStart != -1 {
+				return content[argumentStart:i]
+			}
+		case depth == 0 && argumentStart == -1 && (i == loc[1] || !isSwiftIdentifierChar(content[i-1])):
+			if match := labelPattern.FindStringIndex(content[i:]); match != nil {
+				argumentStart = i + match[1]
+				i = argumentStart - 1
+				depth++
+			}
+		}
+	}
+	return ""
+}
+
+func isSwiftIdentifierChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// parseSwiftPackageContent parses the Package.swift manifest statically, without evaluating the Swift code,
+// so the computed names and the conditionally added products or targets are not recognized.
+func parseSwiftPackageContent(content string) SwiftPackageModel {
+	pkg := SwiftPackageModel{}
+
+	if match := swiftToolsVersionPattern.FindStringSubmatch(strings.TrimSpace(content)); match != nil {
+		pkg.ToolsVersion = match[1]
+	}
+
+	content = stripSwiftComments(content)
+
+	if match := swiftPackageNamePattern.FindStringSubmatch(content); match != nil {
+		pkg.Name = match[1]
+	}
+
+	for _, match := range swiftProductPattern.FindAllStringSubmatch(packageArrayArgument(content, "products"), -1) {
+		pkg.Products = append(pkg.Products, SwiftPackageProduct{Name: match[2], Type: match[1]})
+	}
+
+	for _, match := range swiftTargetPattern.FindAllStringSubmatch(packageArrayArgument(content, "targets"), -1) {
+		pkg.Targets = append(pkg.Targets, SwiftPackageTarget{Name: match[2], Type: match[1]})
+	}
+
+	for _, match := range swiftPlatformPattern.FindAllStringSubmatch(packageArrayArgument(content, "platforms"), -1) {
+		version := match[3]
+		if match[2] != "" {
+			version = strings.Replace(match[2], "_", ".", -1)
+		}
+		pkg.Platforms = append(pkg.Platforms, SwiftPackagePlatform{Name: match[1], Version: version})
+	}
+
+	return pkg
+}
+
+// ParseSwiftPackage parses the given Package.swift manifest statically (see: parseSwiftPackageContent).
+func ParseSwiftPackage(fs filesystem.FileSystem, pth string) (SwiftPackageModel, error) {
+	content, err := filesystem.ReadStringFromFile(fs, pth)
+	if err != nil {
+		return SwiftPackageModel{}, err
+	}
+	return parseSwiftPackageContent(content), nil
+}
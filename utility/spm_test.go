@@ -0,0 +1,76 @@
+package utility
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSwiftPackageContent(t *testing.T) {
+	t.Log("library package")
+	{
+		pkg := parseSwiftPackageContent(`// swift-tools-version:5.1
+// The swift-tools-version declares the minimum version of Swift required to build this package.
+
+import PackageDescription
+
+let package = Package(
+    name: "Sample",
+    platforms: [
+        .iOS(.v13), .macOS(.v10_15),
+        .tvOS("13.0"),
+    ],
+    products: [
+        // .library(name: "Commented", targets: ["Commented"]),
+        .library(name: "Sample", targets: ["Sample"]),
+        .executable(name: "sample-cli", targets: ["SampleCLI"]),
+    ],
+    dependencies: [
+        .package(url: "https://github.com/apple/swift-argument-parser", from: "0.0.1"),
+    ],
+    targets: [
+        .target(name: "Sample", dependencies: []),
+        /* .target(name: "Disabled"), */
+        .target(
+            name: "SampleCLI",
+            dependencies: ["Sample", .product(name: "ArgumentParser", package: "swift-argument-parser")]),
+        .testTarget(name: "SampleTests", dependencies: ["Sample"]),
+    ]
+)
+`)
+		require.Equal(t, "5.1", pkg.ToolsVersion)
+		require.Equal(t, "Sample", pkg.Name)
+		require.Equal(t, []SwiftPackageProduct{
+			{Name: "Sample", Type: "library"},
+			{Name: "sample-cli", Type: "executable"},
+		}, pkg.Products)
+		require.Equal(t, []SwiftPackageTarget{
+			{Name: "Sample", Type: SwiftTargetType},
+			{Name: "SampleCLI", Type: SwiftTargetType},
+			{Name: "SampleTests", Type: SwiftTestTargetType},
+		}, pkg.Targets)
+		require.Equal(t, []string{"SampleTests"}, pkg.TestTargets())
+		require.Equal(t, []SwiftPackagePlatform{
+			{Name: "iOS", Version: "13"},
+			{Name: "macOS", Version: "10.15"},
+			{Name: "tvOS", Version: "13.0"},
+		}, pkg.Platforms)
+		require.True(t, pkg.HasPlatform("macOS"))
+		require.False(t, pkg.HasPlatform("watchOS"))
+	}
+
+	t.Log("package without platforms and products")
+	{
+		pkg := parseSwiftPackageContent(`// swift-tools-version: 5.7
+import PackageDescription
+
+let package = Package(name: "Tool", targets: [.executableTarget(name: "Tool")])
+`)
+		require.Equal(t, "5.7", pkg.ToolsVersion)
+		require.Equal(t, "Tool", pkg.Name)
+		require.Equal(t, 0, len(pkg.Products))
+		require.Equal(t, 0, len(pkg.Platforms))
+		require.Equal(t, []SwiftPackageTarget{{Name: "Tool", Type: SwiftExecutableTargetType}}, pkg.Targets)
+		require.Equal(t, []string{}, pkg.TestTargets())
+	}
+}
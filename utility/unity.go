@@ -0,0 +1,98 @@
+package utility
+
+import (
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+)
+
+// UnityProjectVersionModel is the model of the ProjectSettings/ProjectVersion.txt file of a Unity project.
+type UnityProjectVersionModel struct {
+	// EditorVersion is the version of the Unity editor, which last opened the project, like: 2019.4.1f1.
+	EditorVersion             string `yaml:"m_EditorVersion"`
+	EditorVersionWithRevision string `yaml:"m_EditorVersionWithRevision"`
+}
+
+func parseUnityProjectVersionContent(content string) (UnityProjectVersionModel, error) {
+	var projectVersion UnityProjectVersionModel
+	if err := yaml.Unmarshal([]byte(content), &projectVersion); err != nil {
+		return UnityProjectVersionModel{}, err
+	}
+	return projectVersion, nil
+}
+
+// ParseUnityProjectVersion ...
+func ParseUnityProjectVersion(fs filesystem.FileSystem, pth string) (UnityProjectVersionModel, error) {
+	content, err := filesystem.ReadStringFromFile(fs, pth)
+	if err != nil {
+		return UnityProjectVersionModel{}, err
+	}
+	return parseUnityProjectVersionContent(content)
+}
+
+// UnityPlayerSettingsModel holds the build related player settings of the ProjectSettings/ProjectSettings.asset file of a Unity project.
+type UnityPlayerSettingsModel struct {
+	ProductName string
+	// ApplicationIdentifiers maps the build target groups (like: iPhone, Android or Standalone) to their application identifiers,
+	// the editor adds a build target group, once its player settings are configured.
+	ApplicationIdentifiers map[string]string
+}
+
+// HasBuildTargetGroup reports whether the player settings of the given build target group are configured.
+func (settings UnityPlayerSettingsModel) HasBuildTargetGroup(name string) bool {
+	_, ok := settings.ApplicationIdentifiers[name]
+	return ok
+}
+
+// indentation returns the number of the leading spaces of the line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// parseUnityPlayerSettingsContent parses the PlayerSettings line by line,
+// as the Unity specific YAML tags (like: --- !u!129 &1) are not supported by the YAML parser.
+// The content is split in place, the serialized assets (like: icons) may be stored in lines of several megabytes.
+func parseUnityPlayerSettingsContent(content string) (UnityPlayerSettingsModel, error) {
+	settings := UnityPlayerSettingsModel{ApplicationIdentifiers: map[string]string{}}
+
+	// applicationIdentifierIndent is the indentation of the applicationIdentifier key, while parsing its entries, -1 otherwise
+	applicationIdentifierIndent := -1
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		indent := indentation(line)
+		if applicationIdentifierIndent != -1 {
+			if indent > applicationIdentifierIndent {
+				if split := strings.SplitN(trimmed, ":", 2); len(split) == 2 {
+					settings.ApplicationIdentifiers[strings.TrimSpace(split[0])] = strings.TrimSpace(split[1])
+				}
+				continue
+			}
+			applicationIdentifierIndent = -1
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "productName:"):
+			settings.ProductName = strings.TrimSpace(strings.TrimPrefix(trimmed, "productName:"))
+		case trimmed == "applicationIdentifier:":
+			applicationIdentifierIndent = indent
+		}
+	}
+	return settings, nil
+}
+
+// ParseUnityPlayerSettings ...
+func ParseUnityPlayerSettings(fs filesystem.FileSystem, pth string) (UnityPlayerSettingsModel, error) {
+	content, err := filesystem.ReadStringFromFile(fs, pth)
+	if err != nil {
+		return UnityPlayerSettingsModel{}, err
+	}
+	return parseUnityPlayerSettingsContent(content)
+}
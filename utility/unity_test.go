@@ -0,0 +1,79 @@
+package utility
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnityProjectVersionContent(t *testing.T) {
+	t.Log("project version")
+	{
+		projectVersion, err := parseUnityProjectVersionContent(`m_EditorVersion: 2019.4.1f1
+m_EditorVersionWithRevision: 2019.4.1f1 (e6c045e14e4e)
+`)
+		require.NoError(t, err)
+		require.Equal(t, "2019.4.1f1", projectVersion.EditorVersion)
+		require.Equal(t, "2019.4.1f1 (e6c045e14e4e)", projectVersion.EditorVersionWithRevision)
+	}
+
+	t.Log("invalid project version")
+	{
+		_, err := parseUnityProjectVersionContent(`m_EditorVersion: [`)
+		require.Error(t, err)
+	}
+}
+
+func TestParseUnityPlayerSettingsContent(t *testing.T) {
+	t.Log("configured build target groups")
+	{
+		settings, err := parseUnityPlayerSettingsContent(`%YAML 1.1
+%TAG !u! tag:unity3d.com,2011:
+--- !u!129 &1
+PlayerSettings:
+  m_ObjectHideFlags: 0
+  serializedVersion: 20
+  companyName: Bitrise
+  productName: Sample Game
+  defaultScreenWidth: 1024
+  applicationIdentifier:
+    Android: io.bitrise.sample
+    iPhone: io.bitrise.sample
+  buildNumber: {}
+  AndroidBundleVersionCode: 1
+  m_BuildTargetBatching:
+  - m_BuildTarget: Standalone
+    m_StaticBatching: 1
+`)
+		require.NoError(t, err)
+		require.Equal(t, "Sample Game", settings.ProductName)
+		require.Equal(t, map[string]string{"Android": "io.bitrise.sample", "iPhone": "io.bitrise.sample"}, settings.ApplicationIdentifiers)
+		require.True(t, settings.HasBuildTargetGroup("iPhone"))
+		require.False(t, settings.HasBuildTargetGroup("Standalone"))
+	}
+
+	t.Log("no configured build target group")
+	{
+		settings, err := parseUnityPlayerSettingsContent(`PlayerSettings:
+  productName: Sample
+  applicationIdentifier: {}
+`)
+		require.NoError(t, err)
+		require.Equal(t, "Sample", settings.ProductName)
+		require.Equal(t, 0, len(settings.ApplicationIdentifiers))
+	}
+
+	t.Log("lines over the default token size of the bufio.Scanner")
+	{
+		settings, err := parseUnityPlayerSettingsContent(`PlayerSettings:
+  productName: Sample
+  m_SplashScreenLogos: ` + strings.Repeat("0", 128*1024) + `
+  applicationIdentifier:
+    iPhone: io.bitrise.sample
+`)
+		require.NoError(t, err)
+		require.Equal(t, "Sample", settings.ProductName)
+		require.Equal(t, map[string]string{"iPhone": "io.bitrise.sample"}, settings.ApplicationIdentifiers)
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
 	"github.com/bitrise-io/go-utils/pathutil"
 )
 
@@ -145,8 +145,8 @@ func ExtensionFilter(ext string, allowed bool) FilterFunc {
 
 // RegexpFilter ...
 func RegexpFilter(pattern string, allowed bool) FilterFunc {
+	re := regexp.MustCompile(pattern)
 	return func(pth string) (bool, error) {
-		re := regexp.MustCompile(pattern)
 		found := re.FindString(pth) != ""
 		return (allowed == found), nil
 	}
@@ -182,9 +182,9 @@ func ComponentWithExtensionFilter(ext string, allowed bool) FilterFunc {
 }
 
 // IsDirectoryFilter ...
-func IsDirectoryFilter(allowed bool) FilterFunc {
+func IsDirectoryFilter(fs filesystem.FileSystem, allowed bool) FilterFunc {
 	return func(pth string) (bool, error) {
-		fileInf, err := os.Lstat(pth)
+		fileInf, err := fs.Lstat(pth)
 		if err != nil {
 			return false, err
 		}
@@ -203,9 +203,27 @@ func InDirectoryFilter(dir string, allowed bool) FilterFunc {
 	}
 }
 
+// PathsUnder returns the (root relative) paths inside the given directory, in their original order.
+// Every path is returned for the root directory (empty or .).
+func PathsUnder(paths []string, dir string) []string {
+	dir = filepath.Clean(dir)
+	if dir == "." {
+		return paths
+	}
+
+	prefix := dir + string(filepath.Separator)
+	pathsUnder := []string{}
+	for _, pth := range paths {
+		if strings.HasPrefix(pth, prefix) {
+			pathsUnder = append(pathsUnder, pth)
+		}
+	}
+	return pathsUnder
+}
+
 // FileContains ...
-func FileContains(pth, str string) (bool, error) {
-	content, err := fileutil.ReadStringFromFile(pth)
+func FileContains(fs filesystem.FileSystem, pth, str string) (bool, error) {
+	content, err := filesystem.ReadStringFromFile(fs, pth)
 	if err != nil {
 		return false, err
 	}
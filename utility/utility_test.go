@@ -7,6 +7,7 @@ import (
 
 	"path/filepath"
 
+	"github.com/bitrise-core/bitrise-init/filesystem"
 	"github.com/bitrise-io/go-utils/fileutil"
 	"github.com/bitrise-io/go-utils/pathutil"
 	"github.com/stretchr/testify/require"
@@ -34,6 +35,16 @@ func TestCaseInsensitiveContains(t *testing.T) {
 	require.Equal(t, false, CaseInsensitiveContains(``, `a`))
 }
 
+func TestPathsUnder(t *testing.T) {
+	paths := []string{"package.json", "android", "android/build.gradle", "android-lib/build.gradle", "ios/Sample.xcodeproj"}
+
+	require.Equal(t, []string{"android/build.gradle"}, PathsUnder(paths, "android"))
+	require.Equal(t, []string{"android/build.gradle"}, PathsUnder(paths, "android/"))
+	require.Equal(t, paths, PathsUnder(paths, ""))
+	require.Equal(t, paths, PathsUnder(paths, "."))
+	require.Equal(t, []string{}, PathsUnder(paths, "web"))
+}
+
 func TestListPathInDirSortedByComponents(t *testing.T) {
 	t.Log()
 	{
@@ -276,28 +287,31 @@ func TestIsDirectoryFilter(t *testing.T) {
 	tmpFile := filepath.Join(tmpDir, "file.txt")
 	require.NoError(t, fileutil.WriteStringToFile(tmpFile, ""))
 
+	fs, err := filesystem.NewOSFileSystem(tmpDir)
+	require.NoError(t, err)
+
 	t.Log("allow")
 	{
 		paths := []string{
-			tmpDir,
-			tmpFile,
+			".",
+			"file.txt",
 		}
-		filter := IsDirectoryFilter(true)
+		filter := IsDirectoryFilter(fs, true)
 		filtered, err := FilterPaths(paths, filter)
 		require.NoError(t, err)
-		require.Equal(t, []string{tmpDir}, filtered)
+		require.Equal(t, []string{"."}, filtered)
 	}
 
 	t.Log("forbid")
 	{
 		paths := []string{
-			tmpDir,
-			tmpFile,
+			".",
+			"file.txt",
 		}
-		filter := IsDirectoryFilter(false)
+		filter := IsDirectoryFilter(fs, false)
 		filtered, err := FilterPaths(paths, filter)
 		require.NoError(t, err)
-		require.Equal(t, []string{tmpFile}, filtered)
+		require.Equal(t, []string{"file.txt"}, filtered)
 	}
 }
 
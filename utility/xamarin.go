@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/models"
 )
 
 const (
@@ -15,21 +16,28 @@ const (
 
 // FilterSolutionFiles ...
 func FilterSolutionFiles(fileList []string) ([]string, error) {
-	allowSolutionExtensionFilter := ExtensionFilter(solutionExtension, true)
-	forbidComponentsSolutionFilter := RegexpFilter(`.*Components/.+.sln`, false)
-	files, err := FilterPaths(fileList,
-		allowSolutionExtensionFilter,
-		forbidComponentsSolutionFilter)
+	files, _, err := ExplainSolutionFiles(fileList)
+	return files, err
+}
+
+var solutionFilesPipeline = NewFilterPipeline(
+	NewNamedFilter("AllowSolutionExtensionFilter", "not a solution file", ExtensionFilter(solutionExtension, true)),
+	NewNamedFilter("ForbidComponentsSolutionFilter", "solution of a Xamarin component", RegexpFilter(`.*Components/.+.sln`, false)),
+)
+
+// ExplainSolutionFiles returns the solution files, like FilterSolutionFiles, and the rejected paths with the rejecting filters.
+func ExplainSolutionFiles(fileList []string) ([]string, []models.Rejection, error) {
+	files, rejections, err := solutionFilesPipeline.Explain(fileList)
 	if err != nil {
-		return []string{}, err
+		return []string{}, []models.Rejection{}, err
 	}
 
-	return files, nil
+	return files, rejections, nil
 }
 
 // GetSolutionConfigs ...
-func GetSolutionConfigs(solutionFile string) (map[string][]string, error) {
-	content, err := fileutil.ReadStringFromFile(solutionFile)
+func GetSolutionConfigs(fs filesystem.FileSystem, solutionFile string) (map[string][]string, error) {
+	content, err := filesystem.ReadStringFromFile(fs, solutionFile)
 	if err != nil {
 		return map[string][]string{}, err
 	}
@@ -5,7 +5,8 @@ import (
 
 	"fmt"
 
-	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/bitrise-tools/go-xcode/xcodeproj"
 )
 
@@ -37,7 +38,9 @@ var AllowXcodeProjExtFilter = ExtensionFilter(xcodeproj.XCodeProjExt, true)
 var AllowXCWorkspaceExtFilter = ExtensionFilter(xcodeproj.XCWorkspaceExt, true)
 
 // AllowIsDirectoryFilter ...
-var AllowIsDirectoryFilter = IsDirectoryFilter(true)
+func AllowIsDirectoryFilter(fs filesystem.FileSystem) FilterFunc {
+	return IsDirectoryFilter(fs, true)
+}
 
 // ForbidEmbeddedWorkspaceRegexpFilter ...
 var ForbidEmbeddedWorkspaceRegexpFilter = RegexpFilter(embeddedWorkspacePathPattern, false)
@@ -58,13 +61,17 @@ var ForbidCordovaLibDirComponentFilter = ComponentFilter(cordovaLibDirName, fals
 var ForbidFramworkComponentWithExtensionFilter = ComponentWithExtensionFilter(frameworkExt, false)
 
 // AllowIphoneosSDKFilter ...
-var AllowIphoneosSDKFilter = SDKFilter("iphoneos", true)
+func AllowIphoneosSDKFilter(fs filesystem.FileSystem) FilterFunc {
+	return SDKFilter(fs, "iphoneos", true)
+}
 
 // AllowMacosxSDKFilter ...
-var AllowMacosxSDKFilter = SDKFilter("macosx", true)
+func AllowMacosxSDKFilter(fs filesystem.FileSystem) FilterFunc {
+	return SDKFilter(fs, "macosx", true)
+}
 
 // SDKFilter ...
-func SDKFilter(sdk string, allowed bool) FilterFunc {
+func SDKFilter(fs filesystem.FileSystem, sdk string, allowed bool) FilterFunc {
 	return func(pth string) (bool, error) {
 		found := false
 
@@ -73,13 +80,13 @@ func SDKFilter(sdk string, allowed bool) FilterFunc {
 		if xcodeproj.IsXCodeProj(pth) {
 			projectFiles = append(projectFiles, pth)
 		} else if xcodeproj.IsXCWorkspace(pth) {
-			projects, err := xcodeproj.WorkspaceProjectReferences(pth)
+			projects, err := XcodeWorkspaceProjectReferences(fs, pth)
 			if err != nil {
 				return false, err
 			}
 
 			for _, project := range projects {
-				exist, err := pathutil.IsPathExists(project)
+				exist, err := filesystem.IsPathExists(fs, project)
 				if err != nil {
 					return false, err
 				}
@@ -94,8 +101,8 @@ func SDKFilter(sdk string, allowed bool) FilterFunc {
 		}
 
 		for _, projectFile := range projectFiles {
-			pbxprojPth := filepath.Join(projectFile, "project.pbxproj")
-			projectSDKs, err := xcodeproj.GetBuildConfigSDKs(pbxprojPth)
+			pbxprojPth := filepath.Join(projectFile, pbxprojBase)
+			projectSDKs, err := XcodeBuildConfigSDKs(fs, pbxprojPth)
 			if err != nil {
 				return false, err
 			}
@@ -157,10 +164,10 @@ func ReplaceWorkspaceInList(workspaces []xcodeproj.WorkspaceModel, workspace xco
 }
 
 // CreateStandaloneProjectsAndWorkspaces ...
-func CreateStandaloneProjectsAndWorkspaces(projectFiles, workspaceFiles []string) ([]xcodeproj.ProjectModel, []xcodeproj.WorkspaceModel, error) {
+func CreateStandaloneProjectsAndWorkspaces(fs filesystem.FileSystem, projectFiles, workspaceFiles []string) ([]xcodeproj.ProjectModel, []xcodeproj.WorkspaceModel, error) {
 	workspaces := []xcodeproj.WorkspaceModel{}
 	for _, workspaceFile := range workspaceFiles {
-		workspace, err := xcodeproj.NewWorkspace(workspaceFile, projectFiles...)
+		workspace, err := NewXcodeWorkspace(fs, workspaceFile, projectFiles...)
 		if err != nil {
 			return []xcodeproj.ProjectModel{}, []xcodeproj.WorkspaceModel{}, err
 		}
@@ -179,7 +186,7 @@ func CreateStandaloneProjectsAndWorkspaces(projectFiles, workspaceFiles []string
 		}
 
 		if !workspaceContains {
-			project, err := xcodeproj.NewProject(projectFile)
+			project, err := NewXcodeProject(fs, projectFile)
 			if err != nil {
 				return []xcodeproj.ProjectModel{}, []xcodeproj.WorkspaceModel{}, err
 			}
@@ -190,74 +197,97 @@ func CreateStandaloneProjectsAndWorkspaces(projectFiles, workspaceFiles []string
 	return standaloneProjects, workspaces, nil
 }
 
-// FilterRelevantProjectFiles ...
-func FilterRelevantProjectFiles(fileList []string, projectTypes ...XcodeProjectType) ([]string, error) {
-	filters := []FilterFunc{
-		AllowXcodeProjExtFilter,
-		AllowIsDirectoryFilter,
-		ForbidEmbeddedWorkspaceRegexpFilter,
-		ForbidGitDirComponentFilter,
-		ForbidPodsDirComponentFilter,
-		ForbidCarthageDirComponentFilter,
-		ForbidFramworkComponentWithExtensionFilter,
-		ForbidCordovaLibDirComponentFilter,
-	}
+// The named filters of the relevant file pipelines, the names and the descriptions show up in the scan explanations.
+var (
+	namedAllowXcodeProjExtFilter                    = NewNamedFilter("AllowXcodeProjExtFilter", "not an Xcode project", AllowXcodeProjExtFilter)
+	namedAllowXCWorkspaceExtFilter                  = NewNamedFilter("AllowXCWorkspaceExtFilter", "not an Xcode workspace", AllowXCWorkspaceExtFilter)
+	namedAllowPodfileBaseFilter                     = NewNamedFilter("AllowPodfileBaseFilter", "not a Podfile", AllowPodfileBaseFilter)
+	namedAllowCartfileBaseFilter                    = NewNamedFilter("AllowCartfileBaseFilter", "not a Cartfile", AllowCartfileBaseFilter)
+	namedForbidEmbeddedWorkspaceRegexpFilter        = NewNamedFilter("ForbidEmbeddedWorkspaceRegexpFilter", "embedded workspace of an Xcode project", ForbidEmbeddedWorkspaceRegexpFilter)
+	namedForbidGitDirComponentFilter                = NewNamedFilter("ForbidGitDirComponentFilter", "inside the .git directory", ForbidGitDirComponentFilter)
+	namedForbidPodsDirComponentFilter               = NewNamedFilter("ForbidPodsDirComponentFilter", "inside the Pods directory", ForbidPodsDirComponentFilter)
+	namedForbidCarthageDirComponentFilter           = NewNamedFilter("ForbidCarthageDirComponentFilter", "inside the Carthage directory", ForbidCarthageDirComponentFilter)
+	namedForbidFramworkComponentWithExtensionFilter = NewNamedFilter("ForbidFramworkComponentWithExtensionFilter", "inside a framework", ForbidFramworkComponentWithExtensionFilter)
+	namedForbidCordovaLibDirComponentFilter         = NewNamedFilter("ForbidCordovaLibDirComponentFilter", "inside the CordovaLib directory", ForbidCordovaLibDirComponentFilter)
+)
 
-	for _, projectType := range projectTypes {
-		switch projectType {
-		case XcodeProjectTypeIOS:
-			filters = append(filters, AllowIphoneosSDKFilter)
-		case XcodeProjectTypeMacOS:
-			filters = append(filters, AllowMacosxSDKFilter)
-		}
-	}
+// The relevant project and workspace pipelines filter by the path only,
+// the filters which access the file system are appended by explainRelevantXcodeFiles.
+var relevantProjectFilesPipeline = NewFilterPipeline(
+	namedAllowXcodeProjExtFilter,
+	namedForbidEmbeddedWorkspaceRegexpFilter,
+	namedForbidGitDirComponentFilter,
+	namedForbidPodsDirComponentFilter,
+	namedForbidCarthageDirComponentFilter,
+	namedForbidFramworkComponentWithExtensionFilter,
+	namedForbidCordovaLibDirComponentFilter,
+)
 
-	return FilterPaths(fileList, filters...)
-}
+var relevantWorkspaceFilesPipeline = NewFilterPipeline(
+	namedAllowXCWorkspaceExtFilter,
+	namedForbidEmbeddedWorkspaceRegexpFilter,
+	namedForbidGitDirComponentFilter,
+	namedForbidPodsDirComponentFilter,
+	namedForbidCarthageDirComponentFilter,
+	namedForbidFramworkComponentWithExtensionFilter,
+	namedForbidCordovaLibDirComponentFilter,
+)
 
-// FilterRelevantWorkspaceFiles ...
-func FilterRelevantWorkspaceFiles(fileList []string, projectTypes ...XcodeProjectType) ([]string, error) {
-	filters := []FilterFunc{
-		AllowXCWorkspaceExtFilter,
-		AllowIsDirectoryFilter,
-		ForbidEmbeddedWorkspaceRegexpFilter,
-		ForbidGitDirComponentFilter,
-		ForbidPodsDirComponentFilter,
-		ForbidCarthageDirComponentFilter,
-		ForbidFramworkComponentWithExtensionFilter,
-		ForbidCordovaLibDirComponentFilter,
-	}
+var relevantPodfilesPipeline = NewFilterPipeline(
+	namedAllowPodfileBaseFilter,
+	namedForbidGitDirComponentFilter,
+	namedForbidPodsDirComponentFilter,
+	namedForbidCarthageDirComponentFilter,
+	namedForbidFramworkComponentWithExtensionFilter,
+	namedForbidCordovaLibDirComponentFilter,
+)
 
+var relevantCartfilesPipeline = NewFilterPipeline(
+	namedAllowCartfileBaseFilter,
+	namedForbidGitDirComponentFilter,
+	namedForbidPodsDirComponentFilter,
+	namedForbidCarthageDirComponentFilter,
+	namedForbidFramworkComponentWithExtensionFilter,
+	namedForbidCordovaLibDirComponentFilter,
+)
+
+func explainRelevantXcodeFiles(fs filesystem.FileSystem, pipeline FilterPipeline, fileList []string, projectTypes ...XcodeProjectType) ([]string, []models.Rejection, error) {
+	pipeline = pipeline.Append(NewNamedFilter("AllowIsDirectoryFilter", "not a directory", AllowIsDirectoryFilter(fs)))
 	for _, projectType := range projectTypes {
 		switch projectType {
 		case XcodeProjectTypeIOS:
-			filters = append(filters, AllowIphoneosSDKFilter)
+			pipeline = pipeline.Append(NewNamedFilter("AllowIphoneosSDKFilter", "no iphoneos SDK in the build configurations", AllowIphoneosSDKFilter(fs)))
 		case XcodeProjectTypeMacOS:
-			filters = append(filters, AllowMacosxSDKFilter)
+			pipeline = pipeline.Append(NewNamedFilter("AllowMacosxSDKFilter", "no macosx SDK in the build configurations", AllowMacosxSDKFilter(fs)))
 		}
 	}
+	return pipeline.Explain(fileList)
+}
 
-	return FilterPaths(fileList, filters...)
+// FilterRelevantProjectFiles ...
+func FilterRelevantProjectFiles(fs filesystem.FileSystem, fileList []string, projectTypes ...XcodeProjectType) ([]string, error) {
+	filtered, _, err := ExplainRelevantProjectFiles(fs, fileList, projectTypes...)
+	return filtered, err
+}
+
+// ExplainRelevantProjectFiles returns the relevant project files, like FilterRelevantProjectFiles,
+// and the rejected paths with the rejecting filters.
+func ExplainRelevantProjectFiles(fs filesystem.FileSystem, fileList []string, projectTypes ...XcodeProjectType) ([]string, []models.Rejection, error) {
+	return explainRelevantXcodeFiles(fs, relevantProjectFilesPipeline, fileList, projectTypes...)
+}
+
+// FilterRelevantWorkspaceFiles ...
+func FilterRelevantWorkspaceFiles(fs filesystem.FileSystem, fileList []string, projectTypes ...XcodeProjectType) ([]string, error) {
+	filtered, _, err := explainRelevantXcodeFiles(fs, relevantWorkspaceFilesPipeline, fileList, projectTypes...)
+	return filtered, err
 }
 
 // FilterRelevantPodfiles ...
 func FilterRelevantPodfiles(fileList []string) ([]string, error) {
-	return FilterPaths(fileList,
-		AllowPodfileBaseFilter,
-		ForbidGitDirComponentFilter,
-		ForbidPodsDirComponentFilter,
-		ForbidCarthageDirComponentFilter,
-		ForbidFramworkComponentWithExtensionFilter,
-		ForbidCordovaLibDirComponentFilter)
+	return relevantPodfilesPipeline.Filter(fileList)
 }
 
 // FilterRelevantCartFile ...
 func FilterRelevantCartFile(fileList []string) ([]string, error) {
-	return FilterPaths(fileList,
-		AllowCartfileBaseFilter,
-		ForbidGitDirComponentFilter,
-		ForbidPodsDirComponentFilter,
-		ForbidCarthageDirComponentFilter,
-		ForbidFramworkComponentWithExtensionFilter,
-		ForbidCordovaLibDirComponentFilter)
+	return relevantCartfilesPipeline.Filter(fileList)
 }
@@ -0,0 +1,542 @@
+package utility
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-tools/go-xcode/xcodeproj"
+)
+
+// The Xcode project readers of this file mirror the go-xcode xcodeproj package's project and workspace constructors,
+// but they read the project files through a filesystem.FileSystem instead of the disk.
+
+const (
+	pbxprojBase         = "project.pbxproj"
+	xcworkspacedataBase = "contents.xcworkspacedata"
+	sharedSchemePattern = `(^|.*/)xcshareddata/xcschemes/.+[.]xcscheme$`
+)
+
+var sharedSchemeRegexp = regexp.MustCompile(sharedSchemePattern)
+
+// NewXcodeProject reads the Xcode project's SDKs, shared schemes and targets.
+func NewXcodeProject(fs filesystem.FileSystem, xcodeprojPth string) (xcodeproj.ProjectModel, error) {
+	project := xcodeproj.ProjectModel{
+		Pth:  xcodeprojPth,
+		Name: strings.TrimSuffix(filepath.Base(xcodeprojPth), filepath.Ext(xcodeprojPth)),
+	}
+
+	pbxprojPth := filepath.Join(xcodeprojPth, pbxprojBase)
+
+	if exist, err := filesystem.IsPathExists(fs, pbxprojPth); err != nil {
+		return xcodeproj.ProjectModel{}, err
+	} else if !exist {
+		return xcodeproj.ProjectModel{}, fmt.Errorf("Project descriptor not found at: %s", pbxprojPth)
+	}
+
+	pbxprojContent, err := filesystem.ReadStringFromFile(fs, pbxprojPth)
+	if err != nil {
+		return xcodeproj.ProjectModel{}, err
+	}
+
+	sdks, err := buildConfigSDKs(pbxprojContent)
+	if err != nil {
+		return xcodeproj.ProjectModel{}, err
+	}
+	project.SDKs = sdks
+
+	schemes, err := XcodeSharedSchemes(fs, xcodeprojPth)
+	if err != nil {
+		return xcodeproj.ProjectModel{}, err
+	}
+	project.SharedSchemes = schemes
+
+	targets, err := pbxprojTargets(pbxprojContent)
+	if err != nil {
+		return xcodeproj.ProjectModel{}, err
+	}
+	project.Targets = targets
+
+	return project, nil
+}
+
+// NewXcodeWorkspace reads the Xcode workspace and its referred projects,
+// if projectsToCheck are given, only these projects are read.
+func NewXcodeWorkspace(fs filesystem.FileSystem, xcworkspacePth string, projectsToCheck ...string) (xcodeproj.WorkspaceModel, error) {
+	workspace := xcodeproj.WorkspaceModel{
+		Pth:  xcworkspacePth,
+		Name: strings.TrimSuffix(filepath.Base(xcworkspacePth), filepath.Ext(xcworkspacePth)),
+	}
+
+	projects, err := XcodeWorkspaceProjectReferences(fs, xcworkspacePth)
+	if err != nil {
+		return xcodeproj.WorkspaceModel{}, err
+	}
+
+	if len(projectsToCheck) > 0 {
+		filteredProjects := []string{}
+		for _, project := range projects {
+			for _, projectToCheck := range projectsToCheck {
+				if project == projectToCheck {
+					filteredProjects = append(filteredProjects, project)
+				}
+			}
+		}
+		projects = filteredProjects
+	}
+
+	for _, xcodeprojPth := range projects {
+		if exist, err := filesystem.IsPathExists(fs, xcodeprojPth); err != nil {
+			return xcodeproj.WorkspaceModel{}, err
+		} else if !exist {
+			return xcodeproj.WorkspaceModel{}, fmt.Errorf("referred project (%s) not found", xcodeprojPth)
+		}
+
+		project, err := NewXcodeProject(fs, xcodeprojPth)
+		if err != nil {
+			return xcodeproj.WorkspaceModel{}, err
+		}
+
+		workspace.Projects = append(workspace.Projects, project)
+	}
+
+	return workspace, nil
+}
+
+// XcodeWorkspaceProjectReferences returns the paths of the projects, referred by the workspace.
+// The paths are relative to the file system's root, just like the workspace path.
+func XcodeWorkspaceProjectReferences(fs filesystem.FileSystem, xcworkspacePth string) ([]string, error) {
+	projects := []string{}
+
+	workspaceDir := filepath.Dir(xcworkspacePth)
+
+	xcworkspacedataPth := filepath.Join(xcworkspacePth, xcworkspacedataBase)
+	if exist, err := filesystem.IsPathExists(fs, xcworkspacedataPth); err != nil {
+		return []string{}, err
+	} else if !exist {
+		return []string{}, fmt.Errorf("contents.xcworkspacedata does not exist at: %s", xcworkspacedataPth)
+	}
+
+	xcworkspacedataStr, err := filesystem.ReadStringFromFile(fs, xcworkspacedataPth)
+	if err != nil {
+		return []string{}, err
+	}
+
+	xcworkspacedataLines := strings.Split(xcworkspacedataStr, "\n")
+	fileRefStart := false
+	locationRegexp := regexp.MustCompile(`location = "(.+):(.+).xcodeproj"`)
+
+	for _, line := range xcworkspacedataLines {
+		if strings.Contains(line, "<FileRef") {
+			fileRefStart = true
+			continue
+		}
+
+		if fileRefStart {
+			fileRefStart = false
+			matches := locationRegexp.FindStringSubmatch(line)
+			if len(matches) == 3 {
+				projectName := matches[2]
+				project := filepath.Join(workspaceDir, projectName+".xcodeproj")
+				projects = append(projects, project)
+			}
+		}
+	}
+
+	sort.Strings(projects)
+
+	return projects, nil
+}
+
+// XcodeBuildConfigSDKs returns the SDKs of the project's build configurations.
+func XcodeBuildConfigSDKs(fs filesystem.FileSystem, pbxprojPth string) ([]string, error) {
+	content, err := filesystem.ReadStringFromFile(fs, pbxprojPth)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return buildConfigSDKs(content)
+}
+
+// XcodeSharedSchemes returns the shared schemes of the project or workspace.
+func XcodeSharedSchemes(fs filesystem.FileSystem, projectOrWorkspacePth string) ([]xcodeproj.SchemeModel, error) {
+	schemePaths := []string{}
+	if err := filesystem.Walk(fs, projectOrWorkspacePth, func(pth string, info os.FileInfo, err error) error {
+		if sharedSchemeRegexp.MatchString(filepath.ToSlash(pth)) {
+			schemePaths = append(schemePaths, pth)
+		}
+		return nil
+	}); err != nil {
+		return []xcodeproj.SchemeModel{}, err
+	}
+
+	sort.Strings(schemePaths)
+
+	schemes := []xcodeproj.SchemeModel{}
+	for _, schemePth := range schemePaths {
+		content, err := filesystem.ReadStringFromFile(fs, schemePth)
+		if err != nil {
+			return []xcodeproj.SchemeModel{}, err
+		}
+
+		hasXCTest, err := schemeContainsXCTestBuildAction(content)
+		if err != nil {
+			return []xcodeproj.SchemeModel{}, err
+		}
+
+		schemes = append(schemes, xcodeproj.SchemeModel{
+			Name:      xcodeproj.SchemeNameFromPath(schemePth),
+			HasXCTest: hasXCTest,
+		})
+	}
+
+	return schemes, nil
+}
+
+func buildConfigSDKs(pbxprojContent string) ([]string, error) {
+	sdkMap := map[string]bool{}
+
+	beginXCBuildConfigurationSection := `/* Begin XCBuildConfiguration section */`
+	endXCBuildConfigurationSection := `/* End XCBuildConfiguration section */`
+	isXCBuildConfigurationSection := false
+
+	// SDKROOT = macosx;
+	sdkRegexp := regexp.MustCompile(`SDKROOT = (?P<sdk>.*);`)
+
+	scanner := bufio.NewScanner(strings.NewReader(pbxprojContent))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.TrimSpace(line) == endXCBuildConfigurationSection {
+			break
+		}
+
+		if strings.TrimSpace(line) == beginXCBuildConfigurationSection {
+			isXCBuildConfigurationSection = true
+			continue
+		}
+
+		if !isXCBuildConfigurationSection {
+			continue
+		}
+
+		if match := sdkRegexp.FindStringSubmatch(line); len(match) == 2 {
+			sdkMap[match[1]] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return []string{}, err
+	}
+
+	sdks := []string{}
+	for sdk := range sdkMap {
+		sdks = append(sdks, sdk)
+	}
+
+	return sdks, nil
+}
+
+func schemeContainsXCTestBuildAction(schemeContent string) (bool, error) {
+	testActionStartPattern := "<TestAction"
+	testActionEndPattern := "</TestAction>"
+	isTestableAction := false
+
+	testableReferenceStartPattern := "<TestableReference"
+	testableReferenceSkippedRegexp := regexp.MustCompile(`skipped = "(?P<skipped>.+)"`)
+	testableReferenceEndPattern := "</TestableReference>"
+	isTestableReference := false
+
+	xctestBuildableReferenceNameRegexp := regexp.MustCompile(`BuildableName = ".+.xctest"`)
+
+	scanner := bufio.NewScanner(strings.NewReader(schemeContent))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == testActionEndPattern {
+			break
+		}
+
+		if line == testActionStartPattern {
+			isTestableAction = true
+			continue
+		}
+
+		if !isTestableAction {
+			continue
+		}
+
+		// TestAction
+
+		if line == testableReferenceEndPattern {
+			isTestableReference = false
+			continue
+		}
+
+		if line == testableReferenceStartPattern {
+			isTestableReference = true
+			continue
+		}
+
+		if !isTestableReference {
+			continue
+		}
+
+		// TestableReference
+
+		if matches := testableReferenceSkippedRegexp.FindStringSubmatch(line); len(matches) > 1 {
+			if matches[1] != "NO" {
+				break
+			}
+		}
+
+		if xctestBuildableReferenceNameRegexp.FindString(line) != "" {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+type pbxNativeTarget struct {
+	id           string
+	dependencies []string
+	name         string
+	productPath  string
+}
+
+type pbxTargetDependency struct {
+	id     string
+	target string
+}
+
+func parsePBXNativeTargets(pbxprojContent string) ([]pbxNativeTarget, error) {
+	targets := []pbxNativeTarget{}
+	target := pbxNativeTarget{}
+
+	beginSectionPattern := `/* Begin PBXNativeTarget section */`
+	endSectionPattern := `/* End PBXNativeTarget section */`
+	isSection := false
+
+	// BAAFFED019EE788800F3AC91 /* SampleAppWithCocoapods */ = {
+	beginTargetRegexp := regexp.MustCompile(`\s*(?P<id>[A-Z0-9]+) /\* (?P<name>.*) \*/ = {`)
+	endTargetPattern := `};`
+	isTarget := false
+
+	beginDependenciesPattern := `dependencies = (`
+	dependencyRegexp := regexp.MustCompile(`\s*(?P<id>[A-Z0-9]+) /\* (?P<isa>.*) \*/,`)
+	endDependenciesPattern := `);`
+	isDependencies := false
+
+	// name = SampleAppWithCocoapods;
+	nameRegexp := regexp.MustCompile(`\s*name = (?P<name>.*);`)
+	// productReference = BAAFFEED19EE788800F3AC91 /* SampleAppWithCocoapodsTests.xctest */;
+	productReferenceRegexp := regexp.MustCompile(`\s*productReference = (?P<id>[A-Z0-9]+) /\* (?P<path>.*) \*/;`)
+
+	scanner := bufio.NewScanner(strings.NewReader(pbxprojContent))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmedLine := strings.TrimSpace(line)
+
+		if trimmedLine == endSectionPattern {
+			break
+		}
+
+		if trimmedLine == beginSectionPattern {
+			isSection = true
+			continue
+		}
+
+		if !isSection {
+			continue
+		}
+
+		if trimmedLine == endTargetPattern {
+			targets = append(targets, target)
+			target = pbxNativeTarget{}
+			isTarget = false
+			continue
+		}
+
+		if matches := beginTargetRegexp.FindStringSubmatch(line); len(matches) == 3 {
+			target.id = matches[1]
+			target.name = matches[2]
+			isTarget = true
+			continue
+		}
+
+		if !isTarget {
+			continue
+		}
+
+		if matches := nameRegexp.FindStringSubmatch(line); len(matches) == 2 {
+			target.name = strings.Trim(matches[1], `"`)
+		}
+
+		if matches := productReferenceRegexp.FindStringSubmatch(line); len(matches) == 3 {
+			target.productPath = strings.Trim(matches[2], `"`)
+		}
+
+		if isDependencies && trimmedLine == endDependenciesPattern {
+			isDependencies = false
+			continue
+		}
+
+		if trimmedLine == beginDependenciesPattern {
+			isDependencies = true
+			continue
+		}
+
+		if !isDependencies {
+			continue
+		}
+
+		if matches := dependencyRegexp.FindStringSubmatch(line); len(matches) == 3 {
+			if strings.Trim(matches[2], `"`) == "PBXTargetDependency" {
+				target.dependencies = append(target.dependencies, strings.Trim(matches[1], `"`))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return []pbxNativeTarget{}, err
+	}
+
+	return targets, nil
+}
+
+func parsePBXTargetDependencies(pbxprojContent string) ([]pbxTargetDependency, error) {
+	dependencies := []pbxTargetDependency{}
+	dependency := pbxTargetDependency{}
+
+	beginSectionPattern := `/* Begin PBXTargetDependency section */`
+	endSectionPattern := `/* End PBXTargetDependency section */`
+	isSection := false
+
+	// BAAFFEEF19EE788800F3AC91 /* PBXTargetDependency */ = {
+	beginDependencyRegexp := regexp.MustCompile(`\s*(?P<id>[A-Z0-9]+) /\* (?P<isa>.*) \*/ = {`)
+	endDependencyPattern := `};`
+	isDependency := false
+
+	// target = BAAFFED019EE788800F3AC91 /* SampleAppWithCocoapods */;
+	targetRegexp := regexp.MustCompile(`\s*target = (?P<id>[A-Z0-9]+) /\* (?P<name>.*) \*/;`)
+
+	scanner := bufio.NewScanner(strings.NewReader(pbxprojContent))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmedLine := strings.TrimSpace(line)
+
+		if trimmedLine == endSectionPattern {
+			break
+		}
+
+		if trimmedLine == beginSectionPattern {
+			isSection = true
+			continue
+		}
+
+		if !isSection {
+			continue
+		}
+
+		if trimmedLine == endDependencyPattern {
+			dependencies = append(dependencies, dependency)
+			dependency = pbxTargetDependency{}
+			isDependency = false
+			continue
+		}
+
+		if matches := beginDependencyRegexp.FindStringSubmatch(line); len(matches) == 3 {
+			dependency.id = matches[1]
+			isDependency = true
+			continue
+		}
+
+		if !isDependency {
+			continue
+		}
+
+		if matches := targetRegexp.FindStringSubmatch(line); len(matches) == 3 {
+			dependency.target = strings.Trim(matches[1], `"`)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return []pbxTargetDependency{}, err
+	}
+
+	return dependencies, nil
+}
+
+// pbxprojTargets returns the project's targets, a target has XCTest if a test target depends on it.
+func pbxprojTargets(pbxprojContent string) ([]xcodeproj.TargetModel, error) {
+	nativeTargets, err := parsePBXNativeTargets(pbxprojContent)
+	if err != nil {
+		return []xcodeproj.TargetModel{}, err
+	}
+
+	targetDependencies, err := parsePBXTargetDependencies(pbxprojContent)
+	if err != nil {
+		return []xcodeproj.TargetModel{}, err
+	}
+
+	nativeTargetByID := map[string]pbxNativeTarget{}
+	for _, target := range nativeTargets {
+		nativeTargetByID[target.id] = target
+	}
+
+	dependencyByID := map[string]pbxTargetDependency{}
+	for _, dependency := range targetDependencies {
+		dependencyByID[dependency.id] = dependency
+	}
+
+	targetMap := map[string]xcodeproj.TargetModel{}
+
+	// Add targets which has test targets
+	for _, target := range nativeTargets {
+		if path.Ext(target.productPath) != ".xctest" {
+			continue
+		}
+
+		for _, dependencyID := range target.dependencies {
+			dependency, found := dependencyByID[dependencyID]
+			if !found {
+				continue
+			}
+
+			if dependentTarget, found := nativeTargetByID[dependency.target]; found {
+				targetMap[dependentTarget.name] = xcodeproj.TargetModel{
+					Name:      dependentTarget.name,
+					HasXCTest: true,
+				}
+			}
+		}
+	}
+
+	// Add targets which has NO test targets
+	for _, target := range nativeTargets {
+		if path.Ext(target.productPath) == ".xctest" {
+			continue
+		}
+
+		if _, found := targetMap[target.name]; !found {
+			targetMap[target.name] = xcodeproj.TargetModel{
+				Name:      target.name,
+				HasXCTest: false,
+			}
+		}
+	}
+
+	targets := []xcodeproj.TargetModel{}
+	for _, target := range targetMap {
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
@@ -0,0 +1,91 @@
+package utility
+
+import (
+	"testing"
+
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-tools/go-xcode/xcodeproj"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchemeContent = `<?xml version="1.0" encoding="UTF-8"?>
+<Scheme
+   LastUpgradeVersion = "0810"
+   version = "1.3">
+   <TestAction
+      buildConfiguration = "Debug"
+      selectedDebuggerIdentifier = "Xcode.DebuggerFoundation.Debugger.LLDB"
+      shouldUseLaunchSchemeArgsEnv = "YES">
+      <Testables>
+         <TestableReference
+            skipped = "NO">
+            <BuildableReference
+               BuildableIdentifier = "primary"
+               BlueprintIdentifier = "13C4D5BA1DDDDED400D5DC29"
+               BuildableName = "BitriseFastlaneSampleTests.xctest"
+               BlueprintName = "BitriseFastlaneSampleTests"
+               ReferencedContainer = "container:BitriseFastlaneSample.xcodeproj">
+            </BuildableReference>
+         </TestableReference>
+      </Testables>
+   </TestAction>
+</Scheme>
+`
+
+const testWorkspaceContent = `<?xml version="1.0" encoding="UTF-8"?>
+<Workspace
+   version = "1.0">
+   <FileRef
+      location = "group:ios/BitriseFastlaneSample.xcodeproj">
+   </FileRef>
+   <FileRef
+      location = "group:Pods/Pods.xcodeproj">
+   </FileRef>
+</Workspace>
+`
+
+func TestNewXcodeProject(t *testing.T) {
+	fs, err := filesystem.NewMemoryFileSystem(map[string]string{
+		"ios/BitriseFastlaneSample.xcodeproj/project.pbxproj":                                                     testIOSPbxprojContent,
+		"ios/BitriseFastlaneSample.xcodeproj/xcshareddata/xcschemes/BitriseFastlaneSample.xcscheme":               testSchemeContent,
+		"ios/BitriseFastlaneSample.xcodeproj/xcuserdata/bitrise.xcuserdatad/xcschemes/UserScheme.xcscheme":        testSchemeContent,
+		"ios/BitriseFastlaneSample.xcodeproj/project.xcworkspace/xcshareddata/xcschemes/WorkspaceScheme.xcscheme": "<Scheme></Scheme>",
+		"Sample.xcworkspace/contents.xcworkspacedata":                                                             testWorkspaceContent,
+	})
+	require.NoError(t, err)
+
+	t.Log("project")
+	{
+		project, err := NewXcodeProject(fs, "ios/BitriseFastlaneSample.xcodeproj")
+		require.NoError(t, err)
+		require.Equal(t, "BitriseFastlaneSample", project.Name)
+		require.Equal(t, []string{"iphoneos"}, project.SDKs)
+		require.Equal(t, []xcodeproj.SchemeModel{
+			{Name: "WorkspaceScheme", HasXCTest: false},
+			{Name: "BitriseFastlaneSample", HasXCTest: true},
+		}, project.SharedSchemes)
+		require.Equal(t, []xcodeproj.TargetModel{{Name: "BitriseFastlaneSample", HasXCTest: true}}, project.Targets)
+	}
+
+	t.Log("missing project")
+	{
+		_, err := NewXcodeProject(fs, "Missing.xcodeproj")
+		require.EqualError(t, err, "Project descriptor not found at: Missing.xcodeproj/project.pbxproj")
+	}
+
+	t.Log("workspace")
+	{
+		projects, err := XcodeWorkspaceProjectReferences(fs, "Sample.xcworkspace")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Pods/Pods.xcodeproj", "ios/BitriseFastlaneSample.xcodeproj"}, projects)
+
+		workspace, err := NewXcodeWorkspace(fs, "Sample.xcworkspace", "ios/BitriseFastlaneSample.xcodeproj")
+		require.NoError(t, err)
+		require.Equal(t, "Sample", workspace.Name)
+		require.Equal(t, 1, len(workspace.Projects))
+		require.Equal(t, "ios/BitriseFastlaneSample.xcodeproj", workspace.Projects[0].Pth)
+
+		_, err = NewXcodeWorkspace(fs, "Sample.xcworkspace")
+		require.EqualError(t, err, "referred project (Pods/Pods.xcodeproj) not found")
+	}
+}
@@ -1,12 +1,10 @@
 package utility
 
 import (
-	"os"
-	"path/filepath"
 	"testing"
 
-	"github.com/bitrise-io/go-utils/fileutil"
-	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-core/bitrise-init/filesystem"
+	"github.com/bitrise-core/bitrise-init/models"
 	"github.com/stretchr/testify/require"
 )
 
@@ -140,34 +138,22 @@ func TestForbidFramworkComponentWithExtensionFilter(t *testing.T) {
 }
 
 func TestAllowIphoneosSDKFilter(t *testing.T) {
-	tmpDir, err := pathutil.NormalizedOSTempDirPath("__xcodeproj_test__")
+	fs, err := filesystem.NewMemoryFileSystem(map[string]string{
+		"iphoneos.xcodeproj/project.pbxproj": testIOSPbxprojContent,
+		"macosx.xcodeproj/project.pbxproj":   testMacOSPbxprojContent,
+	})
 	require.NoError(t, err)
-	defer func() {
-		require.NoError(t, os.RemoveAll(tmpDir))
-	}()
-
-	iphoneosProject := filepath.Join(tmpDir, "iphoneos.xcodeproj")
-	require.NoError(t, os.MkdirAll(iphoneosProject, 0777))
-
-	iphoneosPbxprojPth := filepath.Join(iphoneosProject, "project.pbxproj")
-	require.NoError(t, fileutil.WriteStringToFile(iphoneosPbxprojPth, testIOSPbxprojContent))
-
-	macosxProject := filepath.Join(tmpDir, "macosx.xcodeproj")
-	require.NoError(t, os.MkdirAll(macosxProject, 0777))
-
-	macosxPbxprojPth := filepath.Join(macosxProject, "project.pbxproj")
-	require.NoError(t, fileutil.WriteStringToFile(macosxPbxprojPth, testMacOSPbxprojContent))
 
 	t.Log("iphoneos sdk")
 	{
 		paths := []string{
-			iphoneosProject,
-			macosxProject,
+			"iphoneos.xcodeproj",
+			"macosx.xcodeproj",
 		}
 		expectedFiltered := []string{
-			iphoneosProject,
+			"iphoneos.xcodeproj",
 		}
-		actualFiltered, err := FilterPaths(paths, AllowIphoneosSDKFilter)
+		actualFiltered, err := FilterPaths(paths, AllowIphoneosSDKFilter(fs))
 		require.NoError(t, err)
 		require.Equal(t, expectedFiltered, actualFiltered)
 	}
@@ -175,14 +161,38 @@ func TestAllowIphoneosSDKFilter(t *testing.T) {
 	t.Log("macosx sdk")
 	{
 		paths := []string{
-			iphoneosProject,
-			macosxProject,
+			"iphoneos.xcodeproj",
+			"macosx.xcodeproj",
 		}
 		expectedFiltered := []string{
-			macosxProject,
+			"macosx.xcodeproj",
 		}
-		actualFiltered, err := FilterPaths(paths, AllowMacosxSDKFilter)
+		actualFiltered, err := FilterPaths(paths, AllowMacosxSDKFilter(fs))
 		require.NoError(t, err)
 		require.Equal(t, expectedFiltered, actualFiltered)
 	}
 }
+
+func TestExplainRelevantProjectFiles(t *testing.T) {
+	fs, err := filesystem.NewMemoryFileSystem(map[string]string{
+		"iphoneos.xcodeproj/project.pbxproj":     testIOSPbxprojContent,
+		"macosx.xcodeproj/project.pbxproj":       testMacOSPbxprojContent,
+		"Pods/Pods.xcodeproj/project.pbxproj":    testIOSPbxprojContent,
+		"Carthage/Lib.xcodeproj/project.pbxproj": testIOSPbxprojContent,
+	})
+	require.NoError(t, err)
+
+	filtered, rejections, err := ExplainRelevantProjectFiles(fs, []string{
+		"Carthage/Lib.xcodeproj",
+		"Pods/Pods.xcodeproj",
+		"iphoneos.xcodeproj",
+		"macosx.xcodeproj",
+	}, XcodeProjectTypeIOS)
+	require.NoError(t, err)
+	require.Equal(t, []string{"iphoneos.xcodeproj"}, filtered)
+	require.Equal(t, []models.Rejection{
+		{Path: "Carthage/Lib.xcodeproj", Filter: "ForbidCarthageDirComponentFilter", Reason: "inside the Carthage directory"},
+		{Path: "Pods/Pods.xcodeproj", Filter: "ForbidPodsDirComponentFilter", Reason: "inside the Pods directory"},
+		{Path: "macosx.xcodeproj", Filter: "AllowIphoneosSDKFilter", Reason: "no iphoneos SDK in the build configurations"},
+	}, rejections)
+}